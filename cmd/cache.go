@@ -0,0 +1,154 @@
+/*
+Copyright © 2022 maxgio92 <me@maxgio.it>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	v "github.com/spf13/viper"
+
+	"github.com/maxgio92/krawler/internal/utils"
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	mib = 1 << 20
+
+	// The default state file of the repositories crawled by previous runs, in the cache directory.
+	stateFileName = "state.json.gz"
+)
+
+var (
+	// The cache directory flag value.
+	cacheDir string
+
+	// The prune flag values.
+	pruneMaxAge  time.Duration
+	pruneMaxSize int64
+
+	// cacheCmd represents the cache command.
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of repository metadata and packages",
+	}
+
+	cacheInfoCmd = &cobra.Command{
+		Use:   "info",
+		Short: "Show the cache directory, number of entries and size",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, err := openCache()
+			if err != nil {
+				return err
+			}
+
+			info, err := cache.Info()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(Output, "Directory: %s\nEntries: %d\nSize: %.1f MiB\n", info.Dir, info.Entries, float64(info.Size)/mib)
+
+			return nil
+		},
+	}
+
+	cachePruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the cache entries not used recently, and the least recently used ones beyond the maximum size",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, err := openCache()
+			if err != nil {
+				return err
+			}
+
+			removed, err := cache.Prune(pruneMaxAge, pruneMaxSize*mib)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(Output, "Removed %d entries.\n", removed)
+
+			return nil
+		},
+	}
+
+	cacheClearCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all the cache entries, and the state of the repositories crawled by previous runs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, err := openCache()
+			if err != nil {
+				return err
+			}
+
+			removed, err := cache.Clear()
+			if err != nil {
+				return err
+			}
+
+			// The next runs crawl all the repositories again.
+			if err = packages.RemoveState(filepath.Join(cache.Dir(), stateFileName)); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(Output, "Removed %d entries.\n", removed)
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheInfoCmd, cachePruneCmd, cacheClearCmd)
+
+	// Bind the cache directory flag. It overrides the configuration.
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "cache directory (default is $XDG_CACHE_HOME/krawler)")
+
+	cachePruneCmd.Flags().DurationVar(&pruneMaxAge, "max-age", 30*24*time.Hour, "Maximum time since an entry was last used (0 for unlimited)")
+	cachePruneCmd.Flags().Int64Var(&pruneMaxSize, "max-size", 0, "Maximum size of the cache, in MiB (0 for unlimited)")
+}
+
+// getCacheDir returns the cache directory, from the flag, the configuration or the default, in order.
+func getCacheDir(configured string) (string, error) {
+	if rootCmd.PersistentFlags().Changed("cache-dir") {
+		return cacheDir, nil
+	}
+
+	if configured != "" {
+		return configured, nil
+	}
+
+	return fetch.DefaultCacheDir()
+}
+
+func openCache() (*fetch.Cache, error) {
+	config, err := utils.GetDistroConfigAndVarsFromViper(v.GetViper())
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := getCacheDir(config.HTTP.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return fetch.OpenCache(dir)
+}
@@ -19,5 +19,65 @@ package cmd
 const (
 	ConfigDistrosRoot           = "distros"
 	RPMKernelHeadersPackageName = "kernel-devel"
+
+	// RPMKernelUAPIHeadersPackageName is the package name of the kernel userspace API headers, which is
+	// published also where kernel-devel is not, like in the Red Hat Universal Base Image repositories.
+	// It does not ship the kernel configuration, so the releases found from it have no toolchain.
+	RPMKernelUAPIHeadersPackageName = "kernel-headers"
+
 	DebKernelHeadersPackageName = "linux-headers"
+	APKKernelHeadersPackageName = "linux-lts-dev"
+
+	// PhotonKernelHeadersPackageName is the package name of the Photon OS kernel development files, which
+	// unlike other RPM-based distributions follows the upstream kernel package naming.
+	PhotonKernelHeadersPackageName = "linux-devel"
+
+	// GentooKernelPackageName is the package name of the Gentoo patched kernel sources.
+	GentooKernelPackageName = "gentoo-sources"
+
+	// NixKernelPackageName is the prefix of the NixOS kernel package set names (e.g. linuxPackages_6_1).
+	NixKernelPackageName = "linuxPackages"
+
+	// ImageKernelPackageName is the package name of kernels shipped with image-based distributions.
+	ImageKernelPackageName = "kernel"
+)
+
+// The names of the packages related to the kernel packages searched (e.g. the kernel image, modules and debug
+// information), to resolve the package set of each kernel release. They're matched as the kernel package names,
+// and the names beginning or ending with a dash as part of the Debian package names.
+// The Debian common headers and kbuild packages are resolved as dependencies of the headers packages.
+var (
+	RPMKernelRelatedPackageNames = []string{
+		"kernel",
+		"kernel-core",
+		"kernel-modules",
+		"kernel-modules-core",
+		"kernel-modules-extra",
+		"kernel-headers",
+		"kernel-devel",
+		"kernel-debuginfo",
+		"kernel-debuginfo-common-x86_64",
+		"kernel-debuginfo-common-aarch64",
+	}
+
+	OpenSUSEKernelRelatedPackageNames = []string{
+		"kernel-default",
+		"kernel-default-extra",
+		"kernel-default-debuginfo",
+		"kernel-devel",
+	}
+
+	PhotonKernelRelatedPackageNames = []string{
+		"linux",
+		"linux-debuginfo",
+	}
+
+	DebKernelRelatedPackageNames = []string{
+		"linux-image-",
+		"linux-modules-",
+	}
+
+	APKKernelRelatedPackageNames = []string{
+		"linux-lts",
+	}
 )
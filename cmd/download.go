@@ -0,0 +1,326 @@
+/*
+Copyright © 2022 maxgio92 <me@maxgio.it>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxgio92/krawler/internal/utils"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/deb"
+	"github.com/maxgio92/krawler/pkg/packages/rpm"
+
+	"github.com/spf13/cobra"
+	v "github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// The download flag values.
+	downloadDir  string
+	downloadFrom string
+	downloadKind []string
+	extract      bool
+
+	// downloadCmd represents the download command.
+	downloadCmd = &cobra.Command{
+		Use:   "download [distro release | --from file [release]]",
+		Short: "Download the packages of a kernel release, and optionally extract them",
+		Long: `Download the packages of a kernel release, as searched for the distribution, or as listed
+by the list command in JSON format, into a directory.
+The release is the kernel release (e.g. 4.18.0-331.el8.x86_64), or the name of its package
+(e.g. linux-headers-6.1.0-18-amd64), or its suffix (e.g. 6.1.0-18-amd64).
+With --extract, the RPM and deb packages are extracted into the directory, as the root
+of the file system (e.g. usr/src/linux-headers-6.1.0-18-amd64).`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if downloadFrom != "" {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, kind := range downloadKind {
+				if !slices.Contains(kr.PackageKinds, kind) {
+					return fmt.Errorf("package kind not supported: %s", kind)
+				}
+			}
+
+			kernelReleases, err := getDownloadKernelReleases(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+
+			if err = os.MkdirAll(downloadDir, 0o755); err != nil {
+				return err
+			}
+
+			downloaded := []string{}
+
+			for _, k := range kernelReleases {
+				for _, a := range releaseArtifacts(k) {
+					name := artifactFileName(a)
+
+					// The packages of the releases found on multiple mirrors are downloaded once.
+					if slices.Contains(downloaded, name) || !slices.Contains(downloadKind, a.Kind) {
+						continue
+					}
+
+					if extract {
+						err = extractArtifact(cmd.Context(), a, name)
+					} else {
+						err = downloadArtifact(cmd.Context(), a, filepath.Join(downloadDir, name))
+					}
+
+					if err != nil {
+						return err
+					}
+
+					downloaded = append(downloaded, name)
+
+					fmt.Fprintf(Output, "%s (%s)\n", name, a.Kind)
+				}
+			}
+
+			if extract {
+				return linkKernelSources(downloadDir)
+			}
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(downloadCmd)
+
+	downloadCmd.Flags().StringVarP(&downloadDir, "dir", "d", ".", "Directory to download the packages into")
+	downloadCmd.Flags().StringVar(&downloadFrom, "from", "", "File of the kernel releases listed in JSON format, or - for the standard input")
+	downloadCmd.Flags().StringSliceVar(&downloadKind, "kind",
+		[]string{kr.PackageKindHeaders, kr.PackageKindDevel, kr.PackageKindCommonHeaders, kr.PackageKindKbuild, kr.PackageKindDependency},
+		"Kinds of the packages to download")
+	// Bind the crawling flags, for the releases searched for the distribution.
+	downloadCmd.Flags().AddFlagSet(crawlFlags)
+
+	downloadCmd.Flags().BoolVarP(&extract, "extract", "x", false, "Extract the packages into the directory, instead of downloading the package files")
+}
+
+// getDownloadKernelReleases returns the kernel releases to download, searched for the distribution,
+// or read from the file, and matching the release, if any.
+func getDownloadKernelReleases(ctx context.Context, args []string) ([]kr.KernelRelease, error) {
+	var (
+		kernelReleases []kr.KernelRelease
+		release        string
+		err            error
+	)
+
+	if downloadFrom != "" {
+		if len(args) > 0 {
+			release = args[0]
+		}
+
+		if kernelReleases, err = readKernelReleases(downloadFrom); err != nil {
+			return nil, err
+		}
+
+		if err = configureDownload(); err != nil {
+			return nil, err
+		}
+	} else {
+		list, ok := listFuncs[args[0]]
+		if !ok {
+			return nil, fmt.Errorf("distro not supported: %s", args[0])
+		}
+
+		release = args[1]
+
+		// The packages of the other releases are skipped before visiting their files.
+		matchRelease = release
+
+		if kernelReleases, err = list(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if release == "" {
+		return kernelReleases, nil
+	}
+
+	matching := []kr.KernelRelease{}
+
+	for _, k := range kernelReleases {
+		if k.IsRelease(release) {
+			matching = append(matching, k)
+		}
+	}
+
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("release not found: %s", release)
+	}
+
+	return matching, nil
+}
+
+// configureDownload configures the HTTP client of the downloads, from the configuration.
+func configureDownload() error {
+	config, err := utils.GetDistroConfigAndVarsFromViper(v.GetViper())
+	if err != nil {
+		return err
+	}
+
+	return configureFetch(&config)
+}
+
+// readKernelReleases reads the kernel releases listed in JSON format, or a single one, from the file,
+// or from the standard input.
+func readKernelReleases(name string) ([]kr.KernelRelease, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		k := kr.KernelRelease{}
+		err = json.Unmarshal(data, &k)
+
+		return []kr.KernelRelease{k}, err
+	}
+
+	kernelReleases := []kr.KernelRelease{}
+	err = json.Unmarshal(data, &kernelReleases)
+
+	return kernelReleases, err
+}
+
+// releaseArtifacts returns the packages of the kernel release, or its package if listed without.
+func releaseArtifacts(k kr.KernelRelease) kr.PackageSet {
+	if len(k.Packages) > 0 {
+		return k.Packages
+	}
+
+	kind := kr.PackageKindDevel
+	if strings.Contains(k.PackageName, kr.PackageKindHeaders) {
+		kind = kr.PackageKindHeaders
+	}
+
+	return kr.PackageSet{{Kind: kind, Name: k.PackageName, URL: k.PackageURL}}
+}
+
+// artifactFileName returns the file name of the package, from its URL.
+func artifactFileName(a kr.Artifact) string {
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		return path.Base(a.URL)
+	}
+
+	return path.Base(u.Path)
+}
+
+// downloadArtifact downloads the package to the file, verifying it against its checksum, if declared.
+func downloadArtifact(ctx context.Context, a kr.Artifact, name string) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = packages.Download(ctx, a.URL, a.Checksum, f)
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+// extractArtifact downloads the package to a temporary file, and extracts it into the download directory.
+func extractArtifact(ctx context.Context, a kr.Artifact, name string) error {
+	var extractPackage func(path, dir string) error
+
+	switch path.Ext(name) {
+	case ".rpm":
+		extractPackage = rpm.ExtractPackage
+	case ".deb", ".ddeb", ".udeb":
+		extractPackage = deb.ExtractPackage
+	default:
+		return fmt.Errorf("package format not supported: %s", name)
+	}
+
+	dir, err := os.MkdirTemp("", "krawler-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err = downloadArtifact(ctx, a, filepath.Join(dir, name)); err != nil {
+		return err
+	}
+
+	return extractPackage(filepath.Join(dir, name), downloadDir)
+}
+
+// linkKernelSources links the kernel sources of RPM packages (e.g. usr/src/kernels/4.18.0-331.el8.x86_64)
+// as the ones of deb packages (e.g. usr/src/linux-headers-4.18.0-331.el8.x86_64), if not already there.
+func linkKernelSources(dir string) error {
+	entries, err := os.ReadDir(filepath.Join(dir, "usr", "src", "kernels"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		link := filepath.Join(dir, "usr", "src", "linux-headers-"+e.Name())
+
+		if _, lerr := os.Lstat(link); lerr == nil {
+			continue
+		}
+
+		if err = os.Symlink(filepath.Join("kernels", e.Name()), link); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -16,39 +16,177 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/maxgio92/krawler/internal/format"
 	"github.com/maxgio92/krawler/internal/utils"
 	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/fetch"
 	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/signature"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	v "github.com/spf13/viper"
+	"golang.org/x/exp/slices"
 )
 
 var (
 	// The output format flag value.
 	outputFormat string
 
+	// The concurrency and rate limits flag values.
+	workers         int
+	parallelism     int
+	hostParallelism int
+	hostRate        float64
+
+	// The flag value to disable the cache.
+	noCache bool
+
+	// The incremental crawling flag values.
+	stateFile string
+	refresh   bool
+
+	// The kernel configuration options flag value.
+	configOptions []string
+
+	// The flag value to show the whole kernel configuration.
+	fullConfig bool
+
+	// The kernel release the packages are matched against, on their metadata, before visiting their files.
+	// It's set by the download command, for the files of the other releases not to be visited.
+	matchRelease string
+
+	// The version range, sort and latest releases flag values.
+	minVersion     string
+	maxVersion     string
+	sortBy         string
+	latestPerMinor bool
+
 	// listCmd represents the list command.
 	listCmd = &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
 		Short:   "List available kernel releases with distributed headers, by Linux distribution",
 	}
+
+	// The crawling flags, shared by the commands searching the kernel releases.
+	crawlFlags = newCrawlFlags()
+
+	// The functions listing the kernel releases of the distributions, by the name and the aliases
+	// of their list command, for the other commands to search the kernel releases too.
+	listFuncs = map[string]listFunc{}
 )
 
+// newCrawlFlags returns the flags of the crawling concurrency, rate limits, cache and state.
+func newCrawlFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("crawl", pflag.ExitOnError)
+
+	// Bind the concurrency and rate limits flags. They override the configuration.
+	flags.IntVar(&workers, "workers", packages.DefaultParallelism, "Maximum number of repositories and packages analysed at once")
+	flags.IntVar(&parallelism, "parallelism", fetch.DefaultParallelism, "Maximum number of concurrent requests")
+	flags.IntVar(&hostParallelism, "host-parallelism", fetch.DefaultHostParallelism, "Maximum number of concurrent requests per mirror host")
+	flags.Float64Var(&hostRate, "host-rate", 0, "Maximum number of requests per second per mirror host (0 for unlimited)")
+
+	// Bind the flag to disable the cache.
+	flags.BoolVar(&noCache, "no-cache", false, "Do not cache repository metadata and packages")
+
+	// Bind the incremental crawling flags.
+	flags.StringVar(&stateFile, "state-file", "", "State file of the repositories crawled by previous runs (default is "+stateFileName+" in the cache directory)")
+	flags.BoolVar(&refresh, "refresh", false, "Crawl the repositories unchanged since the last run too")
+
+	return flags
+}
+
+// listFunc returns the kernel releases of a distribution.
+type listFunc func(ctx context.Context) ([]kr.KernelRelease, error)
+
+// newListCmd returns the list command of a distribution, printing the kernel releases returned by list.
+func newListCmd(cmd *cobra.Command, list listFunc) *cobra.Command {
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		kernelReleases, err := list(cmd.Context())
+		cobra.CheckErr(err)
+
+		if len(kernelReleases) > 0 {
+			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
+			cobra.CheckErr(err)
+		} else {
+			//nolint:errcheck
+			Output.WriteString("No releases found.\n")
+		}
+
+		return nil
+	}
+
+	for _, name := range append([]string{cmd.Name()}, cmd.Aliases...) {
+		listFuncs[name] = list
+	}
+
+	return cmd
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 
 	// Bind the output format flag. Default is text.
 	listCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json, yaml)")
+
+	// Bind the crawling flags.
+	listCmd.PersistentFlags().AddFlagSet(crawlFlags)
+
+	// Bind the kernel configuration options flag. It can be repeated.
+	listCmd.PersistentFlags().StringArrayVar(&configOptions, "config-option", nil,
+		"Kernel configuration option to show, as NAME or NAME* by prefix, or to filter releases by, as NAME=VALUE (e.g. CONFIG_DEBUG_INFO_BTF=y)")
+	listCmd.PersistentFlags().BoolVar(&fullConfig, "full-config", false, "Show the whole kernel configuration of the releases")
+
+	// Bind the version range, sort and latest releases flags.
+	listCmd.PersistentFlags().StringVar(&minVersion, "min-version", "", "Minimum package version of the releases (e.g. 5.4)")
+	listCmd.PersistentFlags().StringVar(&maxVersion, "max-version", "", "Maximum package version of the releases, including the versions it's a prefix of (e.g. 6.1)")
+	listCmd.PersistentFlags().StringVar(&sortBy, "sort", "", "Sort the releases (version)")
+	listCmd.PersistentFlags().BoolVar(&latestPerMinor, "latest-per-minor", false, "List only the latest release of each minor version, by architecture and flavour")
 }
 
-func getKernelReleases(distro distro.Distro, packageName string) ([]kr.KernelRelease, error) {
+// getKernelReleases returns the kernel releases of the packages with the name, with the packages related to them
+// (e.g. the kernel image) searched by the related package names.
+func getKernelReleases(ctx context.Context, distro distro.Distro, packageName string, relatedPackageNames ...string) ([]kr.KernelRelease, error) {
+	return getKernelReleasesOf(ctx, distro, []string{packageName}, relatedPackageNames...)
+}
+
+// getKernelReleasesOf returns the kernel releases of the packages with the first of the names found,
+// in order of preference, with the packages related to them searched by the related package names.
+// The names but the first are searched as related package names.
+//
+//nolint:funlen,cyclop
+func getKernelReleasesOf(ctx context.Context, distro distro.Distro, packageNames []string, relatedPackageNames ...string) ([]kr.KernelRelease, error) {
+	packageName := packageNames[0]
+
 	config, err := utils.GetDistroConfigAndVarsFromViper(v.GetViper())
 	if err != nil {
 		return []kr.KernelRelease{}, err
 	}
 
+	options, err := parseConfigOptions(configOptions)
+	if err != nil {
+		return []kr.KernelRelease{}, err
+	}
+
+	if sortBy != "" && sortBy != kr.SortVersion {
+		return []kr.KernelRelease{}, fmt.Errorf("sort not supported: %s", sortBy)
+	}
+
+	if err = configureFetch(&config); err != nil {
+		return []kr.KernelRelease{}, err
+	}
+
+	state, err := loadState(config.HTTP.CacheDir)
+	if err != nil {
+		return []kr.KernelRelease{}, err
+	}
+
 	// The searchOptions for searchOptions packages.
 	searchOptions := packages.NewSearchOptions(
 		packageName,
@@ -59,22 +197,178 @@ func getKernelReleases(distro distro.Distro, packageName string) ([]kr.KernelRel
 		".config",
 	)
 
+	keyring, err := signature.LoadKeyrings(config.Keyrings)
+	if err != nil {
+		return []kr.KernelRelease{}, err
+	}
+
+	searchNames := append([]string{}, relatedPackageNames...)
+
+	for _, name := range packageNames[1:] {
+		if !slices.Contains(searchNames, name) {
+			searchNames = append(searchNames, name)
+		}
+	}
+
+	searchOptions.SetRelatedPackageNames(searchNames...)
+	searchOptions.SetKeyring(keyring)
+	searchOptions.SetState(state)
+	if crawlFlags.Changed("workers") {
+		config.Workers = workers
+	}
+
+	searchOptions.SetParallelism(config.Workers)
+
 	err = distro.Configure(config)
 	if err != nil {
 		return []kr.KernelRelease{}, err
 	}
 
 	// Scrape mirrors for packeges by searchOptions.
-	packages, err := distro.SearchPackages(*searchOptions)
+	foundPackages, err := distro.SearchPackages(ctx, *searchOptions)
 	if err != nil {
+		saveState(state, searchOptions)
+
 		return []kr.KernelRelease{}, err
 	}
 
-	// Get kernel releases from kernel header packages.
-	kernelReleases, err := kr.GetKernelReleasesFromPackages(packages, packageName)
+	var kernelPackages []packages.Package
+
+	for _, name := range packageNames {
+		if kernelPackages, _ = kr.SplitRelatedPackages(foundPackages, name, relatedPackageNames); len(kernelPackages) > 0 {
+			break
+		}
+	}
+
+	if matchRelease != "" {
+		kernelPackages = kr.FilterPackagesByRelease(kernelPackages, matchRelease)
+	}
+
+	// Get kernel releases from kernel header packages, visiting their files.
+	kernelReleases, err := kr.GetKernelReleasesFromPackages(ctx, kernelPackages, searchOptions.Log(), searchOptions.Parallelism(), options...)
+
+	// The state is saved once the package files are visited, for them to be recorded.
+	saveState(state, searchOptions)
+
 	if err != nil {
 		return []kr.KernelRelease{}, err
 	}
 
-	return kernelReleases, nil
+	kr.ResolvePackageSets(kernelReleases, foundPackages)
+
+	for mirror, err := range packages.DefaultHealth().Unhealthy() {
+		searchOptions.Log().WithField("mirror", mirror).WithError(err).Warn("Mirror served corrupt content")
+	}
+
+	return filterKernelReleases(kernelReleases), nil
+}
+
+// configureFetch configures the HTTP client shared by the searches and the downloads, from the configuration
+// and the flags overriding it, and resets the mirror groups and health of previous runs.
+func configureFetch(config *distro.Config) error {
+	var err error
+
+	fetch.DefaultMirrors().Reset()
+	packages.DefaultHealth().Reset()
+
+	if crawlFlags.Changed("parallelism") {
+		config.HTTP.Parallelism = parallelism
+	}
+
+	if crawlFlags.Changed("host-parallelism") {
+		config.HTTP.HostParallelism = hostParallelism
+	}
+
+	if crawlFlags.Changed("host-rate") {
+		config.HTTP.HostRate = hostRate
+	}
+
+	if noCache {
+		config.HTTP.CacheDir = ""
+	} else if config.HTTP.CacheDir, err = getCacheDir(config.HTTP.CacheDir); err != nil {
+		return err
+	}
+
+	return fetch.Configure(config.HTTP)
+}
+
+// filterKernelReleases filters the kernel releases by version range, and sorts them, as by the flags.
+func filterKernelReleases(kernelReleases []kr.KernelRelease) []kr.KernelRelease {
+	var lower, upper *kr.Version
+
+	if minVersion != "" {
+		v := kr.ParseVersion(minVersion, "")
+		lower = &v
+	}
+
+	if maxVersion != "" {
+		v := kr.ParseVersion(maxVersion, "")
+		upper = &v
+	}
+
+	kernelReleases = kr.FilterByVersion(kernelReleases, lower, upper)
+
+	if latestPerMinor {
+		kernelReleases = kr.LatestPerMinor(kernelReleases)
+	}
+
+	if sortBy == kr.SortVersion {
+		kr.SortByVersion(kernelReleases)
+	}
+
+	return kernelReleases
+}
+
+// parseConfigOptions parses the kernel configuration options of the flag, matching all the options
+// if the whole configuration is shown.
+func parseConfigOptions(flags []string) ([]kr.ConfigOption, error) {
+	options := make([]kr.ConfigOption, 0, len(flags))
+
+	for _, f := range flags {
+		o, err := kr.ParseConfigOption(f)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, o)
+	}
+
+	if fullConfig {
+		options = append(options, kr.AllConfigOptions)
+	}
+
+	return options, nil
+}
+
+// saveState persists the state of the repositories crawled.
+// Only the repositories completely crawled are recorded, even if the search is interrupted.
+func saveState(state *packages.State, so *packages.SearchOptions) {
+	if err := state.Save(); err != nil {
+		so.Log().WithError(err).Warn("Error saving the state file")
+	}
+}
+
+// loadState returns the state of the repositories crawled by previous runs, from the flag
+// or the default state file in the cache directory.
+// Incremental crawling is disabled without cache, and the state is empty when refreshing.
+func loadState(cacheDir string) (*packages.State, error) {
+	path := stateFile
+
+	if path == "" {
+		if cacheDir == "" {
+			return nil, nil
+		}
+
+		if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+			return nil, err
+		}
+
+		path = filepath.Join(cacheDir, stateFileName)
+	}
+
+	if refresh {
+		return packages.NewState(path), nil
+	}
+
+	return packages.LoadState(path)
 }
@@ -16,30 +16,20 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	v1 "github.com/maxgio92/krawler/pkg/distro/amazonlinux/v1"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 	"github.com/spf13/cobra"
 )
 
 // amazonLinuxCmd represents the centos command.
-var amazonLinuxCmd = &cobra.Command{
+var amazonLinuxCmd = newListCmd(&cobra.Command{
 	Use:   "amazonlinux",
 	Short: "List Amazon Linux 1 kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&v1.AmazonLinux{}, RPMKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &v1.AmazonLinux{}, RPMKernelHeadersPackageName, RPMKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(amazonLinuxCmd)
@@ -16,30 +16,20 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	v2022 "github.com/maxgio92/krawler/pkg/distro/amazonlinux/v2022"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 	"github.com/spf13/cobra"
 )
 
 // amazonLinux2Cmd represents the centos command.
-var amazonLinux2022Cmd = &cobra.Command{
+var amazonLinux2022Cmd = newListCmd(&cobra.Command{
 	Use:   "amazonlinux2022",
 	Short: "List Amazon Linux 2022 kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&v2022.AmazonLinux{}, RPMKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &v2022.AmazonLinux{}, RPMKernelHeadersPackageName, RPMKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(amazonLinux2022Cmd)
@@ -18,29 +18,19 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	v2023 "github.com/maxgio92/krawler/pkg/distro/amazonlinux/v2023"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 )
 
 // amazonLinux2Cmd represents the centos command.
-var amazonLinux2023Cmd = &cobra.Command{
+var amazonLinux2023Cmd = newListCmd(&cobra.Command{
 	Use:   "amazonlinux2023",
 	Short: "List Amazon Linux 2023 kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&v2023.AmazonLinux{}, RPMKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &v2023.AmazonLinux{}, RPMKernelHeadersPackageName, RPMKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(amazonLinux2023Cmd)
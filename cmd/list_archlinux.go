@@ -19,30 +19,20 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	"github.com/maxgio92/krawler/pkg/distro/archlinux"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 	"github.com/spf13/cobra"
 )
 
 // fedoraCmd represents the fedora command.
-var archLinuxCmd = &cobra.Command{
+var archLinuxCmd = newListCmd(&cobra.Command{
 	Use:   "archlinux",
 	Short: "List Arch Linux kernel releases (current plus three months archive)",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&archlinux.ArchLinux{}, "linux-headers")
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &archlinux.ArchLinux{}, "linux-headers")
+})
 
 func init() {
 	listCmd.AddCommand(archLinuxCmd)
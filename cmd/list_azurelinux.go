@@ -0,0 +1,38 @@
+/*
+Copyright © 2022 maxgio92 <me@maxgio.it>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+
+	"github.com/maxgio92/krawler/pkg/distro/azurelinux"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
+
+	"github.com/spf13/cobra"
+)
+
+// azureLinuxCmd represents the azurelinux command.
+var azureLinuxCmd = newListCmd(&cobra.Command{
+	Use:     "azurelinux",
+	Aliases: []string{"mariner", "cbl-mariner"},
+	Short:   "List Azure Linux (CBL-Mariner) kernel releases",
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &azurelinux.AzureLinux{}, RPMKernelHeadersPackageName, RPMKernelRelatedPackageNames...)
+})
+
+func init() {
+	listCmd.AddCommand(azureLinuxCmd)
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2022 maxgio92 <me@maxgio.it>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+
+	"github.com/maxgio92/krawler/pkg/distro/bottlerocket"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
+
+	"github.com/spf13/cobra"
+)
+
+// bottlerocketCmd represents the bottlerocket command.
+var bottlerocketCmd = newListCmd(&cobra.Command{
+	Use:   "bottlerocket",
+	Short: "List Bottlerocket kernel releases",
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &bottlerocket.Bottlerocket{}, ImageKernelPackageName)
+})
+
+func init() {
+	listCmd.AddCommand(bottlerocketCmd)
+}
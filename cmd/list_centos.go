@@ -16,31 +16,21 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	"github.com/maxgio92/krawler/pkg/distro/centos"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 
 	"github.com/spf13/cobra"
 )
 
 // centosCmd represents the centos command.
-var centosCmd = &cobra.Command{
+var centosCmd = newListCmd(&cobra.Command{
 	Use:   "centos",
 	Short: "List CentOS kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&centos.Centos{}, RPMKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &centos.Centos{}, RPMKernelHeadersPackageName, RPMKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(centosCmd)
@@ -16,30 +16,20 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	"github.com/maxgio92/krawler/pkg/distro/debian"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 	"github.com/spf13/cobra"
 )
 
 // debianCmd represents the debian command.
-var debianCmd = &cobra.Command{
+var debianCmd = newListCmd(&cobra.Command{
 	Use:   "debian",
 	Short: "List Debian kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&debian.Debian{}, DebKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &debian.Debian{}, DebKernelHeadersPackageName, DebKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(debianCmd)
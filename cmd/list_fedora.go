@@ -17,31 +17,21 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	"github.com/maxgio92/krawler/pkg/distro/fedora"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 
 	"github.com/spf13/cobra"
 )
 
 // fedoraCmd represents the fedora command.
-var fedoraCmd = &cobra.Command{
+var fedoraCmd = newListCmd(&cobra.Command{
 	Use:   "fedora",
 	Short: "List Fedora kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&fedora.Fedora{}, RPMKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &fedora.Fedora{}, RPMKernelHeadersPackageName, RPMKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(fedoraCmd)
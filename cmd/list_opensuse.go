@@ -17,32 +17,21 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	"github.com/maxgio92/krawler/pkg/distro/opensuse"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 
 	"github.com/spf13/cobra"
 )
 
 // openSuseCmd represents the openSUSE command.
-var openSuseCmd = &cobra.Command{
+var openSuseCmd = newListCmd(&cobra.Command{
 	Use:   "opensuse",
 	Short: "List OpenSUSE kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		cmd.Flags()
-		kernelReleases, err := getKernelReleases(&opensuse.OpenSuse{}, "kernel-default-devel")
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &opensuse.OpenSuse{}, "kernel-default-devel", OpenSUSEKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(openSuseCmd)
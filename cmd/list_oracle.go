@@ -16,31 +16,21 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	"github.com/maxgio92/krawler/pkg/distro/oracle"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 
 	"github.com/spf13/cobra"
 )
 
 // oracleCmd represents the oracle command.
-var oracleCmd = &cobra.Command{
+var oracleCmd = newListCmd(&cobra.Command{
 	Use:   "oracle",
 	Short: "List Oracle Linux kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&oracle.Oracle{}, RPMKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &oracle.Oracle{}, RPMKernelHeadersPackageName, RPMKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(oracleCmd)
@@ -0,0 +1,44 @@
+/*
+Copyright © 2022 maxgio92 <me@maxgio.it>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+
+	"github.com/maxgio92/krawler/pkg/distro/ubi"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
+
+	"github.com/spf13/cobra"
+)
+
+// ubiCmd represents the ubi command.
+var ubiCmd = newListCmd(&cobra.Command{
+	Use:   "ubi",
+	Short: "List Red Hat Universal Base Image kernel releases",
+	Long: `List Red Hat Universal Base Image kernel releases, from the kernel-devel packages,
+or from the kernel-headers packages where kernel-devel is not published.
+The public UBI repositories publish the kernel userspace API headers (kernel-headers) only,
+which do not ship the kernel configuration: the releases found from them have no compiler
+version, toolchain and configuration, and cannot be used to build kernel modules.
+To build kernel modules, configure the repositories of a RHEL subscription publishing kernel-devel.`,
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleasesOf(ctx, &ubi.UBI{},
+		[]string{RPMKernelHeadersPackageName, RPMKernelUAPIHeadersPackageName}, RPMKernelRelatedPackageNames...)
+})
+
+func init() {
+	listCmd.AddCommand(ubiCmd)
+}
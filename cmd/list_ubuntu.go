@@ -16,31 +16,21 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/maxgio92/krawler/internal/format"
+	"context"
+
 	"github.com/maxgio92/krawler/pkg/distro/ubuntu"
+	kr "github.com/maxgio92/krawler/pkg/kernelrelease"
 
 	"github.com/spf13/cobra"
 )
 
 // ubuntuCmd represents the ubuntu command.
-var ubuntuCmd = &cobra.Command{
+var ubuntuCmd = newListCmd(&cobra.Command{
 	Use:   "ubuntu",
 	Short: "List Ubuntu kernel releases",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kernelReleases, err := getKernelReleases(&ubuntu.Ubuntu{}, DebKernelHeadersPackageName)
-		cobra.CheckErr(err)
-
-		if len(kernelReleases) > 0 {
-			Output, err = format.Encode(Output, kernelReleases, format.Type(outputFormat))
-			cobra.CheckErr(err)
-		} else {
-			//nolint:errcheck
-			Output.WriteString("No releases found.\n")
-		}
-
-		return nil
-	},
-}
+}, func(ctx context.Context) ([]kr.KernelRelease, error) {
+	return getKernelReleases(ctx, &ubuntu.Ubuntu{}, DebKernelHeadersPackageName, DebKernelRelatedPackageNames...)
+})
 
 func init() {
 	listCmd.AddCommand(ubuntuCmd)
@@ -23,6 +23,14 @@ func GetDistroConfigAndVarsFromViper(viper *v.Viper) (d.Config, error) {
 		}
 	}
 
+	if http := viper.Sub("http"); http != nil {
+		if err := http.Unmarshal(&config.HTTP); err != nil {
+			return d.Config{}, err
+		}
+	}
+
+	config.Workers = viper.GetInt("workers")
+
 	//nolint:nestif
 	if distros := viper.Sub("distros"); distros != nil {
 		if centos := distros.Sub(d.CentosType); centos != nil {
@@ -33,6 +41,22 @@ func GetDistroConfigAndVarsFromViper(viper *v.Viper) (d.Config, error) {
 			allsettings = centos.AllSettings()
 		}
 
+		if centosStream := distros.Sub(d.CentosStreamType); centosStream != nil {
+			if err := centosStream.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = centosStream.AllSettings()
+		}
+
+		if ubi := distros.Sub(d.UBIType); ubi != nil {
+			if err := ubi.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = ubi.AllSettings()
+		}
+
 		if amazonLinuxV1 := distros.Sub(d.AmazonLinuxV1Type); amazonLinuxV1 != nil {
 			if err := amazonLinuxV1.Unmarshal(&config); err != nil {
 				return d.Config{}, err
@@ -104,6 +128,86 @@ func GetDistroConfigAndVarsFromViper(viper *v.Viper) (d.Config, error) {
 
 			allsettings = archlinux.AllSettings()
 		}
+
+		if alpine := distros.Sub(d.AlpineType); alpine != nil {
+			if err := alpine.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = alpine.AllSettings()
+		}
+
+		if flatcar := distros.Sub(d.FlatcarType); flatcar != nil {
+			if err := flatcar.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = flatcar.AllSettings()
+		}
+
+		if bottlerocket := distros.Sub(d.BottlerocketType); bottlerocket != nil {
+			if err := bottlerocket.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = bottlerocket.AllSettings()
+		}
+
+		if rocky := distros.Sub(d.RockyType); rocky != nil {
+			if err := rocky.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = rocky.AllSettings()
+		}
+
+		if alma := distros.Sub(d.AlmaType); alma != nil {
+			if err := alma.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = alma.AllSettings()
+		}
+
+		if photon := distros.Sub(d.PhotonType); photon != nil {
+			if err := photon.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = photon.AllSettings()
+		}
+
+		if azureLinux := distros.Sub(d.AzureLinuxType); azureLinux != nil {
+			if err := azureLinux.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = azureLinux.AllSettings()
+		}
+
+		if cos := distros.Sub(d.CosType); cos != nil {
+			if err := cos.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = cos.AllSettings()
+		}
+
+		if gentoo := distros.Sub(d.GentooType); gentoo != nil {
+			if err := gentoo.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = gentoo.AllSettings()
+		}
+
+		if nixos := distros.Sub(d.NixOSType); nixos != nil {
+			if err := nixos.Unmarshal(&config); err != nil {
+				return d.Config{}, err
+			}
+
+			allsettings = nixos.AllSettings()
+		}
 	}
 
 	if _, ok := allsettings["vars"].(map[string]interface{}); ok {
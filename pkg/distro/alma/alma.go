@@ -0,0 +1,15 @@
+package alma
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/distro/rebuild"
+)
+
+// Alma is AlmaLinux, a RHEL rebuild.
+type Alma struct {
+	rebuild.Rebuild
+}
+
+func (a *Alma) Configure(config distro.Config) error {
+	return a.Rebuild.Configure(DefaultConfig, config)
+}
@@ -0,0 +1,27 @@
+package alma
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{Name: "repo", URL: "https://repo.almalinux.org/almalinux/"},
+
+		// EOL minor releases are moved to the vault.
+		{Name: "vault", URL: "https://vault.almalinux.org/"},
+	},
+	Repositories: []packages.Repository{
+		{Name: "BaseOS", URI: packages.URITemplate("/BaseOS/{{ .archs }}/os/")},
+		{Name: "AppStream", URI: packages.URITemplate("/AppStream/{{ .archs }}/os/")},
+		{Name: "devel", URI: packages.URITemplate("/devel/{{ .archs }}/os/")},
+	},
+	Archs: []packages.Architecture{
+		"aarch64",
+		"x86_64",
+		"ppc64le",
+		"s390x",
+	},
+	Versions: nil,
+}
@@ -0,0 +1,173 @@
+package alpine
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/output"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/apk"
+	"github.com/maxgio92/krawler/pkg/scrape"
+)
+
+type Alpine struct {
+	config distro.Config
+}
+
+func (a *Alpine) Configure(config distro.Config) error {
+	cfg, err := a.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	a.config = cfg
+
+	return nil
+}
+
+// SearchPackages scrapes each mirror, for each distro version, for each repository,
+// for each architecture, and returns slice of Package and optionally an error.
+func (a *Alpine) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	a.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, a.config.Mirrors, a.config.Output.Logger)
+
+	// Build distribution version-specific mirror root URLs.
+	perVersionMirrorURLs, err := a.buildPerVersionMirrorURLs(mirrors, a.config.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the APKINDEX URLs for each repository and architecture,
+	// for each distribution version.
+	indexURLs, err := a.buildIndexURLs(perVersionMirrorURLs, a.config.Repositories, a.config.Archs)
+	if err != nil {
+		return nil, errors.Wrap(err, "error building index URLs")
+	}
+
+	packageNames := options.PackageNames()
+	packageNames = append(packageNames, additionalKernelHeadersPackages...)
+
+	searchOptions := apk.NewSearchOptions(&options, a.config.Archs, indexURLs, packageNames)
+	apkPackages, err := apk.SearchPackages(ctx, searchOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching packages")
+	}
+
+	return apkPackages, nil
+}
+
+// Returns the list of version-specific mirror URLs.
+func (a *Alpine) buildPerVersionMirrorURLs(mirrors []packages.Mirror, versions []distro.Version) ([]*url.URL, error) {
+	versions, err := a.buildVersions(mirrors, versions)
+	if err != nil {
+		return []*url.URL{}, err
+	}
+
+	if (len(versions) > 0) && (len(mirrors) > 0) {
+		var versionRoots []*url.URL
+
+		for _, mirror := range mirrors {
+			for _, version := range versions {
+				versionRoot, err := url.Parse(mirror.URL + string(version))
+				if err != nil {
+					return nil, err
+				}
+
+				versionRoots = append(versionRoots, versionRoot)
+			}
+		}
+
+		return versionRoots, nil
+	}
+
+	return nil, distro.ErrNoDistroVersionSpecified
+}
+
+// Returns a list of distro versions, considering the user-provided configuration,
+// and if not, the ones available on configured mirrors.
+func (a *Alpine) buildVersions(mirrors []packages.Mirror, staticVersions []distro.Version) ([]distro.Version, error) {
+	if staticVersions != nil {
+		return staticVersions, nil
+	}
+
+	var dynamicVersions []distro.Version
+
+	dynamicVersions, err := a.crawlVersions(mirrors)
+	if err != nil {
+		return nil, errors.Wrap(err, "error crawling Alpine Linux versions")
+	}
+
+	return dynamicVersions, nil
+}
+
+// Returns the list of the current available distro versions, by scraping
+// the specified mirrors, dynamically.
+func (a *Alpine) crawlVersions(mirrors []packages.Mirror) ([]distro.Version, error) {
+	versions := []distro.Version{}
+
+	seedUrls := make([]*url.URL, 0, len(mirrors))
+
+	for _, mirror := range mirrors {
+		u, err := url.Parse(mirror.URL)
+		if err != nil {
+			return []distro.Version{}, err
+		}
+
+		seedUrls = append(seedUrls, u)
+	}
+
+	folderNames, err := scrape.CrawlFolders(
+		seedUrls,
+		DistroVersionRegex,
+		false,
+		a.config.Output.Verbosity >= output.DebugLevel,
+	)
+	if err != nil {
+		return []distro.Version{}, err
+	}
+
+	for _, v := range folderNames {
+		versions = append(versions, distro.Version(v))
+	}
+
+	return versions, nil
+}
+
+// Returns the list of APKINDEX URLs, one per version root, repository and architecture.
+// E.g. https://dl-cdn.alpinelinux.org/alpine/v3.18/main/x86_64/APKINDEX.tar.gz.
+func (a *Alpine) buildIndexURLs(roots []*url.URL, repositories []packages.Repository, archs []packages.Architecture) ([]string, error) {
+	var urls []string
+
+	for _, root := range roots {
+		for _, r := range repositories {
+			for _, arch := range archs {
+				u, err := url.JoinPath(root.String(), string(r.URI), string(arch), apk.IndexArchive)
+				if err != nil {
+					return nil, err
+				}
+
+				urls = append(urls, u)
+			}
+		}
+	}
+
+	return urls, nil
+}
+
+// Returns the list of default repositories from the default config.
+func (a *Alpine) getDefaultRepositories() []packages.Repository {
+	var repositories []packages.Repository
+
+	for _, repository := range DefaultConfig.Repositories {
+		if !distro.RepositorySliceContains(repositories, repository) {
+			repositories = append(repositories, repository)
+		}
+	}
+
+	return repositories
+}
@@ -0,0 +1,42 @@
+package alpine
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// Default regex to base the distro version detection on.
+	// Match both release branches (e.g. v3.18) and edge.
+	DistroVersionRegex = `^(v(0|[1-9]\d*)\.(0|[1-9]\d*)|edge)\/$`
+)
+
+var (
+	DefaultConfig = distro.Config{
+		Mirrors: []packages.Mirror{
+			{Name: "cdn", URL: "https://dl-cdn.alpinelinux.org/alpine/"},
+		},
+		Repositories: []packages.Repository{
+			{Name: "main", URI: packages.URITemplate("main")},
+			{Name: "community", URI: packages.URITemplate("community")},
+		},
+		Archs: []packages.Architecture{
+			"x86_64",
+			"aarch64",
+			"armv7",
+			"ppc64le",
+			"s390x",
+		},
+
+		// Crawl all versions by default, filtering names on the DistroVersionRegex regular expression.
+		Versions: nil,
+	}
+
+	// Alpine ships a kernel headers package for each kernel flavour.
+	additionalKernelHeadersPackages = []string{
+		"linux-virt-dev",
+		"linux-edge-dev",
+		"linux-vanilla-dev",
+		"linux-hardened-dev",
+	}
+)
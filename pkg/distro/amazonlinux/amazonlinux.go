@@ -2,12 +2,10 @@ package amazonlinux
 
 import (
 	"context"
-	"io"
-	"net/http"
 	"net/url"
-	"strings"
 
 	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/fetch"
 	"github.com/maxgio92/krawler/pkg/output"
 	p "github.com/maxgio92/krawler/pkg/packages"
 	"github.com/maxgio92/krawler/pkg/packages/rpm"
@@ -131,11 +129,14 @@ func (a *AmazonLinux) crawlVersions(mirrors []p.Mirror) ([]distro.Version, error
 
 // SearchPackages scrapes each mirror, for each distro version, for each repository,
 // for each architecture, and returns slice of Package and optionally an error.
-func (a *AmazonLinux) SearchPackages(options p.SearchOptions) ([]p.Package, error) {
+func (a *AmazonLinux) SearchPackages(ctx context.Context, options p.SearchOptions) ([]p.Package, error) {
 	a.Config.Output.Logger = options.Log()
 
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, a.Config.Mirrors, a.Config.Output.Logger)
+
 	// Build distribution version-specific mirror root URLs.
-	perVersionMirrorURLs, err := a.BuildMirrorURLs(a.Config.Mirrors, a.Config.Versions)
+	perVersionMirrorURLs, err := a.BuildMirrorURLs(mirrors, a.Config.Versions)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +149,7 @@ func (a *AmazonLinux) SearchPackages(options p.SearchOptions) ([]p.Package, erro
 	}
 
 	// Dereference repository URLs.
-	repositoryURLs, err := a.dereferenceRepositoryURLs(repositoriesURLrefs, a.Config.Archs)
+	repositoryURLs, err := a.dereferenceRepositoryURLs(ctx, repositoriesURLrefs, a.Config.Archs)
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +161,7 @@ func (a *AmazonLinux) SearchPackages(options p.SearchOptions) ([]p.Package, erro
 	}
 
 	searchOptions := rpm.NewSearchOptions(&options, a.Config.Archs, rss)
-	rpmPackages, err := rpm.SearchPackages(searchOptions)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -168,12 +169,12 @@ func (a *AmazonLinux) SearchPackages(options p.SearchOptions) ([]p.Package, erro
 	return rpmPackages, nil
 }
 
-func (a *AmazonLinux) dereferenceRepositoryURLs(repoURLs []*url.URL, archs []p.Architecture) ([]*url.URL, error) {
+func (a *AmazonLinux) dereferenceRepositoryURLs(ctx context.Context, repoURLs []*url.URL, archs []p.Architecture) ([]*url.URL, error) {
 	var urls []*url.URL
 
 	for _, ar := range archs {
 		for _, v := range repoURLs {
-			r, err := a.dereferenceRepositoryURL(v, ar)
+			r, err := a.dereferenceRepositoryURL(ctx, v, ar)
 			if err != nil {
 				return nil, err
 			}
@@ -187,7 +188,7 @@ func (a *AmazonLinux) dereferenceRepositoryURLs(repoURLs []*url.URL, archs []p.A
 	return urls, nil
 }
 
-func (a *AmazonLinux) dereferenceRepositoryURL(src *url.URL, arch p.Architecture) (*url.URL, error) {
+func (a *AmazonLinux) dereferenceRepositoryURL(ctx context.Context, src *url.URL, arch p.Architecture) (*url.URL, error) {
 	var dest *url.URL
 
 	mirrorListURL, err := url.JoinPath(src.String(), string(arch), "mirror.list")
@@ -195,38 +196,17 @@ func (a *AmazonLinux) dereferenceRepositoryURL(src *url.URL, arch p.Architecture
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, mirrorListURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(req)
+	urls, err := p.ResolveMirrorlist(ctx, mirrorListURL)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		a.Config.Output.Logger.Error("Amazon Linux v2023 repository URL not valid to be dereferenced")
+		a.Config.Output.Logger.WithError(err).Error("Amazon Linux v2023 repository URL not valid to be dereferenced")
 		//nolint:nilnil
 		return nil, nil
 	}
 
-	if resp.Body == nil {
-		a.Config.Output.Logger.Error("empty response from Amazon Linux v2023 repository reference URL")
-		//nolint:nilnil
-		return nil, nil
-	}
-
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get first repository URL available, no matter what the geolocation.
-	s := strings.Split(string(b), "\n")[0]
+	// Fail over to the other repository mirrors listed, no matter what the geolocation.
+	fetch.DefaultMirrors().Add(urls...)
 
-	dest, err = url.Parse(s)
+	dest, err = url.Parse(urls[0])
 	if err != nil {
 		return nil, err
 	}
@@ -2,13 +2,11 @@ package v1
 
 import (
 	"context"
-	"io"
-	"net/http"
 	"net/url"
-	"strings"
 
 	"github.com/maxgio92/krawler/pkg/distro"
 	common "github.com/maxgio92/krawler/pkg/distro/amazonlinux"
+	"github.com/maxgio92/krawler/pkg/fetch"
 	"github.com/maxgio92/krawler/pkg/packages"
 	"github.com/maxgio92/krawler/pkg/packages/rpm"
 )
@@ -23,11 +21,14 @@ func (a *AmazonLinux) Configure(config distro.Config) error {
 
 // GetPackages scrapes each mirror, for each distro version, for each repository,
 // for each architecture, and returns slice of Package and optionally an error.
-func (a *AmazonLinux) SearchPackages(options packages.SearchOptions) ([]packages.Package, error) {
+func (a *AmazonLinux) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
 	a.Config.Output.Logger = options.Log()
 
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, a.Config.Mirrors, a.Config.Output.Logger)
+
 	// Build distribution version-specific mirror root URLs.
-	perVersionMirrorURLs, err := a.BuildMirrorURLs(a.Config.Mirrors, a.Config.Versions)
+	perVersionMirrorURLs, err := a.BuildMirrorURLs(mirrors, a.Config.Versions)
 	if err != nil {
 		return nil, err
 	}
@@ -40,7 +41,7 @@ func (a *AmazonLinux) SearchPackages(options packages.SearchOptions) ([]packages
 	}
 
 	// Dereference repository URLs.
-	repositoryURLs, err := a.dereferenceRepositoryURLs(repositoriesURLrefs, a.Config.Archs)
+	repositoryURLs, err := a.dereferenceRepositoryURLs(ctx, repositoriesURLrefs, a.Config.Archs)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +53,7 @@ func (a *AmazonLinux) SearchPackages(options packages.SearchOptions) ([]packages
 	}
 
 	searchOptions := rpm.NewSearchOptions(&options, a.Config.Archs, rss)
-	rpmPackages, err := rpm.SearchPackages(searchOptions)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -60,12 +61,12 @@ func (a *AmazonLinux) SearchPackages(options packages.SearchOptions) ([]packages
 	return rpmPackages, nil
 }
 
-func (a *AmazonLinux) dereferenceRepositoryURLs(repoURLs []*url.URL, archs []packages.Architecture) ([]*url.URL, error) {
+func (a *AmazonLinux) dereferenceRepositoryURLs(ctx context.Context, repoURLs []*url.URL, archs []packages.Architecture) ([]*url.URL, error) {
 	var urls []*url.URL
 
 	for _, ar := range archs {
 		for _, v := range repoURLs {
-			r, err := a.dereferenceRepositoryURL(v, ar)
+			r, err := a.dereferenceRepositoryURL(ctx, v, ar)
 			if err != nil {
 				return nil, err
 			}
@@ -79,7 +80,7 @@ func (a *AmazonLinux) dereferenceRepositoryURLs(repoURLs []*url.URL, archs []pac
 	return urls, nil
 }
 
-func (a *AmazonLinux) dereferenceRepositoryURL(src *url.URL, arch packages.Architecture) (*url.URL, error) {
+func (a *AmazonLinux) dereferenceRepositoryURL(ctx context.Context, src *url.URL, arch packages.Architecture) (*url.URL, error) {
 	var dest *url.URL
 
 	mirrorListURL, err := url.JoinPath(src.String(), "mirror.list")
@@ -87,38 +88,17 @@ func (a *AmazonLinux) dereferenceRepositoryURL(src *url.URL, arch packages.Archi
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, mirrorListURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(req)
+	urls, err := packages.ResolveMirrorlist(ctx, mirrorListURL)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		a.Config.Output.Logger.Error("Amazon Linux v1 repository URL not valid to be dereferenced")
+		a.Config.Output.Logger.WithError(err).Error("Amazon Linux v1 repository URL not valid to be dereferenced")
 		//nolint:nilnil
 		return nil, nil
 	}
 
-	if resp.Body == nil {
-		a.Config.Output.Logger.Error("empty response from Amazon Linux v1 repository reference URL")
-		//nolint:nilnil
-		return nil, nil
-	}
-
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get first repository URL available, no matter what the geolocation.
-	s := strings.Split(strings.ReplaceAll(string(b), "$basearch", string(arch)), "\n")[0]
+	// Fail over to the other repository mirrors listed, no matter what the geolocation.
+	fetch.DefaultMirrors().Add(urls...)
 
-	dest, err = url.Parse(s)
+	dest, err = url.Parse(urls[0])
 	if err != nil {
 		return nil, err
 	}
@@ -19,6 +19,7 @@ limitations under the License.
 package archlinux
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -49,13 +50,16 @@ func (a *ArchLinux) Configure(config distro.Config) error {
 
 // GetPackages scrapes each mirror, for each distro version, for each repository,
 // for each architecture, and returns slice of Package and optionally an error.
-func (a *ArchLinux) SearchPackages(options packages.SearchOptions) ([]packages.Package, error) {
+func (a *ArchLinux) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
 	a.config.Output.Logger = options.Log()
 
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, a.config.Mirrors, a.config.Output.Logger)
+
 	mirrorURLs := []*url.URL{}
 
 	// Get current release mirrors.
-	currentURLs, err := a.buildMirrorURLs()
+	currentURLs, err := a.buildMirrorURLs(mirrors)
 	if err != nil {
 		return nil, errors.Wrap(err, "error building mirror URLs")
 	}
@@ -77,7 +81,7 @@ func (a *ArchLinux) SearchPackages(options packages.SearchOptions) ([]packages.P
 		return nil, err
 	}
 
-	packageNames := []string{options.PackageName()}
+	packageNames := options.PackageNames()
 	packageNames = append(packageNames, additionalKernelHeadersPackages...)
 
 	dbURLs, err := buildDBURLs(repositoryURLs)
@@ -86,7 +90,7 @@ func (a *ArchLinux) SearchPackages(options packages.SearchOptions) ([]packages.P
 	}
 
 	searchOptions := alpm.NewSearchOptions(&options, dbURLs, packageNames)
-	res, err := alpm.SearchPackages(searchOptions)
+	res, err := alpm.SearchPackages(ctx, searchOptions)
 	if err != nil {
 		return nil, errors.Wrap(err, "searching packages")
 	}
@@ -94,9 +98,9 @@ func (a *ArchLinux) SearchPackages(options packages.SearchOptions) ([]packages.P
 	return res, nil
 }
 
-func (a *ArchLinux) buildMirrorURLs() ([]*url.URL, error) {
+func (a *ArchLinux) buildMirrorURLs(mirrors []packages.Mirror) ([]*url.URL, error) {
 	mirrorURLs := []*url.URL{}
-	for _, v := range a.config.Mirrors {
+	for _, v := range mirrors {
 		u, err := url.Parse(v.URL)
 		if err != nil {
 			return nil, errors.Wrap(err, "error parsing mirror URL")
@@ -0,0 +1,180 @@
+package azurelinux
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/output"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/rpm"
+	"github.com/maxgio92/krawler/pkg/scrape"
+)
+
+// AzureLinux is Azure Linux, formerly CBL-Mariner.
+type AzureLinux struct {
+	config distro.Config
+}
+
+func (a *AzureLinux) Configure(config distro.Config) error {
+	cfg, err := a.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	a.config = cfg
+
+	return nil
+}
+
+// SearchPackages scrapes each mirror, for each distro version, for each repository,
+// for each architecture, and returns slice of Package and optionally an error.
+func (a *AzureLinux) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	a.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, a.config.Mirrors, a.config.Output.Logger)
+
+	// Build distribution version-specific mirror root URLs.
+	perVersionMirrorUrls, err := a.buildPerVersionMirrorUrls(mirrors, a.config.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build available repository URLs based on provided configuration,
+	// for each distribution version.
+	repositoryURLs, err := a.buildRepositoriesUrls(perVersionMirrorUrls, a.config.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get RPM packages from each repository.
+	rss := []string{}
+	for _, ru := range repositoryURLs {
+		rss = append(rss, ru.String())
+	}
+	searchOptions := rpm.NewSearchOptions(&options, a.config.Archs, rss)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpmPackages, nil
+}
+
+// Returns the list of version-specific mirror URLs.
+// Versions are built for each mirror, as CBL-Mariner and Azure Linux versions are published on different mirrors.
+func (a *AzureLinux) buildPerVersionMirrorUrls(mirrors []packages.Mirror, versions []distro.Version) ([]*url.URL, error) {
+	var versionRoots []*url.URL
+
+	for _, mirror := range mirrors {
+		mirrorVersions, err := a.buildVersions([]packages.Mirror{mirror}, versions)
+		if err != nil {
+			return []*url.URL{}, err
+		}
+
+		for _, version := range mirrorVersions {
+			versionRoot, err := url.Parse(mirror.URL + string(version))
+			if err != nil {
+				return nil, err
+			}
+
+			versionRoots = append(versionRoots, versionRoot)
+		}
+	}
+
+	if len(versionRoots) < 1 {
+		return nil, distro.ErrNoDistroVersionSpecified
+	}
+
+	return versionRoots, nil
+}
+
+// Returns a list of distro versions, considering the user-provided configuration,
+// and if not, the ones available on configured mirrors.
+func (a *AzureLinux) buildVersions(mirrors []packages.Mirror, staticVersions []distro.Version) ([]distro.Version, error) {
+	if staticVersions != nil {
+		return staticVersions, nil
+	}
+
+	var dynamicVersions []distro.Version
+
+	dynamicVersions, err := a.crawlVersions(mirrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamicVersions, nil
+}
+
+// Returns the list of the current available distro versions, by scraping
+// the specified mirrors, dynamically.
+func (a *AzureLinux) crawlVersions(mirrors []packages.Mirror) ([]distro.Version, error) {
+	versions := []distro.Version{}
+
+	seedUrls := make([]*url.URL, 0, len(mirrors))
+
+	for _, mirror := range mirrors {
+		u, err := url.Parse(mirror.URL)
+		if err != nil {
+			return []distro.Version{}, err
+		}
+
+		seedUrls = append(seedUrls, u)
+	}
+
+	folderNames, err := scrape.CrawlFolders(
+		seedUrls,
+		DistroVersionRegex,
+		false,
+		a.config.Output.Verbosity >= output.DebugLevel,
+	)
+	if err != nil {
+		return []distro.Version{}, err
+	}
+
+	for _, v := range folderNames {
+		versions = append(versions, distro.Version(v))
+	}
+
+	return versions, nil
+}
+
+// Returns the list of repositories URLs.
+func (a *AzureLinux) buildRepositoriesUrls(roots []*url.URL, repositories []packages.Repository) ([]*url.URL, error) {
+	var urls []*url.URL
+
+	for _, root := range roots {
+		//nolint:revive,stylecheck
+		for _, r := range repositories {
+			// Get repository URL from URI.
+			//nolint:revive,stylecheck
+			us, err := url.JoinPath(root.String(), string(r.URI))
+			if err != nil {
+				return nil, err
+			}
+
+			repositoryUrl, err := url.Parse(us)
+			if err != nil {
+				return nil, err
+			}
+
+			urls = append(urls, repositoryUrl)
+		}
+	}
+
+	return urls, nil
+}
+
+// Returns the list of default repositories from the default config.
+func (a *AzureLinux) getDefaultRepositories() []packages.Repository {
+	var repositories []packages.Repository
+
+	for _, repository := range DefaultConfig.Repositories {
+		if !distro.RepositorySliceContains(repositories, repository) {
+			repositories = append(repositories, repository)
+		}
+	}
+
+	return repositories
+}
@@ -0,0 +1,108 @@
+package azurelinux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestBuildRepositoriesUrls(t *testing.T) {
+	t.Parallel()
+
+	// CBL-Mariner and Azure Linux versions are published on different mirrors.
+	indexes := map[string]string{
+		"/cbl-mariner/": `<html><body><a href="1.0/">1.0/</a><a href="2.0/">2.0/</a><a href="keys/">keys/</a></body></html>`,
+		"/azurelinux/":  `<html><body><a href="3.0/">3.0/</a></body></html>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		index, ok := indexes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(index))
+	}))
+	t.Cleanup(server.Close)
+
+	mirrors := []packages.Mirror{
+		{Name: "cbl-mariner", URL: server.URL + "/cbl-mariner"},
+		{Name: "azurelinux", URL: server.URL + "/azurelinux"},
+	}
+
+	tests := map[string]struct {
+		versions []distro.Version
+		want     []string
+	}{
+		"crawled versions": {
+			want: []string{
+				server.URL + "/cbl-mariner/1.0/prod/base/x86_64/",
+				server.URL + "/cbl-mariner/1.0/prod/update/x86_64/",
+				server.URL + "/cbl-mariner/2.0/prod/base/x86_64/",
+				server.URL + "/cbl-mariner/2.0/prod/update/x86_64/",
+				server.URL + "/azurelinux/3.0/prod/base/x86_64/",
+				server.URL + "/azurelinux/3.0/prod/update/x86_64/",
+			},
+		},
+		"user-provided versions": {
+			versions: []distro.Version{"3.0"},
+			want: []string{
+				server.URL + "/cbl-mariner/3.0/prod/base/x86_64/",
+				server.URL + "/cbl-mariner/3.0/prod/update/x86_64/",
+				server.URL + "/azurelinux/3.0/prod/base/x86_64/",
+				server.URL + "/azurelinux/3.0/prod/update/x86_64/",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			a := &AzureLinux{}
+			assert.NilError(t, a.Configure(distro.Config{
+				Mirrors:  mirrors,
+				Archs:    []packages.Architecture{"x86_64"},
+				Versions: tt.versions,
+			}))
+
+			roots, err := a.buildPerVersionMirrorUrls(a.config.Mirrors, a.config.Versions)
+			assert.NilError(t, err)
+
+			urls, err := a.buildRepositoriesUrls(roots, a.config.Repositories)
+			assert.NilError(t, err)
+
+			got := []string{}
+			for _, u := range urls {
+				got = append(got, u.String())
+			}
+
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestBuildPerVersionMirrorUrlsWithoutVersions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><a href="keys/">keys/</a></body></html>`))
+	}))
+	t.Cleanup(server.Close)
+
+	a := &AzureLinux{}
+	assert.NilError(t, a.Configure(distro.Config{Mirrors: []packages.Mirror{{URL: server.URL}}}))
+
+	_, err := a.buildPerVersionMirrorUrls(a.config.Mirrors, nil)
+	assert.Assert(t, errors.Is(err, distro.ErrNoDistroVersionSpecified))
+}
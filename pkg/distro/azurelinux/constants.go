@@ -0,0 +1,28 @@
+package azurelinux
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// Default regex to base the distro version detection on (e.g. 2.0).
+	DistroVersionRegex = `^(0|[1-9]\d*)\.(0|[1-9]\d*)\/$`
+)
+
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		// Azure Linux was named CBL-Mariner up to version 2.0.
+		{Name: "cbl-mariner", URL: "https://packages.microsoft.com/cbl-mariner/"},
+		{Name: "azurelinux", URL: "https://packages.microsoft.com/azurelinux/"},
+	},
+	Repositories: []packages.Repository{
+		{Name: "base", URI: packages.URITemplate("/prod/base/{{ .archs }}/")},
+		{Name: "update", URI: packages.URITemplate("/prod/update/{{ .archs }}/")},
+	},
+	Archs: []packages.Architecture{
+		"x86_64",
+		"aarch64",
+	},
+	Versions: nil,
+}
@@ -0,0 +1,242 @@
+package bottlerocket
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Bottlerocket discovers the kernels shipped with Bottlerocket variants, from the
+// kmod kits published in the variant TUF repositories, as Bottlerocket does not
+// publish kernel packages.
+type Bottlerocket struct {
+	config distro.Config
+}
+
+func (b *Bottlerocket) Configure(config distro.Config) error {
+	cfg, err := b.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	b.config = cfg
+
+	return nil
+}
+
+// SearchPackages reads the TUF repository of each variant, for each architecture,
+// for each distro version, and returns a slice of Package and optionally an error.
+func (b *Bottlerocket) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	b.config.Output.Logger = options.Log()
+
+	repos, err := b.buildRepositories()
+	if err != nil {
+		return nil, errors.Wrap(err, "error building repository URLs")
+	}
+
+	repoURLs := make([]string, 0, len(repos))
+	for _, v := range repos {
+		repoURLs = append(repoURLs, v.url)
+	}
+
+	so := packages.NewSearchOptions(
+		options.PackageName(),
+		b.config.Archs,
+		repoURLs,
+		options.Verbosity(),
+		options.ProgressMessage(),
+		options.PackageFileNames()...,
+	)
+	so.SetParallelism(options.Parallelism())
+
+	versions := make([]string, 0, len(b.config.Versions))
+	for _, v := range b.config.Versions {
+		versions = append(versions, strings.TrimPrefix(string(v), "v"))
+	}
+
+	var result []packages.Package
+
+	// Run search producers.
+	for _, v := range repos {
+		repo := v
+
+		so.Go(ctx, func() {
+			searchPackagesFromRepository(
+				ctx,
+				func() {
+					so.Progress(1)
+					so.SigProducerCompletion()
+				},
+				so, repo, versions)
+		})
+	}
+
+	// Run collect consumer.
+	go so.Consume(
+		func(p ...packages.Package) {
+			if len(p) > 0 {
+				result = append(result, p...)
+				so.Log().Infof("New %d packages found", len(p))
+			}
+		},
+		func(e error) {
+			so.Log().Error(e)
+		},
+	)
+
+	// Wait for producers and consumers to complete and cleanup.
+	so.WaitAndClose()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// repository is the TUF repository of a variant, for a specific architecture.
+type repository struct {
+	variant string
+	arch    string
+	url     string
+}
+
+// buildRepositories returns the list of the TUF repositories.
+// E.g. https://updates.bottlerocket.aws/2020-07-07/aws-k8s-1.28/x86_64/.
+func (b *Bottlerocket) buildRepositories() ([]repository, error) {
+	var repos []repository
+
+	for _, mirror := range b.config.Mirrors {
+		for _, r := range b.config.Repositories {
+			for _, arch := range b.config.Archs {
+				u, err := url.JoinPath(mirror.URL, string(r.URI), string(arch), "/")
+				if err != nil {
+					return nil, err
+				}
+
+				repos = append(repos, repository{
+					variant: strings.Trim(string(r.URI), "/"),
+					arch:    string(arch),
+					url:     u,
+				})
+			}
+		}
+	}
+
+	return repos, nil
+}
+
+// searchPackagesFromRepository looks for the kmod kits published in the repository,
+// and sends to the queue a Package for each kernel found.
+//
+//nolint:funlen
+func searchPackagesFromRepository(ctx context.Context, doneFunc func(), so *packages.SearchOptions, repo repository, versions []string) {
+	defer doneFunc()
+
+	so.Log().WithField("url", repo.url).Info("Analysing repository")
+
+	ts, err := getTargets(ctx, repo.url)
+	if err != nil {
+		so.SendError(ctx, errors.Wrap(err, "error getting repository targets"))
+
+		return
+	}
+
+	pattern := regexp.MustCompile(kmodKitRegex)
+
+	kits := map[string]string{}
+
+	for name, target := range ts {
+		match := pattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		arch := match[pattern.SubexpIndex("arch")]
+		variant := match[pattern.SubexpIndex("variant")]
+		version := match[pattern.SubexpIndex("version")]
+
+		if arch != repo.arch || variant != repo.variant {
+			continue
+		}
+
+		if len(versions) > 0 && !slices.Contains(versions, version) {
+			continue
+		}
+
+		u, err := targetURL(repo.url, name, target)
+		if err != nil {
+			so.SendError(ctx, err)
+
+			continue
+		}
+
+		kits[version] = u
+	}
+
+	queue := packages.NewMPSCQueue(len(kits), so.Parallelism())
+
+	for k, v := range kits {
+		version, kitURL := k, v
+
+		queue.Go(ctx, func() {
+			defer queue.SigProducerCompletion()
+
+			so.Log().WithField("url", kitURL).Debug("Reading kernel release from kmod kit")
+
+			p, err := getPackageFromKmodKit(ctx, kitURL)
+			if err != nil {
+				queue.SendError(ctx, errors.Wrap(err, kitURL))
+
+				return
+			}
+
+			p.Name = so.PackageName()
+			p.Arch = repo.arch
+			p.Variant = repo.variant
+			p.VariantRelease = version
+
+			queue.SendMessage(ctx, p)
+		})
+	}
+
+	go queue.Consume(
+		func(p ...packages.Package) {
+			so.SendMessage(ctx, p...)
+		},
+		func(e error) {
+			so.SendError(ctx, e)
+		},
+	)
+
+	queue.WaitAndClose()
+}
+
+// getPackageFromKmodKit returns the Package of the kernel release shipped with the kmod kit.
+// The kit is read only until the kernel release is found, and its kernel configuration
+// is read by the Package files.
+func getPackageFromKmodKit(ctx context.Context, kitURL string) (*Package, error) {
+	body, err := fetch.Get(ctx, kitURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	release, err := readKernelRelease(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Package{
+		Version: release,
+		url:     kitURL,
+	}, nil
+}
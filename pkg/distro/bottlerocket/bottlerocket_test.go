@@ -0,0 +1,252 @@
+package bottlerocket
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	testKernelRelease = "6.1.72"
+	testKernelConfig  = "CONFIG_CC_VERSION_TEXT=\"x86_64-bottlerocket-linux-gnu-gcc (Buildroot 2022.11.1) 11.3.0\"\nCONFIG_GCC_VERSION=110300\n"
+	testKitHash       = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+)
+
+// newTarXz returns an xz-compressed tarball of the files, in order.
+func newTarXz(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	xzw, err := xz.NewWriter(&buf)
+	assert.NilError(t, err)
+
+	tw := tar.NewWriter(xzw)
+	for _, f := range files {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: f[0], Mode: 0o644, Size: int64(len(f[1])), Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(f[1]))
+		assert.NilError(t, err)
+	}
+
+	assert.NilError(t, tw.Close())
+	assert.NilError(t, xzw.Close())
+
+	return buf.Bytes()
+}
+
+// newKmodKit returns a kmod kit, shipping the kernel development sources as a nested tarball.
+func newKmodKit(t *testing.T) []byte {
+	t.Helper()
+
+	devel := newTarXz(t,
+		[2]string{"kernel-devel/Makefile", "VERSION = 6\n"},
+		[2]string{"kernel-devel/.config", testKernelConfig},
+		[2]string{"kernel-devel/include/config/kernel.release", testKernelRelease + "\n"},
+	)
+
+	return newTarXz(t,
+		[2]string{"x86_64-aws-k8s-1.28-kmod-kit-v1.16.0/toolchain/README", "toolchain\n"},
+		[2]string{"x86_64-aws-k8s-1.28-kmod-kit-v1.16.0/kernel-devel.tar.xz", string(devel)},
+	)
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	kit := newKmodKit(t)
+
+	files := map[string]string{
+		"/aws-k8s-1.28/x86_64/metadata/timestamp.json":  `{"signed": {"meta": {"snapshot.json": {"version": 3}}}}`,
+		"/aws-k8s-1.28/x86_64/metadata/3.snapshot.json": `{"signed": {"meta": {"targets.json": {"version": 7}}}}`,
+		"/aws-k8s-1.28/x86_64/metadata/7.targets.json": `{"signed": {"targets": {
+			"x86_64-aws-k8s-1.28-kmod-kit-v1.16.0.tar.xz": {"length": 1024, "hashes": {"sha256": "` + testKitHash + `"}},
+			"x86_64-aws-k8s-1.28-kmod-kit-v1.15.0.tar.xz": {"length": 1024, "hashes": {}},
+			"x86_64-aws-k8s-1.27-kmod-kit-v1.16.0.tar.xz": {"length": 1024, "hashes": {}},
+			"bottlerocket-aws-k8s-1.28-x86_64-1.16.0.img.lz4": {"length": 1024, "hashes": {}}
+		}}}`,
+		"/aws-k8s-1.28/x86_64/targets/" + testKitHash + ".x86_64-aws-k8s-1.28-kmod-kit-v1.16.0.tar.xz": string(kit),
+		"/aws-k8s-1.28/x86_64/targets/x86_64-aws-k8s-1.28-kmod-kit-v1.15.0.tar.xz":                     string(kit),
+		"/aws-k8s-1.28/aarch64/metadata/timestamp.json":                                                `{"signed": {"meta": {}}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGetTargets(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	targets, err := getTargets(context.Background(), server.URL+"/aws-k8s-1.28/x86_64/")
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 4)
+	assert.DeepEqual(t, targets["x86_64-aws-k8s-1.28-kmod-kit-v1.16.0.tar.xz"], Target{
+		Length: 1024,
+		Hashes: map[string]string{targetsHash: testKitHash},
+	})
+
+	// The snapshot role is not listed by the timestamp role.
+	_, err = getTargets(context.Background(), server.URL+"/aws-k8s-1.28/aarch64/")
+	assert.Assert(t, errors.Is(err, ErrMetadataNotFound))
+
+	_, err = getTargets(context.Background(), server.URL+"/aws-k8s-1.27/x86_64/")
+	assert.ErrorContains(t, err, "unexpected HTTP status code")
+}
+
+func TestTargetURL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		target Target
+		want   string
+	}{
+		"consistent snapshot": {
+			target: Target{Hashes: map[string]string{targetsHash: testKitHash}},
+			want:   "https://updates.bottlerocket.aws/2020-07-07/aws-k8s-1.28/x86_64/targets/" + testKitHash + ".kit.tar.xz",
+		},
+		"without hash": {
+			target: Target{Hashes: map[string]string{"sha512": "00"}},
+			want:   "https://updates.bottlerocket.aws/2020-07-07/aws-k8s-1.28/x86_64/targets/kit.tar.xz",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := targetURL("https://updates.bottlerocket.aws/2020-07-07/aws-k8s-1.28/x86_64/", "kit.tar.xz", tt.target)
+
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestReadKernelRelease(t *testing.T) {
+	t.Parallel()
+
+	release, err := readKernelRelease(bytes.NewReader(newKmodKit(t)))
+	assert.NilError(t, err)
+	assert.Equal(t, release, testKernelRelease)
+
+	_, err = readKernelRelease(bytes.NewReader(newTarXz(t, [2]string{"kit/README", "no kernel\n"})))
+	assert.Assert(t, errors.Is(err, ErrKernelReleaseNotFound))
+
+	_, err = readKernelRelease(bytes.NewReader([]byte("not an archive")))
+	assert.Assert(t, err != nil)
+}
+
+func TestSearchPackages(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	tests := map[string]struct {
+		versions []distro.Version
+		want     map[string]string
+	}{
+		"all the releases": {
+			want: map[string]string{
+				"1.16.0": server.URL + "/aws-k8s-1.28/x86_64/targets/" + testKitHash + ".x86_64-aws-k8s-1.28-kmod-kit-v1.16.0.tar.xz",
+				"1.15.0": server.URL + "/aws-k8s-1.28/x86_64/targets/x86_64-aws-k8s-1.28-kmod-kit-v1.15.0.tar.xz",
+			},
+		},
+		"release": {
+			versions: []distro.Version{"v1.15.0"},
+			want: map[string]string{
+				"1.15.0": server.URL + "/aws-k8s-1.28/x86_64/targets/x86_64-aws-k8s-1.28-kmod-kit-v1.15.0.tar.xz",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &Bottlerocket{}
+			assert.NilError(t, b.Configure(distro.Config{
+				Mirrors:      []packages.Mirror{{URL: server.URL}},
+				Repositories: []packages.Repository{{URI: "aws-k8s-1.28"}},
+				Archs:        []packages.Architecture{"x86_64"},
+				Versions:     tt.versions,
+			}))
+
+			result, err := b.SearchPackages(context.Background(), *packages.NewSearchOptions("kernel", nil, nil, 0, ""))
+			assert.NilError(t, err)
+
+			got := map[string]string{}
+
+			for _, p := range result {
+				//nolint:forcetypeassert
+				kernel := p.(*Package)
+
+				assert.Equal(t, kernel.GetName(), "kernel")
+				assert.Equal(t, kernel.GetVersion(), testKernelRelease)
+				assert.Equal(t, kernel.GetArch(), "x86_64")
+				assert.Equal(t, kernel.Variant, "aws-k8s-1.28")
+
+				got[kernel.VariantRelease] = kernel.URL()
+			}
+
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestPackageFiles(t *testing.T) {
+	t.Parallel()
+
+	kits := map[string][]byte{
+		"/kit.tar.xz":           newKmodKit(t),
+		"/kit-no-config.tar.xz": newTarXz(t, [2]string{"kit/README", "no kernel\n"}),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(kits[r.URL.Path])
+	}))
+	t.Cleanup(server.Close)
+
+	files := map[string]string{}
+	visit := func(name string, r io.Reader) error {
+		content, err := io.ReadAll(r)
+		files[name] = string(content)
+
+		return err
+	}
+
+	// The kernel configuration is read from the kmod kit, downloaded visiting the files.
+	assert.NilError(t, (&Package{url: server.URL + "/kit.tar.xz"}).Files(context.Background(), visit))
+	assert.DeepEqual(t, files, map[string]string{kernelConfigFile: testKernelConfig})
+
+	// The kmod kits without kernel configuration have no files.
+	files = map[string]string{}
+	assert.NilError(t, (&Package{url: server.URL + "/kit-no-config.tar.xz"}).Files(context.Background(), visit))
+	assert.DeepEqual(t, files, map[string]string{})
+}
@@ -0,0 +1,51 @@
+package bottlerocket
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// TUF repository metadata files.
+	// More on this here: https://theupdateframework.github.io/specification/latest/.
+	metadataPath  = "metadata"
+	targetsPath   = "targets"
+	timestampFile = "timestamp.json"
+	snapshotFile  = "snapshot.json"
+	targetsFile   = "targets.json"
+	targetsHash   = "sha256"
+
+	// The kernel module development kit target, published for each variant release,
+	// ships the kernel development sources along with the kernel configuration.
+	// E.g. x86_64-aws-k8s-1.28-kmod-kit-v1.16.0.tar.xz.
+	kmodKitRegex = `^(?P<arch>[^-]+)-(?P<variant>.+)-kmod-kit-v(?P<version>.+)\.tar\.xz$`
+
+	kmodKitArchive    = ".tar.xz"
+	kernelReleaseFile = "kernel.release"
+	kernelConfigFile  = ".config"
+)
+
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{Name: "aws", URL: "https://updates.bottlerocket.aws/2020-07-07/"},
+	},
+
+	// Each variant is served by a dedicated TUF repository, per architecture.
+	Repositories: []packages.Repository{
+		{Name: "aws-k8s-1.28", URI: packages.URITemplate("aws-k8s-1.28")},
+		{Name: "aws-k8s-1.27", URI: packages.URITemplate("aws-k8s-1.27")},
+		{Name: "aws-k8s-1.26", URI: packages.URITemplate("aws-k8s-1.26")},
+		{Name: "aws-k8s-1.25", URI: packages.URITemplate("aws-k8s-1.25")},
+		{Name: "aws-k8s-1.24", URI: packages.URITemplate("aws-k8s-1.24")},
+		{Name: "aws-ecs-1", URI: packages.URITemplate("aws-ecs-1")},
+		{Name: "aws-ecs-2", URI: packages.URITemplate("aws-ecs-2")},
+		{Name: "aws-dev", URI: packages.URITemplate("aws-dev")},
+	},
+	Archs: []packages.Architecture{
+		"x86_64",
+		"aarch64",
+	},
+
+	// All the variant releases available in the repositories by default.
+	Versions: nil,
+}
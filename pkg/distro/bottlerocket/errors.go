@@ -0,0 +1,8 @@
+package bottlerocket
+
+import "errors"
+
+var (
+	ErrMetadataNotFound      = errors.New("metadata role not found in the repository")
+	ErrKernelReleaseNotFound = errors.New("kernel release not found in the kmod kit")
+)
@@ -0,0 +1,90 @@
+package bottlerocket
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"pault.ag/go/debian/deb"
+)
+
+// kitFileVisitor is called with each regular file of a kmod kit, by base name,
+// and returns whether the remaining files are to be skipped.
+type kitFileVisitor func(name string, content io.Reader) (bool, error)
+
+// readKernelRelease reads the kernel release from a kmod kit archive, reading the kit only until found.
+func readKernelRelease(r io.Reader) (string, error) {
+	var release string
+
+	_, err := walkKmodKit(r, func(name string, content io.Reader) (bool, error) {
+		if name != kernelReleaseFile {
+			return false, nil
+		}
+
+		var buf bytes.Buffer
+
+		//nolint:gosec
+		if _, err := io.Copy(&buf, content); err != nil {
+			return true, err
+		}
+
+		release = strings.TrimSpace(buf.String())
+
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if release == "" {
+		return "", ErrKernelReleaseNotFound
+	}
+
+	return release, nil
+}
+
+// walkKmodKit visits the regular files of a kmod kit archive, in order, until the visitor skips the remaining ones,
+// and returns whether they're skipped. The kit is an xz-compressed tarball, which in turn ships the kernel
+// development sources as a nested xz-compressed tarball, whose files are visited too.
+func walkKmodKit(r io.Reader, visit kitFileVisitor) (bool, error) {
+	// The decompressors are by compression extension (e.g. .xz).
+	xzr, err := deb.DecompressorFor(filepath.Ext(kmodKitArchive))(r)
+	if err != nil {
+		return true, errors.Wrap(err, "error decompressing archive")
+	}
+	defer xzr.Close()
+
+	tr := tar.NewReader(xzr)
+
+	for {
+		var header *tar.Header
+
+		header, err = tr.Next()
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+
+		if err != nil {
+			return true, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		var skip bool
+
+		if strings.HasSuffix(header.Name, kmodKitArchive) {
+			skip, err = walkKmodKit(tr, visit)
+		} else {
+			skip, err = visit(filepath.Base(header.Name), tr)
+		}
+
+		if skip || err != nil {
+			return true, err
+		}
+	}
+}
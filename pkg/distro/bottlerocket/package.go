@@ -0,0 +1,64 @@
+package bottlerocket
+
+import (
+	"context"
+	"io"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Package represents the kernel shipped with a Bottlerocket variant release, for a specific architecture.
+type Package struct {
+	Name           string
+	Version        string
+	Release        string
+	Arch           string
+	Variant        string
+	VariantRelease string
+	url            string
+}
+
+func (p *Package) GetName() string {
+	return p.Name
+}
+
+func (p *Package) GetVersion() string {
+	return p.Version
+}
+
+func (p *Package) GetRelease() string {
+	return p.Release
+}
+
+func (p *Package) GetArch() string {
+	return p.Arch
+}
+
+func (p *Package) GetLocation() string {
+	return p.url
+}
+
+func (p *Package) URL() string {
+	return p.url
+}
+
+// Files downloads the kmod kit, and visits the kernel configuration, if shipped with it.
+// The kit is read only until the kernel configuration is visited.
+func (p *Package) Files(ctx context.Context, visit packages.FileVisitor) error {
+	body, err := fetch.Get(ctx, p.url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = walkKmodKit(body, func(name string, content io.Reader) (bool, error) {
+		if name != kernelConfigFile {
+			return false, nil
+		}
+
+		return packages.VisitFile(visit, name, content)
+	})
+
+	return err
+}
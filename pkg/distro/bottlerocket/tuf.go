@@ -0,0 +1,111 @@
+package bottlerocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+// metaFile is a reference to a versioned metadata role file, as listed in the
+// timestamp and snapshot roles.
+type metaFile struct {
+	Version int `json:"version"`
+}
+
+// meta is the signed content of the timestamp and snapshot roles.
+type meta struct {
+	Signed struct {
+		Meta map[string]metaFile `json:"meta"`
+	} `json:"signed"`
+}
+
+// Target is a file published in the repository, as listed in the targets role.
+type Target struct {
+	Length int64             `json:"length"`
+	Hashes map[string]string `json:"hashes"`
+}
+
+// targets is the signed content of the targets role.
+type targets struct {
+	Signed struct {
+		Targets map[string]Target `json:"targets"`
+	} `json:"signed"`
+}
+
+// getTargets walks the TUF role chain of the repository (timestamp, snapshot, targets),
+// and returns the published targets, indexed by name.
+// Signatures are not verified.
+func getTargets(ctx context.Context, repoURL string) (map[string]Target, error) {
+	timestamp := &meta{}
+
+	timestampURL, err := url.JoinPath(repoURL, metadataPath, timestampFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = getJSON(ctx, timestampURL, timestamp); err != nil {
+		return nil, err
+	}
+
+	snapshotURL, err := versionedMetadataURL(repoURL, timestamp, snapshotFile)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &meta{}
+	if err = getJSON(ctx, snapshotURL, snapshot); err != nil {
+		return nil, err
+	}
+
+	targetsURL, err := versionedMetadataURL(repoURL, snapshot, targetsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &targets{}
+	if err = getJSON(ctx, targetsURL, t); err != nil {
+		return nil, err
+	}
+
+	return t.Signed.Targets, nil
+}
+
+// targetURL returns the URL of the target, prefixed by its hash as for consistent snapshots.
+func targetURL(repoURL, name string, target Target) (string, error) {
+	hash, ok := target.Hashes[targetsHash]
+	if !ok {
+		return url.JoinPath(repoURL, targetsPath, name)
+	}
+
+	return url.JoinPath(repoURL, targetsPath, hash+"."+name)
+}
+
+// versionedMetadataURL returns the URL of the version of the role file referenced by parent.
+// E.g. metadata/42.snapshot.json.
+func versionedMetadataURL(repoURL string, parent *meta, role string) (string, error) {
+	m, ok := parent.Signed.Meta[role]
+	if !ok {
+		return "", errors.Wrap(ErrMetadataNotFound, role)
+	}
+
+	return url.JoinPath(repoURL, metadataPath, strconv.Itoa(m.Version)+"."+role)
+}
+
+func getJSON(ctx context.Context, u string, v interface{}) error {
+	body, err := fetch.Get(ctx, u)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err = json.NewDecoder(body).Decode(v); err != nil {
+		return errors.Wrap(err, u)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package centos
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/maxgio92/krawler/pkg/distro"
@@ -12,6 +13,10 @@ import (
 
 type Centos struct {
 	config distro.Config
+
+	// The regex to base the distro version detection on.
+	// If empty, CentosMirrorsDistroVersionRegex is used.
+	versionRegex string
 }
 
 func (c *Centos) Configure(config distro.Config) error {
@@ -27,11 +32,22 @@ func (c *Centos) Configure(config distro.Config) error {
 
 // GetPackages scrapes each mirror, for each distro version, for each repository,
 // for each architecture, and returns slice of Package and optionally an error.
-func (c *Centos) SearchPackages(options packages.SearchOptions) ([]packages.Package, error) {
+func (c *Centos) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
 	c.config.Output.Logger = options.Log()
 
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, c.config.Mirrors, c.config.Output.Logger)
+
+	versions, err := c.buildVersions(mirrors, c.config.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail over to the mirrors listed by the mirrorlists of the versions too.
+	distro.ResolveMirrorlists(ctx, c.config.Mirrors, versions, c.config.Archs, c.config.Output.Logger)
+
 	// Build distribution version-specific mirror root URLs.
-	perVersionMirrorUrls, err := c.buildPerVersionMirrorUrls(c.config.Mirrors, c.config.Versions)
+	perVersionMirrorUrls, err := c.buildPerVersionMirrorUrls(mirrors, versions)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +65,7 @@ func (c *Centos) SearchPackages(options packages.SearchOptions) ([]packages.Pack
 		rss = append(rss, ru.String())
 	}
 	searchOptions := rpm.NewSearchOptions(&options, c.config.Archs, rss)
-	rpmPackages, err := rpm.SearchPackages(searchOptions)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -117,9 +133,14 @@ func (c *Centos) crawlVersions(mirrors []packages.Mirror) ([]distro.Version, err
 		seedUrls = append(seedUrls, u)
 	}
 
+	versionRegex := c.versionRegex
+	if versionRegex == "" {
+		versionRegex = CentosMirrorsDistroVersionRegex
+	}
+
 	folderNames, err := scrape.CrawlFolders(
 		seedUrls,
-		CentosMirrorsDistroVersionRegex,
+		versionRegex,
 		false,
 		c.config.Output.Verbosity >= output.DebugLevel,
 	)
@@ -161,10 +182,10 @@ func (c *Centos) buildRepositoriesUrls(roots []*url.URL, repositories []packages
 }
 
 // Returns the list of default repositories from the default config.
-func (c *Centos) getDefaultRepositories() []packages.Repository {
+func (c *Centos) getDefaultRepositories(def distro.Config) []packages.Repository {
 	var repositories []packages.Repository
 
-	for _, repository := range DefaultConfig.Repositories {
+	for _, repository := range def.Repositories {
 		if !distro.RepositorySliceContains(repositories, repository) {
 			repositories = append(repositories, repository)
 		}
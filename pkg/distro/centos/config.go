@@ -62,11 +62,11 @@ func (c *Centos) mergeConfig(def distro.Config, config distro.Config) (distro.Co
 	}
 
 	if len(config.Repositories) < 1 {
-		config.Repositories = c.getDefaultRepositories()
+		config.Repositories = c.getDefaultRepositories(def)
 	} else {
 		for _, repository := range config.Repositories {
 			if repository.URI == "" {
-				config.Repositories = c.getDefaultRepositories()
+				config.Repositories = c.getDefaultRepositories(def)
 
 				break
 			}
@@ -8,12 +8,16 @@ import (
 const (
 	// Default regex to base the distro version detection on.
 	CentosMirrorsDistroVersionRegex = `^(0|[1-9]\d*)(\.(0|[1-9]\d*)?)?(\.(0|[1-9]\d*)?)?(-[a-zA-Z\d][-a-zA-Z.\d]*)?(\+[a-zA-Z\d][-a-zA-Z.\d]*)?\/$`
+
+	// Regex to base the CentOS Stream version detection on (e.g. 9-stream).
+	StreamMirrorsDistroVersionRegex = `^(0|[1-9]\d*)-stream\/$`
 )
 
 var DefaultConfig = distro.Config{
 	Mirrors: []packages.Mirror{
 		{URL: "https://mirrors.edge.kernel.org/centos/"},
-		{URL: "https://archive.kernel.org/centos-vault/"},
+		{URL: "https://archive.kernel.org/centos-vault/", Group: "vault"},
+		{URL: "https://vault.centos.org/", Group: "vault"},
 	},
 	Repositories: []packages.Repository{
 		{Name: "base", URI: packages.URITemplate("/os/{{ .archs }}/")},
@@ -29,3 +33,31 @@ var DefaultConfig = distro.Config{
 	},
 	Versions: nil,
 }
+
+// StreamDefaultConfig is the default configuration for CentOS Stream, which is
+// published with a different layout on dedicated mirrors.
+// EOL Stream versions are moved to the vault.
+var StreamDefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{
+			Name:       "stream",
+			URL:        "https://mirror.stream.centos.org/",
+			Mirrorlist: "https://mirrors.centos.org/metalink?repo=centos-baseos-{{ .versions }}&arch={{ .archs }}",
+		},
+		{Name: "vault", URL: "https://vault.centos.org/", Group: "vault"},
+		{Name: "vault", URL: "https://archive.kernel.org/centos-vault/", Group: "vault"},
+	},
+	Repositories: []packages.Repository{
+		{Name: "BaseOS", URI: packages.URITemplate("/BaseOS/{{ .archs }}/os/")},
+		{Name: "AppStream", URI: packages.URITemplate("/AppStream/{{ .archs }}/os/")},
+		{Name: "CRB", URI: packages.URITemplate("/CRB/{{ .archs }}/os/")},
+		{Name: "PowerTools", URI: packages.URITemplate("/PowerTools/{{ .archs }}/os/")},
+	},
+	Archs: []packages.Architecture{
+		"aarch64",
+		"x86_64",
+		"ppc64le",
+		"s390x",
+	},
+	Versions: nil,
+}
@@ -0,0 +1,23 @@
+package centos
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+)
+
+// Stream is CentOS in Stream mode, which only crawls Stream versions
+// (e.g. 9-stream) from the Stream mirrors.
+type Stream struct {
+	Centos
+}
+
+func (s *Stream) Configure(config distro.Config) error {
+	cfg, err := s.buildConfig(StreamDefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	s.config = cfg
+	s.versionRegex = StreamMirrorsDistroVersionRegex
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package centos
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const testMirrorIndex = `<html><body>
+<a href="../">../</a>
+<a href="7.9.2009/">7.9.2009/</a>
+<a href="8/">8/</a>
+<a href="8-stream/">8-stream/</a>
+<a href="9-stream/">9-stream/</a>
+<a href="SIGs/">SIGs/</a>
+<a href="TIME">TIME</a>
+</body></html>`
+
+func TestStreamConfigure(t *testing.T) {
+	t.Parallel()
+
+	s := &Stream{}
+	assert.NilError(t, s.Configure(distro.Config{Archs: []packages.Architecture{"x86_64"}}))
+
+	assert.DeepEqual(t, s.config.Mirrors, StreamDefaultConfig.Mirrors)
+	assert.Equal(t, s.versionRegex, StreamMirrorsDistroVersionRegex)
+
+	uris := []string{}
+	for _, r := range s.config.Repositories {
+		uris = append(uris, string(r.URI))
+	}
+
+	assert.DeepEqual(t, uris, []string{"/BaseOS/x86_64/os/", "/AppStream/x86_64/os/", "/CRB/x86_64/os/", "/PowerTools/x86_64/os/"})
+}
+
+func TestStreamCrawlVersions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(testMirrorIndex))
+	}))
+	t.Cleanup(server.Close)
+
+	mirrors := []packages.Mirror{{URL: server.URL + "/"}}
+
+	tests := map[string]struct {
+		versionRegex string
+		want         []distro.Version
+	}{
+		"stream": {
+			versionRegex: StreamMirrorsDistroVersionRegex,
+			want:         []distro.Version{"8-stream", "9-stream"},
+		},
+		"centos": {
+			want: []distro.Version{"7.9.2009", "8", "8-stream", "9-stream"},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &Centos{versionRegex: tt.versionRegex}
+
+			got, err := c.crawlVersions(mirrors)
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestStreamRepositoriesUrls(t *testing.T) {
+	t.Parallel()
+
+	s := &Stream{}
+	assert.NilError(t, s.Configure(distro.Config{
+		Archs:    []packages.Architecture{"aarch64"},
+		Versions: []distro.Version{"9-stream"},
+	}))
+
+	roots, err := s.buildPerVersionMirrorUrls(s.config.Mirrors, s.config.Versions)
+	assert.NilError(t, err)
+
+	urls, err := s.buildRepositoriesUrls(roots[:1], s.config.Repositories[:1])
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, urls, []*url.URL{{Scheme: "https", Host: "mirror.stream.centos.org", Path: "/9-stream/BaseOS/aarch64/os/"}})
+}
@@ -8,6 +8,8 @@ const (
 	// DefaultArch is the default architecture for which scrape for packages.
 	DefaultArch          = X8664Arch
 	CentosType           = "centos"
+	CentosStreamType     = "centosstream"
+	UBIType              = "ubi"
 	AmazonLinuxV1Type    = "amazonlinux"
 	AmazonLinuxV2Type    = "amazonlinux2"
 	AmazonLinuxV2022Type = "amazonlinux2022"
@@ -17,4 +19,14 @@ const (
 	FedoraType           = "fedora"
 	OracleType           = "oracle"
 	ArchLinuxType        = "archlinux"
+	AlpineType           = "alpine"
+	FlatcarType          = "flatcar"
+	BottlerocketType     = "bottlerocket"
+	RockyType            = "rocky"
+	AlmaType             = "alma"
+	PhotonType           = "photon"
+	AzureLinuxType       = "azurelinux"
+	CosType              = "cos"
+	GentooType           = "gentoo"
+	NixOSType            = "nixos"
 )
@@ -0,0 +1,76 @@
+package cos
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func (c *Cos) buildConfig(def distro.Config, user distro.Config) (distro.Config, error) {
+	config := c.mergeConfig(def, user)
+
+	err := c.sanitizeConfig(&config)
+	if err != nil {
+		return distro.Config{}, err
+	}
+
+	return config, nil
+}
+
+// Returns the final configuration by merging the default with the user provided.
+// Repositories are ignored, as COS images are not distributed through package repositories.
+func (c *Cos) mergeConfig(def distro.Config, config distro.Config) distro.Config {
+	if len(config.Archs) < 1 {
+		config.Archs = def.Archs
+	} else {
+		for _, arch := range config.Archs {
+			if arch == "" {
+				config.Archs = def.Archs
+
+				break
+			}
+		}
+	}
+
+	if len(config.Mirrors) < 1 {
+		config.Mirrors = def.Mirrors
+	} else {
+		for _, mirror := range config.Mirrors {
+			if mirror.URL == "" {
+				config.Mirrors = def.Mirrors
+
+				break
+			}
+		}
+	}
+
+	config.Repositories = nil
+
+	return config
+}
+
+func (c *Cos) sanitizeConfig(config *distro.Config) error {
+	err := c.sanitizeMirrors(&config.Mirrors)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Cos) sanitizeMirrors(mirrors *[]packages.Mirror) error {
+	for i, mirror := range *mirrors {
+		if !strings.HasSuffix(mirror.URL, "/") {
+			(*mirrors)[i].URL = mirror.URL + "/"
+		}
+
+		_, err := url.Parse(mirror.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cos
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// The release notes feed published for each milestone, listing all the milestone
+	// releases with the versions of their major components, kernel included.
+	releaseNotesFeedFormat = "cos-%s-release-notes.xml"
+
+	// The release builds are named after their milestone and build number (e.g. cos-113-18244-85-49).
+	releaseNameRegex = `cos-(?P<milestone>\d+)-(?P<build>\d+-\d+-\d+)`
+	kernelRegex      = `(?i)kernel[^0-9]*(?P<version>\d+\.\d+\.\d+)`
+
+	// The kernel headers are published in the COS tools bucket, for each build.
+	toolsURLFormat   = "https://storage.googleapis.com/%s/%s/kernel-headers.tgz"
+	toolsBucket      = "cos-tools"
+	toolsBucketArm64 = "cos-tools-arm64"
+	X8664Arch        = "x86_64"
+	Arm64Arch        = "arm64"
+)
+
+// The kernel files of the kernel headers, declaring the compiler version.
+var kernelFiles = []string{".config", "compile.h"}
+
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{Name: "Google Cloud", URL: "https://cloud.google.com/feeds/"},
+	},
+
+	// COS is image-based, so there are no package repositories.
+	Repositories: nil,
+	Archs: []packages.Architecture{
+		X8664Arch,
+		Arm64Arch,
+	},
+
+	// Milestones, as the release notes feeds cannot be listed.
+	Versions: []distro.Version{
+		"97",
+		"101",
+		"105",
+		"109",
+		"113",
+		"117",
+		"121",
+	},
+}
@@ -0,0 +1,98 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Cos discovers the kernels shipped with Google Container-Optimized OS images,
+// from the milestone release notes, and the kernel headers published for each build,
+// as COS does not publish kernel packages.
+type Cos struct {
+	config distro.Config
+}
+
+func (c *Cos) Configure(config distro.Config) error {
+	cfg, err := c.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	c.config = cfg
+
+	return nil
+}
+
+// SearchPackages reads the release notes feed of each milestone, from each mirror,
+// for each architecture, and returns a slice of Package and optionally an error.
+// The package URL is the one of the kernel headers of the release build.
+func (c *Cos) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	c.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, c.config.Mirrors, c.config.Output.Logger)
+
+	var result []packages.Package
+
+	builds := map[string]bool{}
+
+	for _, mirror := range mirrors {
+		for _, milestone := range c.config.Versions {
+			feedURL, err := url.JoinPath(mirror.URL, fmt.Sprintf(releaseNotesFeedFormat, milestone))
+			if err != nil {
+				return nil, err
+			}
+
+			options.Log().WithField("url", feedURL).Info("Analysing release notes")
+
+			releases, err := getReleaseNotes(ctx, feedURL)
+			if err != nil {
+				options.Log().WithError(err).WithField("milestone", milestone).Error("error getting release notes")
+
+				continue
+			}
+
+			for _, release := range releases {
+				if builds[release.Build] {
+					continue
+				}
+
+				builds[release.Build] = true
+
+				for _, arch := range c.config.Archs {
+					result = append(result, &Package{
+						Name:      options.PackageName(),
+						Version:   release.KernelVersion,
+						Arch:      string(arch),
+						Milestone: release.Milestone,
+						Build:     release.Build,
+						url:       fmt.Sprintf(toolsURLFormat, toolsBucketFor(arch), release.Build),
+					})
+				}
+			}
+
+			if len(releases) > 0 {
+				options.Log().Infof("New %d packages found", len(releases))
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// toolsBucketFor returns the COS tools bucket where the build artifacts are published for the architecture.
+func toolsBucketFor(arch packages.Architecture) string {
+	if arch == Arm64Arch {
+		return toolsBucketArm64
+	}
+
+	return toolsBucket
+}
@@ -0,0 +1,91 @@
+package cos
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"path"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Package represents the kernel shipped with a COS release image, for a specific architecture.
+type Package struct {
+	Name      string
+	Version   string
+	Release   string
+	Arch      string
+	Milestone string
+	Build     string
+	url       string
+}
+
+func (p *Package) GetName() string {
+	return p.Name
+}
+
+func (p *Package) GetVersion() string {
+	return p.Version
+}
+
+func (p *Package) GetRelease() string {
+	return p.Release
+}
+
+func (p *Package) GetArch() string {
+	return p.Arch
+}
+
+func (p *Package) GetLocation() string {
+	return p.url
+}
+
+func (p *Package) URL() string {
+	return p.url
+}
+
+// Files downloads the kernel headers published for the release build, and visits the kernel
+// configuration and the compile header shipped with them, if any.
+func (p *Package) Files(ctx context.Context, visit packages.FileVisitor) error {
+	body, err := fetch.Get(ctx, p.url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	gzr, err := gzip.NewReader(body)
+	if err != nil {
+		return errors.Wrap(err, "error decompressing kernel headers")
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+
+	for {
+		var header *tar.Header
+
+		header, err = tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg || !slices.Contains(kernelFiles, path.Base(header.Name)) {
+			continue
+		}
+
+		var skip bool
+
+		if skip, err = packages.VisitFile(visit, header.Name, tr); skip {
+			return err
+		}
+	}
+}
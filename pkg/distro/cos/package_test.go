@@ -0,0 +1,65 @@
+package cos
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// newTarGz returns a gzip-compressed tarball of the files, in order.
+func newTarGz(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	for _, f := range files {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: f[0], Mode: 0o644, Size: int64(len(f[1])), Typeflag: tar.TypeReg}))
+
+		_, err := tw.Write([]byte(f[1]))
+		assert.NilError(t, err)
+	}
+
+	assert.NilError(t, tw.Close())
+	assert.NilError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
+func TestPackageFiles(t *testing.T) {
+	t.Parallel()
+
+	headers := newTarGz(t,
+		[2]string{"usr/src/linux-headers-6.1.75+/Makefile", "VERSION = 6\n"},
+		[2]string{"usr/src/linux-headers-6.1.75+/.config", "CONFIG_GCC_VERSION=120200\n"},
+		[2]string{"usr/src/linux-headers-6.1.75+/include/generated/compile.h", "#define LINUX_COMPILER \"gcc 12.2.0\"\n"},
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(headers)
+	}))
+	t.Cleanup(server.Close)
+
+	files := map[string]string{}
+	visit := func(name string, r io.Reader) error {
+		content, err := io.ReadAll(r)
+		files[name] = string(content)
+
+		return err
+	}
+
+	assert.NilError(t, (&Package{url: server.URL + "/18244.1.6/kernel-headers.tgz"}).Files(context.Background(), visit))
+	assert.DeepEqual(t, files, map[string]string{
+		"usr/src/linux-headers-6.1.75+/.config":                     "CONFIG_GCC_VERSION=120200\n",
+		"usr/src/linux-headers-6.1.75+/include/generated/compile.h": "#define LINUX_COMPILER \"gcc 12.2.0\"\n",
+	})
+}
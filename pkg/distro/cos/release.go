@@ -0,0 +1,98 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+var (
+	releaseNamePattern = regexp.MustCompile(releaseNameRegex)
+	kernelPattern      = regexp.MustCompile(kernelRegex)
+	htmlTagPattern     = regexp.MustCompile(`<[^>]+>`)
+)
+
+// Release is a COS release build, as announced in the milestone release notes.
+type Release struct {
+	Milestone     string
+	Build         string
+	KernelVersion string
+}
+
+// feed is the Atom feed of the milestone release notes.
+// Each entry is dated, and announces one or more release builds.
+type feed struct {
+	Entries []struct {
+		Title   string `xml:"title"`
+		Content string `xml:"content"`
+	} `xml:"entry"`
+}
+
+// getReleaseNotes returns the releases announced in the release notes feed, indexed by build.
+func getReleaseNotes(ctx context.Context, feedURL string) (map[string]Release, error) {
+	body, err := fetch.Get(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return parseReleaseNotes(body)
+}
+
+// parseReleaseNotes parses the releases from an Atom release notes feed.
+// The kernel version of each release is the first one following the release name,
+// within the entry text, as the release notes are free text: the releases whose notes
+// don't mention the kernel (e.g. "Fixed a bug.") are not returned.
+func parseReleaseNotes(r io.Reader) (map[string]Release, error) {
+	f := &feed{}
+
+	if err := xml.NewDecoder(r).Decode(f); err != nil {
+		return nil, errors.Wrap(err, "error decoding release notes feed")
+	}
+
+	releases := map[string]Release{}
+
+	for _, entry := range f.Entries {
+		text := htmlTagPattern.ReplaceAllString(entry.Content, " ")
+
+		indexes := releaseNamePattern.FindAllStringSubmatchIndex(text, -1)
+
+		for i, idx := range indexes {
+			end := len(text)
+			if i+1 < len(indexes) {
+				end = indexes[i+1][0]
+			}
+
+			m, b := 2*releaseNamePattern.SubexpIndex("milestone"), 2*releaseNamePattern.SubexpIndex("build")
+			milestone := text[idx[m]:idx[m+1]]
+			build := strings.ReplaceAll(text[idx[b]:idx[b+1]], "-", ".")
+
+			if v, ok := releases[build]; ok && v.KernelVersion != "" {
+				continue
+			}
+
+			release := Release{Milestone: milestone, Build: build}
+
+			match := kernelPattern.FindStringSubmatch(text[idx[1]:end])
+			if match != nil {
+				release.KernelVersion = match[kernelPattern.SubexpIndex("version")]
+			}
+
+			releases[build] = release
+		}
+	}
+
+	for k, v := range releases {
+		if v.KernelVersion == "" {
+			delete(releases, k)
+		}
+	}
+
+	return releases, nil
+}
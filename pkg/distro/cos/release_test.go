@@ -0,0 +1,92 @@
+package cos
+
+import (
+	"html"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const testReleaseNotes = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>cos-113 - Release notes</title>
+  <entry>
+    <title>March 26, 2024</title>
+    <content type="html">&lt;h3&gt;cos-113-18244-1-6&lt;/h3&gt;
+&lt;table&gt;&lt;tr&gt;&lt;th&gt;Kernel&lt;/th&gt;&lt;th&gt;Docker&lt;/th&gt;&lt;/tr&gt;
+&lt;tr&gt;&lt;td&gt;COS-6.1.75&lt;/td&gt;&lt;td&gt;24.0.9&lt;/td&gt;&lt;/tr&gt;&lt;/table&gt;
+&lt;h3&gt;cos-113-18244-1-3&lt;/h3&gt;
+&lt;p&gt;Fixed a bug.&lt;/p&gt;</content>
+  </entry>
+  <entry>
+    <title>April 9, 2024</title>
+    <content type="html">&lt;h3&gt;cos-113-18244-1-15&lt;/h3&gt;
+&lt;p&gt;Upgraded the kernel to v6.1.79.&lt;/p&gt;</content>
+  </entry>
+</feed>`
+
+func TestParseReleaseNotes(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseReleaseNotes(strings.NewReader(testReleaseNotes))
+
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]Release{
+		"18244.1.6":  {Milestone: "113", Build: "18244.1.6", KernelVersion: "6.1.75"},
+		"18244.1.15": {Milestone: "113", Build: "18244.1.15", KernelVersion: "6.1.79"},
+	}, got)
+}
+
+// testFeed returns a release notes feed, with an entry for each content.
+func testFeed(contents ...string) string {
+	feed := `<?xml version="1.0" encoding="UTF-8"?><feed xmlns="http://www.w3.org/2005/Atom">`
+
+	for _, content := range contents {
+		feed += `<entry><title>March 26, 2024</title><content type="html">` + html.EscapeString(content) + `</content></entry>`
+	}
+
+	return feed + `</feed>`
+}
+
+func TestParseReleaseNotesKernelVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		feed string
+		want map[string]Release
+	}{
+		"kernel not mentioned": {
+			feed: testFeed(`<h3>cos-113-18244-1-3</h3><p>Fixed a bug.</p>`),
+			want: map[string]Release{},
+		},
+		"kernel version without the kernel name": {
+			feed: testFeed(`<h3>cos-113-18244-1-3</h3><p>Updated Linux to 6.1.85.</p>`),
+			want: map[string]Release{},
+		},
+		"kernel of the next release": {
+			feed: testFeed(`<h3>cos-113-18244-1-3</h3><p>Fixed a bug.</p><h3>cos-113-18244-1-6</h3><p>Upgraded the kernel to v6.1.79.</p>`),
+			want: map[string]Release{
+				"18244.1.6": {Milestone: "113", Build: "18244.1.6", KernelVersion: "6.1.79"},
+			},
+		},
+		"kernel in a later entry": {
+			feed: testFeed(`<h3>cos-113-18244-1-3</h3><p>Fixed a bug.</p>`, `<h3>cos-113-18244-1-3</h3><p>Kernel: COS-6.1.75</p>`),
+			want: map[string]Release{
+				"18244.1.3": {Milestone: "113", Build: "18244.1.3", KernelVersion: "6.1.75"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseReleaseNotes(strings.NewReader(tt.feed))
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tt.want, got)
+		})
+	}
+}
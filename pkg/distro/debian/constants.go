@@ -9,6 +9,10 @@ const (
 	DebianMirrorsDistroVersionRegex                       = `^.+$`
 	DefaultArch                                           = X8664Arch
 	X8664Arch                       packages.Architecture = "amd64"
+
+	// FlatVersion is the version of flat repositories, which have no dists,
+	// as in APT sources (e.g. deb https://example.com/debian ./).
+	FlatVersion distro.Version = "./"
 )
 
 var DefaultConfig = distro.Config{
@@ -1,6 +1,7 @@
 package debian
 
 import (
+	"context"
 	"net/url"
 	"path"
 	"strings"
@@ -29,12 +30,14 @@ func (d *Debian) Configure(config distro.Config) error {
 
 // GetPackages scrapes each mirror, for each distro version, for each repository,
 // for each architecture, and returns slice of Package and optionally an error.
-func (d *Debian) SearchPackages(options packages.SearchOptions) ([]packages.Package, error) {
+func (d *Debian) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
 	d.Config.Output.Logger = options.Log()
 
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, d.Config.Mirrors, d.Config.Output.Logger)
+
 	// Build distribution version-specific seed URLs.
-	// TODO: introduce support for Release index files, where InRelease does not exist.
-	distURLs, err := d.buildReleaseIndexURLs(d.Config.Mirrors, d.Config.Versions)
+	distURLs, err := d.buildReleaseIndexURLs(mirrors, d.Config.Versions)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +49,7 @@ func (d *Debian) SearchPackages(options packages.SearchOptions) ([]packages.Pack
 
 	searchOptions := deb.NewSearchOptions(&options, d.Config.Archs, distURLs, components)
 
-	debs, err := deb.SearchPackages(searchOptions)
+	debs, err := deb.SearchPackages(ctx, searchOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +66,13 @@ func (d *Debian) buildReleaseIndexURLs(mirrors []packages.Mirror, versions []dis
 
 		for _, mirror := range mirrors {
 			for _, version := range versions {
+				// Flat repositories have no dists, the mirror being the repository itself.
+				if version == FlatVersion {
+					versionRoots = append(versionRoots, mirror.URL)
+
+					continue
+				}
+
 				v, err := url.JoinPath(mirror.URL, "dists", string(version))
 				if err != nil {
 					return nil, err
@@ -1,6 +1,9 @@
 package distro
 
 import (
+	"context"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
 	"github.com/maxgio92/krawler/pkg/output"
 	"github.com/maxgio92/krawler/pkg/packages"
 	"github.com/maxgio92/krawler/pkg/utils/template"
@@ -19,6 +22,17 @@ type Config struct {
 	// A list of Distro versions.
 	Versions []Version
 
+	// A list of OpenPGP keyring files, either ASCII-armored or binary, to verify
+	// the repository metadata signatures against. Verification is disabled if empty.
+	Keyrings []string
+
+	// Options for the HTTP client, like timeouts, retries and proxy.
+	HTTP fetch.Options `json:"http,omitempty"`
+
+	// The maximum number of repositories and packages analysed at once, each of them
+	// sending requests within the HTTP client limits. Zero is the default.
+	Workers int `json:"workers,omitempty"`
+
 	// Options for visual output.
 	Output output.Options `json:"output,omitempty"`
 }
@@ -30,7 +44,8 @@ type Distro interface {
 
 	// GetPackages should return a slice of Package based on
 	// the provided SearchOptions-type filter.
-	SearchPackages(packages.SearchOptions) ([]packages.Package, error)
+	// The search stops when the context is done.
+	SearchPackages(context.Context, packages.SearchOptions) ([]packages.Package, error)
 }
 
 type Version string
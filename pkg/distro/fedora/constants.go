@@ -28,8 +28,16 @@ const (
 
 var DefaultConfig = distro.Config{
 	Mirrors: []packages.Mirror{
-		{Name: "releases", URL: "https://mirrors.edge.kernel.org/fedora/releases/"},
-		{Name: "updates", URL: "https://mirrors.edge.kernel.org/fedora/updates/"},
+		{
+			Name:       "releases",
+			URL:        "https://mirrors.edge.kernel.org/fedora/releases/",
+			Mirrorlist: "https://mirrors.fedoraproject.org/metalink?repo=fedora-{{ .versions }}&arch={{ .archs }}",
+		},
+		{
+			Name:       "updates",
+			URL:        "https://mirrors.edge.kernel.org/fedora/updates/",
+			Mirrorlist: "https://mirrors.fedoraproject.org/metalink?repo=updates-released-f{{ .versions }}&arch={{ .archs }}",
+		},
 	},
 	Repositories: []packages.Repository{
 		{Name: "releases", URI: packages.URITemplate("/Everything/{{ .archs }}/os/")},
@@ -0,0 +1,46 @@
+package flatcar
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// The release feed published for each channel, listing all the channel releases
+	// with their major software versions, kernel included.
+	releaseFeedFormat = "releases-%s.json"
+
+	// The version file published under each board's release folder.
+	versionFile        = "version.txt"
+	versionFileKey     = "FLATCAR_VERSION"
+	kernelConfigFile   = "flatcar_production_image_kernel_config.txt"
+	kernelSoftware     = "kernel"
+	boardSuffix        = "-usr"
+	kernelLocalVersion = "flatcar"
+
+	// CurrentVersion is the alias of the latest release of a channel.
+	CurrentVersion distro.Version = "current"
+)
+
+var DefaultConfig = distro.Config{
+	// Each release channel is published on a dedicated mirror, named as the channel.
+	Mirrors: []packages.Mirror{
+		{Name: "stable", URL: "https://stable.release.flatcar-linux.net/"},
+		{Name: "beta", URL: "https://beta.release.flatcar-linux.net/"},
+		{Name: "alpha", URL: "https://alpha.release.flatcar-linux.net/"},
+		{Name: "lts", URL: "https://lts.release.flatcar-linux.net/"},
+	},
+
+	// Flatcar is image-based, so there are no package repositories: the repositories are
+	// the locations of the release feeds, either absolute or relative to the channel mirrors.
+	Repositories: []packages.Repository{
+		{Name: "releases", URI: packages.URITemplate("https://www.flatcar.org/releases-json/")},
+	},
+	Archs: []packages.Architecture{
+		"amd64",
+		"arm64",
+	},
+
+	// All the releases of the release feeds by default.
+	Versions: nil,
+}
@@ -0,0 +1,5 @@
+package flatcar
+
+import "errors"
+
+var ErrVersionNotFound = errors.New("version not found in the version file")
@@ -0,0 +1,217 @@
+package flatcar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Flatcar discovers the kernels shipped with Flatcar Container Linux images,
+// from the channel release feeds, as Flatcar does not publish kernel packages.
+type Flatcar struct {
+	config distro.Config
+}
+
+func (f *Flatcar) Configure(config distro.Config) error {
+	cfg, err := f.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	f.config = cfg
+
+	return nil
+}
+
+// SearchPackages reads the release feed of each channel mirror, for each distro version,
+// for each architecture, and returns a slice of Package and optionally an error.
+func (f *Flatcar) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	f.config.Output.Logger = options.Log()
+
+	kernels, err := f.buildKernelPackages(ctx, options.PackageName())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// The kernel configuration of each release image is downloaded when the package files are visited.
+	result := make([]packages.Package, 0, len(kernels))
+	for _, v := range kernels {
+		result = append(result, v)
+	}
+
+	f.config.Output.Logger.Infof("New %d packages found", len(result))
+
+	return result, nil
+}
+
+// buildKernelPackages returns a Package for each release image kernel,
+// for each channel, for each version, for each architecture.
+func (f *Flatcar) buildKernelPackages(ctx context.Context, packageName string) ([]*Package, error) {
+	var kernels []*Package
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, f.config.Mirrors, f.config.Output.Logger)
+
+	for _, mirror := range mirrors {
+		channel := channelFromMirror(mirror)
+
+		releases, err := f.getChannelReleaseFeed(ctx, mirror, channel)
+		if err != nil {
+			f.config.Output.Logger.WithError(err).WithField("channel", channel).Error("error getting release feed")
+
+			continue
+		}
+
+		versions, err := f.buildVersions(ctx, mirror, releases)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range versions {
+			release, ok := releases[version]
+			if !ok {
+				f.config.Output.Logger.WithField("version", version).Warn("release not found in release feed")
+
+				continue
+			}
+
+			if release.KernelVersion() == "" {
+				continue
+			}
+
+			for _, arch := range f.config.Archs {
+				if !slices.Contains(release.Architectures, string(arch)) {
+					continue
+				}
+
+				u, err := url.JoinPath(mirror.URL, string(arch)+boardSuffix, version, kernelConfigFile)
+				if err != nil {
+					return nil, err
+				}
+
+				kernels = append(kernels, &Package{
+					Name:           packageName,
+					Version:        release.KernelVersion(),
+					Release:        kernelLocalVersion,
+					Arch:           string(arch),
+					FlatcarRelease: version,
+					url:            u,
+				})
+			}
+		}
+	}
+
+	return kernels, nil
+}
+
+// getChannelReleaseFeed returns the releases of the channel, from the release feed of the first
+// repository publishing it. The repositories relative to the mirror are resolved against its URL.
+func (f *Flatcar) getChannelReleaseFeed(ctx context.Context, mirror packages.Mirror, channel string) (map[string]Release, error) {
+	var err error
+
+	for _, repository := range f.config.Repositories {
+		var feedURL string
+
+		feedURL, err = releaseFeedURL(mirror.URL, string(repository.URI), channel)
+		if err != nil {
+			return nil, err
+		}
+
+		var releases map[string]Release
+
+		if releases, err = getReleaseFeed(ctx, feedURL); err == nil {
+			return releases, nil
+		}
+
+		f.config.Output.Logger.WithError(err).WithField("url", feedURL).Debug("error getting release feed")
+	}
+
+	return nil, err
+}
+
+// buildVersions returns the list of distro versions, considering the user-provided configuration,
+// and if not, all the ones available on the release feed.
+// The current version alias is resolved through the channel version file.
+func (f *Flatcar) buildVersions(ctx context.Context, mirror packages.Mirror, releases map[string]Release) ([]string, error) {
+	versions := []string{}
+
+	if f.config.Versions == nil {
+		for k := range releases {
+			versions = append(versions, k)
+		}
+
+		return versions, nil
+	}
+
+	for _, v := range f.config.Versions {
+		if v != CurrentVersion {
+			versions = append(versions, string(v))
+
+			continue
+		}
+
+		for _, arch := range f.config.Archs {
+			u, err := url.JoinPath(mirror.URL, string(arch)+boardSuffix, string(CurrentVersion), versionFile)
+			if err != nil {
+				return nil, err
+			}
+
+			current, err := getVersionFromVersionFile(ctx, u)
+			if err != nil {
+				f.config.Output.Logger.WithError(err).Error("error getting current version")
+
+				continue
+			}
+
+			if !slices.Contains(versions, current) {
+				versions = append(versions, current)
+			}
+		}
+	}
+
+	return versions, nil
+}
+
+// releaseFeedURL returns the URL of the release feed of the channel, in the repository,
+// either absolute or relative to the mirror URL.
+// E.g. https://www.flatcar.org/releases-json/releases-stable.json.
+func releaseFeedURL(mirrorURL, repositoryURI, channel string) (string, error) {
+	base, err := url.Parse(mirrorURL)
+	if err != nil {
+		return "", err
+	}
+
+	repository, err := url.Parse(repositoryURI)
+	if err != nil {
+		return "", err
+	}
+
+	return url.JoinPath(base.ResolveReference(repository).String(), fmt.Sprintf(releaseFeedFormat, channel))
+}
+
+// channelFromMirror returns the release channel of the mirror, from its name or,
+// if not specified, from the first label of its host name (e.g. stable.release.flatcar-linux.net).
+func channelFromMirror(mirror packages.Mirror) string {
+	if mirror.Name != "" {
+		return mirror.Name
+	}
+
+	u, err := url.Parse(mirror.URL)
+	if err != nil {
+		return ""
+	}
+
+	channel, _, _ := strings.Cut(u.Hostname(), ".")
+
+	return channel
+}
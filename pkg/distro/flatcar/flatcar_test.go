@@ -0,0 +1,238 @@
+package flatcar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const testReleaseFeed = `{
+  "current": {
+    "channel": "stable",
+    "architectures": ["amd64", "arm64"],
+    "release_date": "2024-02-27 18:05:24 +0000",
+    "major_software": {"kernel": ["6.1.77"], "docker": ["24.0.9"]}
+  },
+  "3815.2.0": {
+    "channel": "stable",
+    "architectures": ["amd64", "arm64"],
+    "release_date": "2024-02-27 18:05:24 +0000",
+    "major_software": {"kernel": ["6.1.77"], "docker": ["24.0.9"]}
+  },
+  "3760.2.0": {
+    "channel": "stable",
+    "architectures": ["amd64"],
+    "release_date": "2024-01-16 15:18:44 +0000",
+    "major_software": {"kernel": ["6.1.73"]}
+  },
+  "2345.3.0": {
+    "channel": "stable",
+    "architectures": ["amd64"],
+    "release_date": "2020-03-16 10:00:00 +0000",
+    "major_software": {}
+  }
+}`
+
+const testVersionFile = `FLATCAR_BUILD=3815
+FLATCAR_BRANCH=2
+FLATCAR_PATCH=0
+FLATCAR_VERSION=3815.2.0
+FLATCAR_VERSION_ID=3815.2.0
+FLATCAR_BUILD_ID="2024-02-27-1805"
+FLATCAR_SDK_VERSION=3815.1.0
+`
+
+const testKernelConfig = "#\n# Automatically generated file; DO NOT EDIT.\n# Linux/x86 6.1.77 Kernel Configuration\n#\nCONFIG_CC_VERSION_TEXT=\"x86_64-cros-linux-gnu-gcc (Gentoo Hardened 13.2.1_p20240113 p12) 13.2.1 20240113\"\nCONFIG_GCC_VERSION=130201\n"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	files := map[string]string{
+		"/stable/releases-json/releases-stable.json":                            testReleaseFeed,
+		"/stable/amd64-usr/current/version.txt":                                 testVersionFile,
+		"/stable/arm64-usr/current/version.txt":                                 "FLATCAR_BUILD=3815\n",
+		"/stable/amd64-usr/3815.2.0/flatcar_production_image_kernel_config.txt": testKernelConfig,
+		"/stable/amd64-usr/3760.2.0/flatcar_production_image_kernel_config.txt": testKernelConfig,
+		"/stable/arm64-usr/3815.2.0/flatcar_production_image_kernel_config.txt": testKernelConfig,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestReleaseFeedURL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mirrorURL     string
+		repositoryURI string
+		want          string
+	}{
+		"absolute repository": {
+			mirrorURL:     "https://stable.release.flatcar-linux.net/",
+			repositoryURI: "https://www.flatcar.org/releases-json/",
+			want:          "https://www.flatcar.org/releases-json/releases-stable.json",
+		},
+		"repository relative to the mirror": {
+			mirrorURL:     "https://mirror.example.com/flatcar/stable/",
+			repositoryURI: "releases-json/",
+			want:          "https://mirror.example.com/flatcar/stable/releases-json/releases-stable.json",
+		},
+		"repository relative to the mirror host": {
+			mirrorURL:     "https://mirror.example.com/flatcar/stable/",
+			repositoryURI: "/feeds",
+			want:          "https://mirror.example.com/feeds/releases-stable.json",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := releaseFeedURL(tt.mirrorURL, tt.repositoryURI, "stable")
+
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestGetReleaseFeed(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	releases, err := getReleaseFeed(context.Background(), server.URL+"/stable/releases-json/releases-stable.json")
+	assert.NilError(t, err)
+
+	// The current alias is not a release.
+	assert.Equal(t, len(releases), 3)
+
+	release := releases["3815.2.0"]
+	assert.Equal(t, release.KernelVersion(), "6.1.77")
+	assert.DeepEqual(t, release.Architectures, []string{"amd64", "arm64"})
+
+	release = releases["2345.3.0"]
+	assert.Equal(t, release.KernelVersion(), "")
+
+	_, err = getReleaseFeed(context.Background(), server.URL+"/missing.json")
+	assert.ErrorContains(t, err, "unexpected HTTP status code")
+}
+
+func TestGetVersionFromVersionFile(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	version, err := getVersionFromVersionFile(context.Background(), server.URL+"/stable/amd64-usr/current/version.txt")
+	assert.NilError(t, err)
+	assert.Equal(t, version, "3815.2.0")
+
+	_, err = getVersionFromVersionFile(context.Background(), server.URL+"/stable/arm64-usr/current/version.txt")
+	assert.Assert(t, errors.Is(err, ErrVersionNotFound))
+}
+
+func TestSearchPackages(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	tests := map[string]struct {
+		versions []distro.Version
+		archs    []packages.Architecture
+		want     map[string]string
+	}{
+		"all the releases": {
+			archs: []packages.Architecture{"amd64", "arm64"},
+			want: map[string]string{
+				server.URL + "/stable/amd64-usr/3815.2.0/flatcar_production_image_kernel_config.txt": "6.1.77",
+				server.URL + "/stable/arm64-usr/3815.2.0/flatcar_production_image_kernel_config.txt": "6.1.77",
+				server.URL + "/stable/amd64-usr/3760.2.0/flatcar_production_image_kernel_config.txt": "6.1.73",
+			},
+		},
+		"current release": {
+			versions: []distro.Version{CurrentVersion},
+			archs:    []packages.Architecture{"amd64"},
+			want: map[string]string{
+				server.URL + "/stable/amd64-usr/3815.2.0/flatcar_production_image_kernel_config.txt": "6.1.77",
+			},
+		},
+		"release not in the feed": {
+			versions: []distro.Version{"1000.0.0"},
+			archs:    []packages.Architecture{"amd64"},
+			want:     map[string]string{},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &Flatcar{}
+			assert.NilError(t, f.Configure(distro.Config{
+				Mirrors: []packages.Mirror{{Name: "stable", URL: server.URL + "/stable"}},
+				// The feed is looked for in the repositories in order.
+				Repositories: []packages.Repository{{URI: "/feeds/"}, {URI: "releases-json/"}},
+				Archs:        tt.archs,
+				Versions:     tt.versions,
+			}))
+
+			result, err := f.SearchPackages(context.Background(), *packages.NewSearchOptions("kernel", tt.archs, nil, 0, ""))
+			assert.NilError(t, err)
+
+			got := map[string]string{}
+
+			for _, p := range result {
+				assert.Equal(t, p.GetName(), "kernel")
+				assert.Equal(t, p.GetRelease(), kernelLocalVersion)
+
+				got[p.URL()] = p.GetVersion()
+			}
+
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestPackageFiles(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	p := &Package{url: server.URL + "/stable/amd64-usr/3815.2.0/flatcar_production_image_kernel_config.txt"}
+
+	files := map[string]string{}
+
+	err := p.Files(context.Background(), func(name string, r io.Reader) error {
+		content, err := io.ReadAll(r)
+		files[name] = string(content)
+
+		return err
+	})
+
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, map[string]string{kernelConfigFile: testKernelConfig})
+}
@@ -0,0 +1,56 @@
+package flatcar
+
+import (
+	"context"
+	"path"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Package represents the kernel shipped with a Flatcar release image, for a specific board.
+type Package struct {
+	Name           string
+	Version        string
+	Release        string
+	Arch           string
+	FlatcarRelease string
+	url            string
+}
+
+func (p *Package) GetName() string {
+	return p.Name
+}
+
+func (p *Package) GetVersion() string {
+	return p.Version
+}
+
+func (p *Package) GetRelease() string {
+	return p.Release
+}
+
+func (p *Package) GetArch() string {
+	return p.Arch
+}
+
+func (p *Package) GetLocation() string {
+	return p.url
+}
+
+func (p *Package) URL() string {
+	return p.url
+}
+
+// Files downloads the kernel configuration of the release image, and visits it.
+func (p *Package) Files(ctx context.Context, visit packages.FileVisitor) error {
+	body, err := fetch.Get(ctx, p.url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = packages.VisitFile(visit, path.Base(p.url), body)
+
+	return err
+}
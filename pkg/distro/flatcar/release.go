@@ -0,0 +1,76 @@
+package flatcar
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+// Release is an entry of the channel release feed.
+type Release struct {
+	Channel       string              `json:"channel"`
+	Architectures []string            `json:"architectures"`
+	ReleaseDate   string              `json:"release_date"`
+	MajorSoftware map[string][]string `json:"major_software"`
+}
+
+// KernelVersion returns the version of the kernel shipped with the release, if any.
+func (r *Release) KernelVersion() string {
+	versions := r.MajorSoftware[kernelSoftware]
+	if len(versions) < 1 {
+		return ""
+	}
+
+	return versions[0]
+}
+
+// getReleaseFeed returns the releases of the feed, indexed by release version.
+func getReleaseFeed(ctx context.Context, feedURL string) (map[string]Release, error) {
+	body, err := fetch.Get(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	releases := map[string]Release{}
+
+	if err = json.NewDecoder(body).Decode(&releases); err != nil {
+		return nil, errors.Wrap(err, "error decoding release feed")
+	}
+
+	// The feed aliases the latest release as current.
+	delete(releases, string(CurrentVersion))
+
+	return releases, nil
+}
+
+// getVersionFromVersionFile returns the release version from a version.txt file,
+// which is a list of shell variable assignments.
+func getVersionFromVersionFile(ctx context.Context, versionFileURL string) (string, error) {
+	body, err := fetch.Get(ctx, versionFileURL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Split(bufio.ScanLines)
+
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if found && key == versionFileKey {
+			return strings.Trim(value, `"`), nil
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", errors.Wrap(ErrVersionNotFound, versionFileURL)
+}
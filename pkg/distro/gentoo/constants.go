@@ -0,0 +1,47 @@
+package gentoo
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// The portage tree snapshot, published under the snapshots folder of the mirrors.
+	snapshotFile    = "portage-latest.tar.xz"
+	snapshotArchive = ".xz"
+
+	// The ebuild metadata cache, where each file is named after the ebuild (e.g. gentoo-sources-6.1.55-r1).
+	md5CacheDir = "metadata/md5-cache/sys-kernel"
+	keywordsKey = "KEYWORDS"
+
+	ebuildURLFormat = "https://gitweb.gentoo.org/repo/gentoo.git/tree/sys-kernel/%s/%s.ebuild"
+)
+
+var (
+	DefaultConfig = distro.Config{
+		Mirrors: []packages.Mirror{
+			{Name: "distfiles", URL: "https://distfiles.gentoo.org/snapshots/"},
+		},
+
+		// Gentoo ebuilds are read from the portage tree snapshot, so there are no package repositories.
+		Repositories: nil,
+		Archs: []packages.Architecture{
+			"amd64",
+			"arm64",
+		},
+
+		// Versions are kernel branches (e.g. 6.1), all by default.
+		Versions: nil,
+	}
+
+	// Gentoo ships prebuilt distribution kernels, besides the patched kernel sources.
+	additionalKernelPackages = []string{
+		"gentoo-kernel-bin",
+	}
+
+	// The local version appended by each kernel package to the kernel release.
+	localVersions = map[string]string{
+		"gentoo-sources":    "gentoo",
+		"gentoo-kernel-bin": "gentoo-dist",
+	}
+)
@@ -0,0 +1,139 @@
+package gentoo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Gentoo discovers the kernels packaged by Gentoo, from the ebuild metadata of the
+// portage tree snapshots, as Gentoo publishes source-based packages.
+type Gentoo struct {
+	config distro.Config
+}
+
+func (g *Gentoo) Configure(config distro.Config) error {
+	cfg, err := g.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	g.config = cfg
+
+	return nil
+}
+
+// SearchPackages reads the portage tree snapshot of each mirror, and returns a slice
+// of Package for each kernel ebuild, for each keyworded architecture, and optionally an error.
+func (g *Gentoo) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	g.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, g.config.Mirrors, g.config.Output.Logger)
+
+	packageNames := []string{options.PackageName()}
+	packageNames = append(packageNames, additionalKernelPackages...)
+
+	var result []packages.Package
+
+	for _, mirror := range mirrors {
+		snapshotURL, err := url.JoinPath(mirror.URL, snapshotFile)
+		if err != nil {
+			return nil, err
+		}
+
+		options.Log().WithField("url", snapshotURL).Info("Analysing portage tree snapshot")
+
+		ebuilds, err := getEbuilds(ctx, snapshotURL, packageNames)
+		if err != nil {
+			options.Log().WithError(err).WithField("url", snapshotURL).Error("error reading snapshot")
+
+			continue
+		}
+
+		ps := g.buildPackages(ebuilds)
+		if len(ps) > 0 {
+			result = append(result, ps...)
+			options.Log().Infof("New %d packages found", len(ps))
+		}
+
+		// Mirrors publish the same snapshot.
+		if len(result) > 0 {
+			break
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// buildPackages returns a Package for each ebuild of the configured kernel branches,
+// for each configured architecture the ebuild is keyworded for.
+func (g *Gentoo) buildPackages(ebuilds []ebuild) []packages.Package {
+	var result []packages.Package
+
+	for _, e := range ebuilds {
+		if !matchesVersions(e.version, g.config.Versions) {
+			continue
+		}
+
+		ebuildName := e.name + "-" + e.version
+		if e.revision != "" {
+			ebuildName += "-" + e.revision
+		}
+
+		for _, arch := range g.config.Archs {
+			if !slices.Contains(e.keywords, string(arch)) {
+				continue
+			}
+
+			result = append(result, &Package{
+				Name:     e.name,
+				Version:  e.version,
+				Release:  localVersions[e.name],
+				Arch:     string(arch),
+				Revision: e.revision,
+				url:      fmt.Sprintf(ebuildURLFormat, e.name, ebuildName),
+			})
+		}
+	}
+
+	return result
+}
+
+// matchesVersions returns whether the kernel version belongs to one of the kernel branches,
+// or true if no branch is specified.
+func matchesVersions(version string, versions []distro.Version) bool {
+	if len(versions) < 1 {
+		return true
+	}
+
+	for _, v := range versions {
+		branch := strings.TrimSuffix(string(v), "/")
+		if version == branch || strings.HasPrefix(version, branch+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getEbuilds(ctx context.Context, snapshotURL string, packageNames []string) ([]ebuild, error) {
+	body, err := fetch.Get(ctx, snapshotURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return readSnapshot(body, packageNames)
+}
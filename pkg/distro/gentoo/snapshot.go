@@ -0,0 +1,118 @@
+package gentoo
+
+import (
+	"archive/tar"
+	"bufio"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"pault.ag/go/debian/deb"
+)
+
+// ebuild is the metadata of a kernel ebuild, as of the portage tree metadata cache.
+type ebuild struct {
+	name     string
+	version  string
+	revision string
+	keywords []string
+}
+
+// readSnapshot returns the ebuilds of the specified packages from the xz-compressed
+// portage tree snapshot.
+func readSnapshot(r io.Reader, packageNames []string) ([]ebuild, error) {
+	xzr, err := deb.DecompressorFor(snapshotArchive)(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decompressing snapshot")
+	}
+	defer xzr.Close()
+
+	return readMetadataCache(tar.NewReader(xzr), packageNames)
+}
+
+// readMetadataCache returns the ebuilds of the specified packages from the metadata cache
+// of a portage tree archive.
+func readMetadataCache(tr *tar.Reader, packageNames []string) ([]ebuild, error) {
+	var ebuilds []ebuild
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(path.Dir(header.Name), md5CacheDir) {
+			continue
+		}
+
+		e, ok := parseEbuildName(path.Base(header.Name), packageNames)
+		if !ok {
+			continue
+		}
+
+		e.keywords, err = readKeywords(tr)
+		if err != nil {
+			return nil, errors.Wrap(err, header.Name)
+		}
+
+		ebuilds = append(ebuilds, e)
+	}
+
+	return ebuilds, nil
+}
+
+// parseEbuildName returns the ebuild from its name, if it belongs to one of the specified packages.
+// E.g. gentoo-sources-6.1.55-r1 is gentoo-sources, version 6.1.55, revision r1.
+func parseEbuildName(name string, packageNames []string) (ebuild, bool) {
+	for _, packageName := range packageNames {
+		v := strings.TrimPrefix(name, packageName+"-")
+		if v == name || v == "" || v[0] < '0' || v[0] > '9' {
+			continue
+		}
+
+		e := ebuild{name: packageName, version: v}
+
+		if i := strings.LastIndex(v, "-r"); i > 0 {
+			e.version, e.revision = v[:i], v[i+1:]
+		}
+
+		return e, true
+	}
+
+	return ebuild{}, false
+}
+
+// readKeywords returns the architecture keywords from a metadata cache file,
+// including the testing ones (e.g. ~arm64 as arm64).
+func readKeywords(r io.Reader) ([]string, error) {
+	var keywords []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found || key != keywordsKey {
+			continue
+		}
+
+		for _, v := range strings.Fields(value) {
+			if strings.HasPrefix(v, "-") {
+				continue
+			}
+
+			keywords = append(keywords, strings.TrimPrefix(v, "~"))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return keywords, nil
+}
@@ -0,0 +1,48 @@
+package gentoo
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gotest.tools/assert"
+)
+
+func TestReadMetadataCache(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"gentoo-20240101/metadata/md5-cache/sys-kernel/gentoo-sources-6.1.55":        "EAPI=8\nKEYWORDS=~alpha amd64 ~arm64 -x86\n",
+		"gentoo-20240101/metadata/md5-cache/sys-kernel/gentoo-kernel-bin-6.6.13-r1":  "KEYWORDS=~amd64 ~arm64\n",
+		"gentoo-20240101/metadata/md5-cache/sys-kernel/gentoo-kernel-6.6.13":         "KEYWORDS=~amd64\n",
+		"gentoo-20240101/metadata/md5-cache/sys-kernel/gentoo-sources-9999":          "KEYWORDS=\n",
+		"gentoo-20240101/sys-kernel/gentoo-sources/gentoo-sources-6.1.55.ebuild":     "",
+		"gentoo-20240101/metadata/md5-cache/sys-kernel/linux-firmware-20240115":      "KEYWORDS=amd64\n",
+		"gentoo-20240101/metadata/md5-cache/sys-kernel/gentoo-sources-headers-6.1.5": "KEYWORDS=amd64\n",
+	}
+
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+
+	ebuilds, err := readMetadataCache(tar.NewReader(&buf), []string{"gentoo-sources", "gentoo-kernel-bin"})
+	assert.NilError(t, err)
+
+	got := map[string]ebuild{}
+	for _, e := range ebuilds {
+		got[e.name+"-"+e.version] = e
+	}
+
+	assert.DeepEqual(t, map[string]ebuild{
+		"gentoo-sources-6.1.55":    {name: "gentoo-sources", version: "6.1.55", keywords: []string{"alpha", "amd64", "arm64"}},
+		"gentoo-sources-9999":      {name: "gentoo-sources", version: "9999"},
+		"gentoo-kernel-bin-6.6.13": {name: "gentoo-kernel-bin", version: "6.6.13", revision: "r1", keywords: []string{"amd64", "arm64"}},
+	}, got, cmp.AllowUnexported(ebuild{}))
+}
@@ -0,0 +1,200 @@
+package distro
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/output"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/utils/template"
+)
+
+// ResolveMirrors returns the first mirror of each group of equivalent mirrors, for the content
+// not to be crawled more than once, and registers the groups for the requests to fail over
+// to the equivalent mirrors.
+// The mirrors listed by the mirrorlist of a mirror are equivalent to it. A mirrorlist that cannot
+// be resolved is logged, and the mirror is crawled anyway. Templated mirrorlists are resolved
+// by ResolveMirrorlists instead, once the versions are known.
+func ResolveMirrors(ctx context.Context, mirrors []packages.Mirror, logger *output.Logger) []packages.Mirror {
+	var (
+		result []packages.Mirror
+		groups = map[string][]string{}
+		keys   []string
+	)
+
+	for _, mirror := range mirrors {
+		// Mirrors without group are a group of their own.
+		key := mirror.Group
+		if key == "" {
+			key = "\x00" + mirror.URL
+		}
+
+		if _, ok := groups[key]; !ok {
+			result = append(result, mirror)
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], mirror.URL)
+
+		if mirror.Mirrorlist == "" || isTemplate(mirror.Mirrorlist) {
+			continue
+		}
+
+		urls, err := packages.ResolveMirrorlist(ctx, mirror.Mirrorlist)
+		if err != nil {
+			if logger != nil {
+				logger.WithField("mirrorlist", mirror.Mirrorlist).WithError(err).Warn("Error resolving mirrorlist")
+			}
+
+			continue
+		}
+
+		for _, u := range urls {
+			if base, ok := equivalentBaseURL(mirror.URL, u); ok {
+				groups[key] = append(groups[key], base)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		fetch.DefaultMirrors().Add(groups[key]...)
+	}
+
+	return result
+}
+
+// ResolveMirrorlists resolves the mirrorlists of the mirrors templated with the versions and the architectures
+// (e.g. https://mirrors.fedoraproject.org/metalink?repo=fedora-{{ .versions }}&arch={{ .archs }}), for each version,
+// and registers the mirrors they list as equivalent to the mirror. For each version, the mirrorlists of
+// the architectures are tried in order, up to the first resolved, and the version is logged if none is.
+func ResolveMirrorlists(ctx context.Context, mirrors []packages.Mirror, versions []Version, archs []packages.Architecture,
+	logger *output.Logger,
+) {
+	archVars := make([]interface{}, 0, len(archs))
+	for _, v := range archs {
+		archVars = append(archVars, string(v))
+	}
+
+	for _, mirror := range mirrors {
+		if !isTemplate(mirror.Mirrorlist) {
+			continue
+		}
+
+		group := []string{mirror.URL}
+
+		for _, version := range versions {
+			v := strings.TrimSuffix(string(version), "/")
+
+			mirrorlists, err := template.MultiplexAndExecute(mirror.Mirrorlist, map[string]interface{}{
+				"versions": []interface{}{v},
+				"archs":    archVars,
+			})
+			if err != nil {
+				if logger != nil {
+					logger.WithField("mirrorlist", mirror.Mirrorlist).WithError(err).Warn("Error building mirrorlist")
+				}
+
+				break
+			}
+
+			urls, err := resolveFirstMirrorlist(ctx, mirrorlists)
+			if err != nil {
+				if logger != nil {
+					logger.WithField("mirrorlist", mirror.Mirrorlist).WithField("version", v).WithError(err).Warn("Error resolving mirrorlist")
+				}
+
+				continue
+			}
+
+			for _, u := range urls {
+				if base, ok := versionBaseURL(u, v); ok {
+					group = append(group, base)
+				}
+			}
+		}
+
+		fetch.DefaultMirrors().Add(group...)
+	}
+}
+
+// resolveFirstMirrorlist returns the URLs listed by the first of the mirrorlists resolved,
+// or the error of the last one.
+func resolveFirstMirrorlist(ctx context.Context, mirrorlists []string) ([]string, error) {
+	var err error
+
+	for _, mirrorlist := range mirrorlists {
+		var urls []string
+
+		if urls, err = packages.ResolveMirrorlist(ctx, mirrorlist); err == nil {
+			return urls, nil
+		}
+	}
+
+	return nil, err
+}
+
+// versionBaseURL returns the base URL of the versions, from a URL listed by the mirrorlist of the version.
+// E.g. for the version 9-stream,
+// https://example.com/centos-stream/9-stream/BaseOS/x86_64/os/repodata/repomd.xml
+// is https://example.com/centos-stream/.
+func versionBaseURL(listedURL string, version string) (string, bool) {
+	u, err := url.Parse(listedURL)
+	if err != nil {
+		return "", false
+	}
+
+	i := strings.Index(u.Path+"/", "/"+version+"/")
+	if i < 0 {
+		return "", false
+	}
+
+	u.Path = u.Path[:i+1]
+	u.RawPath = ""
+	u.RawQuery = ""
+
+	return u.String(), true
+}
+
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// equivalentBaseURL returns the base URL equivalent to the mirror URL, from a URL listed by its mirrorlist.
+// As the listed URLs can point to files under the base URL (e.g. a metalink of repodata/repomd.xml),
+// they're truncated after the last path element of the mirror URL.
+// E.g. for https://mirrors.edge.kernel.org/fedora/releases/,
+// https://example.com/fedora/linux/releases/39/Everything/x86_64/os/repodata/repomd.xml
+// is https://example.com/fedora/linux/releases/.
+func equivalentBaseURL(mirrorURL, listedURL string) (string, bool) {
+	m, err := url.Parse(mirrorURL)
+	if err != nil {
+		return "", false
+	}
+
+	u, err := url.Parse(listedURL)
+	if err != nil {
+		return "", false
+	}
+
+	last := path.Base(strings.TrimSuffix(m.Path, "/"))
+	if last == "/" || last == "." {
+		return u.String(), strings.HasSuffix(u.Path, "/")
+	}
+
+	p := u.Path + "/"
+
+	i := strings.LastIndex(p, "/"+last+"/")
+	if i < 0 {
+		// Only base URLs can be taken as is.
+		return u.String(), strings.HasSuffix(u.Path, "/")
+	}
+
+	u.Path = p[:i+len(last)+2]
+	u.RawPath = ""
+	u.RawQuery = ""
+
+	return u.String(), true
+}
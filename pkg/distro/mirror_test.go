@@ -0,0 +1,150 @@
+package distro
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestEquivalentBaseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mirrorURL string
+		listedURL string
+		want      string
+		ok        bool
+	}{
+		"metalink file": {
+			mirrorURL: "https://mirrors.edge.kernel.org/fedora/releases/",
+			listedURL: "https://ftp.example.de/fedora/linux/releases/39/Everything/x86_64/os/repodata/repomd.xml",
+			want:      "https://ftp.example.de/fedora/linux/releases/",
+			ok:        true,
+		},
+		"base URL": {
+			mirrorURL: "https://mirrors.edge.kernel.org/centos/",
+			listedURL: "http://mirror.example.com/pub/centos",
+			want:      "http://mirror.example.com/pub/centos/",
+			ok:        true,
+		},
+		"unrelated base URL": {
+			mirrorURL: "https://mirrors.edge.kernel.org/centos/",
+			listedURL: "http://mirror.example.com/linux/",
+			want:      "http://mirror.example.com/linux/",
+			ok:        true,
+		},
+		"unrelated file": {
+			mirrorURL: "https://mirrors.edge.kernel.org/centos/",
+			listedURL: "http://mirror.example.com/linux/repomd.xml",
+			ok:        false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := equivalentBaseURL(tt.mirrorURL, tt.listedURL)
+			assert.Equal(t, ok, tt.ok)
+
+			if tt.ok {
+				assert.Equal(t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionBaseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		listedURL string
+		version   string
+		want      string
+		ok        bool
+	}{
+		"metalink file": {
+			listedURL: "https://ftp.example.de/fedora/linux/releases/39/Everything/x86_64/os/repodata/repomd.xml",
+			version:   "39",
+			want:      "https://ftp.example.de/fedora/linux/releases/",
+			ok:        true,
+		},
+		"stream version": {
+			listedURL: "http://mirror.example.com/centos-stream/9-stream/BaseOS/x86_64/os/repodata/repomd.xml",
+			version:   "9-stream",
+			want:      "http://mirror.example.com/centos-stream/",
+			ok:        true,
+		},
+		"version base URL": {
+			listedURL: "http://mirror.example.com/fedora/updates/39",
+			version:   "39",
+			want:      "http://mirror.example.com/fedora/updates/",
+			ok:        true,
+		},
+		"other version": {
+			listedURL: "http://mirror.example.com/fedora/releases/40/Everything/x86_64/os/repodata/repomd.xml",
+			version:   "39",
+			ok:        false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := versionBaseURL(tt.listedURL, tt.version)
+			assert.Equal(t, ok, tt.ok)
+
+			if tt.ok {
+				assert.Equal(t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMirrorlists(t *testing.T) {
+	t.Cleanup(fetch.DefaultMirrors().Reset)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo, arch := r.URL.Query().Get("repo"), r.URL.Query().Get("arch")
+
+		// The metalink of the first architecture is missing.
+		if arch != "x86_64" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Fprintf(w, `<metalink version="3.0" xmlns="http://www.metalinker.org/"><files><file name="repomd.xml"><resources>
+<url protocol="https" type="https">https://mirror-%[1]s.example.com/fedora/linux/releases/%[1]s/Everything/x86_64/os/repodata/repomd.xml</url>
+</resources></file></files></metalink>`, repo[len("fedora-"):])
+	}))
+	t.Cleanup(server.Close)
+
+	mirrors := []packages.Mirror{
+		{URL: "https://mirrors.edge.kernel.org/fedora/releases/", Mirrorlist: server.URL + "/metalink?repo=fedora-{{ .versions }}&arch={{ .archs }}"},
+		{URL: "https://mirrors.edge.kernel.org/fedora/updates/"},
+	}
+
+	ResolveMirrorlists(context.Background(), mirrors, []Version{"39", "40"}, []packages.Architecture{"aarch64", "x86_64"}, nil)
+
+	assert.DeepEqual(t, fetch.DefaultMirrors().Alternatives("https://mirrors.edge.kernel.org/fedora/releases/39/", false), []string{
+		"https://mirrors.edge.kernel.org/fedora/releases/39/",
+		"https://mirror-39.example.com/fedora/linux/releases/39/",
+		"https://mirror-40.example.com/fedora/linux/releases/39/",
+	})
+	assert.DeepEqual(t, fetch.DefaultMirrors().Alternatives("https://mirrors.edge.kernel.org/fedora/updates/39/", false), []string{
+		"https://mirrors.edge.kernel.org/fedora/updates/39/",
+	})
+}
@@ -0,0 +1,40 @@
+package nixos
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// The package index published for each channel, as brotli-compressed JSON.
+	packagesIndexFile = "packages.json.br"
+
+	// The channels listed by the channel servers, as the stable releases (e.g. nixos-24.05) and unstable.
+	channelRegex    = `href="/?(?P<channel>nixos-(\d+\.\d+|unstable))/?"`
+	unstableChannel = "nixos-unstable"
+
+	// The stable channels discovered by default, from the latest, as the supported releases.
+	stableChannels = 2
+
+	// The kernels are the linux derivations, whose attribute is named after the kernel package set
+	// (e.g. linux_6_1 or linuxKernel.kernels.linux_6_1 for linuxPackages_6_1).
+	kernelPname      = "linux"
+	kernelAttrRegex  = `^(linuxKernel\.kernels\.)?linux(?P<suffix>_[0-9a-z_]+)?$`
+	packageSetPrefix = "linuxPackages"
+)
+
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{Name: "channels", URL: "https://channels.nixos.org/"},
+	},
+
+	// NixOS channels publish a package index, so there are no package repositories.
+	Repositories: nil,
+	Archs: []packages.Architecture{
+		"x86_64",
+		"aarch64",
+	},
+
+	// Channels are discovered from the mirrors.
+	Versions: nil,
+}
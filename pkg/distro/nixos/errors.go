@@ -0,0 +1,5 @@
+package nixos
+
+import "errors"
+
+var ErrChannelsNotFound = errors.New("no channels found in the channel list, configure the versions")
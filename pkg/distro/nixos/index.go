@@ -0,0 +1,160 @@
+package nixos
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/andybalholm/brotli"
+	"github.com/pkg/errors"
+)
+
+var (
+	channelPattern    = regexp.MustCompile(channelRegex)
+	kernelAttrPattern = regexp.MustCompile(kernelAttrRegex)
+)
+
+// derivation is an entry of the packages.json.br index.
+type derivation struct {
+	Pname   string `json:"pname"`
+	Version string `json:"version"`
+}
+
+// readPackagesIndex returns the kernel versions indexed by kernel package set name,
+// from the brotli-compressed channel package index.
+func readPackagesIndex(r io.Reader) (map[string]string, error) {
+	return readKernelVersions(brotli.NewReader(r))
+}
+
+// readKernelVersions returns the kernel versions indexed by kernel package set name,
+// from the package index. The index is streamed, as it lists every derivation of nixpkgs.
+//
+//nolint:cyclop
+func readKernelVersions(r io.Reader) (map[string]string, error) {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	kernels := map[string]string{}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading package index")
+		}
+
+		if key != "packages" {
+			if err = dec.Decode(&json.RawMessage{}); err != nil {
+				return nil, errors.Wrap(err, "error reading package index")
+			}
+
+			continue
+		}
+
+		if err = expectDelim(dec, '{'); err != nil {
+			return nil, err
+		}
+
+		for dec.More() {
+			var attr json.Token
+
+			attr, err = dec.Token()
+			if err != nil {
+				return nil, errors.Wrap(err, "error reading package index")
+			}
+
+			name, ok := attr.(string)
+			if !ok {
+				//nolint:goerr113
+				return nil, errors.Errorf("error reading package index: unexpected attribute %v", attr)
+			}
+
+			match := kernelAttrPattern.FindStringSubmatch(name)
+			if match == nil {
+				if err = dec.Decode(&struct{}{}); err != nil {
+					return nil, errors.Wrap(err, name)
+				}
+
+				continue
+			}
+
+			var drv derivation
+			if err = dec.Decode(&drv); err != nil {
+				return nil, errors.Wrap(err, name)
+			}
+
+			if drv.Pname != kernelPname || drv.Version == "" {
+				continue
+			}
+
+			kernels[packageSetPrefix+match[kernelAttrPattern.SubexpIndex("suffix")]] = drv.Version
+		}
+
+		if err = expectDelim(dec, '}'); err != nil {
+			return nil, err
+		}
+	}
+
+	return kernels, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, "error reading package index")
+	}
+
+	if token != delim {
+		//nolint:goerr113
+		return errors.Errorf("error reading package index: got %v, want %v", token, delim)
+	}
+
+	return nil
+}
+
+// readChannels returns the unstable channel and the latest stable channels, up to limit,
+// from the channel list served by a channel server.
+func readChannels(r io.Reader, limit int) ([]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var stable []string
+
+	unstable := false
+	seen := map[string]bool{}
+
+	for _, match := range channelPattern.FindAllSubmatch(content, -1) {
+		channel := string(match[channelPattern.SubexpIndex("channel")])
+		if seen[channel] {
+			continue
+		}
+
+		seen[channel] = true
+
+		if channel == unstableChannel {
+			unstable = true
+
+			continue
+		}
+
+		stable = append(stable, channel)
+	}
+
+	// Stable channels are named after the YY.MM release.
+	sort.Sort(sort.Reverse(sort.StringSlice(stable)))
+
+	if len(stable) > limit {
+		stable = stable[:limit]
+	}
+
+	if unstable {
+		return append([]string{unstableChannel}, stable...), nil
+	}
+
+	return stable, nil
+}
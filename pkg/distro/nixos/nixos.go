@@ -0,0 +1,136 @@
+package nixos
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// NixOS discovers the kernels packaged by NixOS, from the channel package indexes,
+// as NixOS does not publish a conventional package repository.
+type NixOS struct {
+	config distro.Config
+}
+
+func (n *NixOS) Configure(config distro.Config) error {
+	cfg, err := n.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	n.config = cfg
+
+	return nil
+}
+
+// SearchPackages reads the package index of each channel, from each mirror, and returns
+// a slice of Package for each kernel package set, for each architecture, and optionally an error.
+// Kernel package sets are filtered by the search package name prefix (e.g. linuxPackages).
+// If no channels are configured, the unstable and the latest stable channels are discovered from each mirror.
+//
+//nolint:funlen,cyclop
+func (n *NixOS) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	n.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, n.config.Mirrors, n.config.Output.Logger)
+
+	var result []packages.Package
+
+	for _, mirror := range mirrors {
+		channels, err := n.getChannels(ctx, mirror)
+		if err != nil {
+			options.Log().WithError(err).WithField("mirror", mirror.URL).Error("error listing channels")
+
+			continue
+		}
+
+		for _, channel := range channels {
+			indexURL, err := url.JoinPath(mirror.URL, strings.TrimSuffix(channel, "/"), packagesIndexFile)
+			if err != nil {
+				return nil, err
+			}
+
+			options.Log().WithField("url", indexURL).Info("Analysing channel")
+
+			kernels, err := getKernelVersions(ctx, indexURL)
+			if err != nil {
+				options.Log().WithError(err).WithField("channel", channel).Error("error reading channel")
+
+				continue
+			}
+
+			var ps []packages.Package
+
+			for name, version := range kernels {
+				if !strings.HasPrefix(name, options.PackageName()) {
+					continue
+				}
+
+				for _, arch := range n.config.Archs {
+					ps = append(ps, &Package{
+						Name:    name,
+						Version: version,
+						Arch:    string(arch),
+						Channel: channel,
+						url:     indexURL,
+					})
+				}
+			}
+
+			if len(ps) > 0 {
+				result = append(result, ps...)
+				options.Log().Infof("New %d packages found", len(ps))
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getChannels returns the configured channels, or the channels discovered from the mirror.
+func (n *NixOS) getChannels(ctx context.Context, mirror packages.Mirror) ([]string, error) {
+	if len(n.config.Versions) > 0 {
+		channels := make([]string, 0, len(n.config.Versions))
+		for _, v := range n.config.Versions {
+			channels = append(channels, string(v))
+		}
+
+		return channels, nil
+	}
+
+	body, err := fetch.Get(ctx, mirror.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	channels, err := readChannels(body, stableChannels)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(channels) < 1 {
+		return nil, ErrChannelsNotFound
+	}
+
+	return channels, nil
+}
+
+func getKernelVersions(ctx context.Context, indexURL string) (map[string]string, error) {
+	body, err := fetch.Get(ctx, indexURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return readPackagesIndex(body)
+}
@@ -0,0 +1,213 @@
+package nixos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const testPackagesIndex = `{
+  "version": 2,
+  "packages": {
+    "hello": {"name": "hello-2.12.1", "pname": "hello", "version": "2.12.1", "system": "x86_64-linux", "meta": {"platforms": ["x86_64-linux"]}},
+    "linux": {"name": "linux-6.6.37", "pname": "linux", "version": "6.6.37", "system": "x86_64-linux"},
+    "linux_6_1": {"name": "linux-6.1.97", "pname": "linux", "version": "6.1.97", "system": "x86_64-linux"},
+    "linuxKernel.kernels.linux_6_1": {"name": "linux-6.1.97", "pname": "linux", "version": "6.1.97", "system": "x86_64-linux"},
+    "linuxKernel.kernels.linux_testing": {"name": "linux-6.10-rc7", "pname": "linux", "version": "6.10-rc7", "system": "x86_64-linux"},
+    "linuxKernel.kernels.linux_rt_6_1": {"name": "linux-rt-6.1.95-rt34", "pname": "linux-rt", "version": "6.1.95-rt34", "system": "x86_64-linux"},
+    "linuxKernel.packages.linux_6_1.zfs": {"name": "zfs-kernel-2.2.4-6.1.97", "pname": "zfs-kernel", "version": "2.2.4-6.1.97", "system": "x86_64-linux"},
+    "linux-firmware": {"name": "linux-firmware-20240610", "pname": "linux-firmware", "version": "20240610", "system": "x86_64-linux"}
+  }
+}`
+
+const testChannels = `<html><body><table>
+<tr><td><a href="/nixos-23.11">nixos-23.11</a></td></tr>
+<tr><td><a href="/nixos-23.11-small">nixos-23.11-small</a></td></tr>
+<tr><td><a href="/nixos-24.05">nixos-24.05</a></td></tr>
+<tr><td><a href="/nixos-24.11">nixos-24.11</a></td></tr>
+<tr><td><a href="/nixos-unstable">nixos-unstable</a></td></tr>
+<tr><td><a href="/nixpkgs-unstable">nixpkgs-unstable</a></td></tr>
+</table></body></html>`
+
+func compress(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := brotli.NewWriter(&buf)
+	_, err := w.Write([]byte(content))
+	assert.NilError(t, err)
+	assert.NilError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	index := string(compress(t, testPackagesIndex))
+
+	files := map[string]string{
+		"/":                                      testChannels,
+		"/nixos-unstable/packages.json.br":       index,
+		"/nixos-24.11/packages.json.br":          index,
+		"/nixos-24.05/packages.json.br":          string(compress(t, `{"packages": {"linux_5_15": {"pname": "linux", "version": "5.15.160"}}}`)),
+		"/nixos-23.11/packages.json.br":          index,
+		"/empty/":                                "<html></html>",
+		"/empty/nixos-unstable/packages.json.br": index,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestReadKernelVersions(t *testing.T) {
+	t.Parallel()
+
+	got, err := readKernelVersions(strings.NewReader(testPackagesIndex))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, map[string]string{
+		"linuxPackages":         "6.6.37",
+		"linuxPackages_6_1":     "6.1.97",
+		"linuxPackages_testing": "6.10-rc7",
+	})
+
+	_, err = readKernelVersions(strings.NewReader(`["linux"]`))
+	assert.ErrorContains(t, err, "error reading package index")
+
+	got, err = readPackagesIndex(bytes.NewReader(compress(t, testPackagesIndex)))
+	assert.NilError(t, err)
+	assert.Equal(t, got["linuxPackages_6_1"], "6.1.97")
+}
+
+func TestReadChannels(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		limit int
+		want  []string
+	}{
+		"latest stable channel": {
+			limit: 1,
+			want:  []string{"nixos-unstable", "nixos-24.11"},
+		},
+		"all the stable channels": {
+			limit: 10,
+			want:  []string{"nixos-unstable", "nixos-24.11", "nixos-24.05", "nixos-23.11"},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := readChannels(strings.NewReader(testChannels), tt.limit)
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestSearchPackages(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	tests := map[string]struct {
+		mirror   string
+		versions []distro.Version
+		want     map[string]string
+	}{
+		"discovered channels": {
+			mirror: server.URL,
+			want: map[string]string{
+				"nixos-unstable/linuxPackages":         "6.6.37",
+				"nixos-unstable/linuxPackages_6_1":     "6.1.97",
+				"nixos-unstable/linuxPackages_testing": "6.10-rc7",
+				"nixos-24.11/linuxPackages":            "6.6.37",
+				"nixos-24.11/linuxPackages_6_1":        "6.1.97",
+				"nixos-24.11/linuxPackages_testing":    "6.10-rc7",
+				"nixos-24.05/linuxPackages_5_15":       "5.15.160",
+			},
+		},
+		"configured channels": {
+			mirror:   server.URL,
+			versions: []distro.Version{"nixos-24.05", "nixos-22.11"},
+			want: map[string]string{
+				"nixos-24.05/linuxPackages_5_15": "5.15.160",
+			},
+		},
+		"no channels listed": {
+			mirror: server.URL + "/empty/",
+			want:   map[string]string{},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			n := &NixOS{}
+			assert.NilError(t, n.Configure(distro.Config{
+				Mirrors:  []packages.Mirror{{URL: tt.mirror}},
+				Archs:    []packages.Architecture{"x86_64"},
+				Versions: tt.versions,
+			}))
+
+			result, err := n.SearchPackages(context.Background(), *packages.NewSearchOptions("linuxPackages", nil, nil, 0, ""))
+			assert.NilError(t, err)
+
+			got := map[string]string{}
+
+			for _, p := range result {
+				//nolint:forcetypeassert
+				kernel := p.(*Package)
+
+				assert.Equal(t, kernel.GetArch(), "x86_64")
+				assert.Equal(t, kernel.URL(), tt.mirror+"/"+kernel.Channel+"/"+packagesIndexFile)
+
+				got[kernel.Channel+"/"+kernel.GetName()] = kernel.GetVersion()
+			}
+
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestGetChannels(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	n := &NixOS{}
+	assert.NilError(t, n.Configure(distro.Config{}))
+
+	_, err := n.getChannels(context.Background(), packages.Mirror{URL: server.URL + "/empty/"})
+	assert.Assert(t, errors.Is(err, ErrChannelsNotFound))
+}
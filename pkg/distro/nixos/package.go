@@ -0,0 +1,46 @@
+package nixos
+
+import (
+	"context"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Package represents the kernel shipped with a NixOS kernel package set, for a specific platform architecture.
+type Package struct {
+	Name    string
+	Version string
+	Release string
+	Arch    string
+	Channel string
+	url     string
+}
+
+func (p *Package) GetName() string {
+	return p.Name
+}
+
+func (p *Package) GetVersion() string {
+	return p.Version
+}
+
+func (p *Package) GetRelease() string {
+	return p.Release
+}
+
+func (p *Package) GetArch() string {
+	return p.Arch
+}
+
+func (p *Package) GetLocation() string {
+	return p.url
+}
+
+func (p *Package) URL() string {
+	return p.url
+}
+
+// Files visits no files, as the kernel is not shipped as a package.
+func (p *Package) Files(_ context.Context, _ packages.FileVisitor) error {
+	return nil
+}
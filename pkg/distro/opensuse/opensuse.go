@@ -17,6 +17,7 @@ limitations under the License.
 package opensuse
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/maxgio92/krawler/pkg/distro"
@@ -43,11 +44,14 @@ func (f *OpenSuse) Configure(config distro.Config) error {
 
 // GetPackages scrapes each mirror, for each distro version, for each repository,
 // for each architecture, and returns slice of Package and optionally an error.
-func (f *OpenSuse) SearchPackages(options packages.SearchOptions) ([]packages.Package, error) {
+func (f *OpenSuse) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
 	f.config.Output.Logger = options.Log()
 
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, f.config.Mirrors, f.config.Output.Logger)
+
 	// Build distribution version-specific mirror root URLs.
-	perVersionMirrorUrls, err := f.buildPerVersionMirrorUrls(f.config.Mirrors, f.config.Versions)
+	perVersionMirrorUrls, err := f.buildPerVersionMirrorUrls(mirrors, f.config.Versions)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +69,7 @@ func (f *OpenSuse) SearchPackages(options packages.SearchOptions) ([]packages.Pa
 		rss = append(rss, ru.String())
 	}
 	searchOptions := rpm.NewSearchOptions(&options, f.config.Archs, rss)
-	rpmPackages, err := rpm.SearchPackages(searchOptions)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
 	if err != nil {
 		return nil, err
 	}
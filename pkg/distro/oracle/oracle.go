@@ -1,6 +1,7 @@
 package oracle
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"net/url"
 
@@ -28,11 +29,14 @@ func (o *Oracle) Configure(config distro.Config) error {
 
 // GetPackages scrapes each mirror, for each distro version, for each repository,
 // for each architecture, and returns slice of Package and optionally an error.
-func (o *Oracle) SearchPackages(options packages.SearchOptions) ([]packages.Package, error) {
+func (o *Oracle) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
 	o.config.Output.Logger = options.Log()
 
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, o.config.Mirrors, o.config.Output.Logger)
+
 	// Build distribution version-specific mirror root URLs.
-	perVersionMirrorUrls, err := o.buildPerVersionMirrorUrls(o.config.Mirrors, o.config.Versions)
+	perVersionMirrorUrls, err := o.buildPerVersionMirrorUrls(mirrors, o.config.Versions)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +54,7 @@ func (o *Oracle) SearchPackages(options packages.SearchOptions) ([]packages.Pack
 		rss = append(rss, ru.String())
 	}
 	searchOptions := rpm.NewSearchOptions(&options, o.config.Archs, rss)
-	rpmPackages, err := rpm.SearchPackages(searchOptions)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,91 @@
+package photon
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func (p *Photon) buildConfig(def distro.Config, user distro.Config) (distro.Config, error) {
+	config, err := p.mergeConfig(def, user)
+	if err != nil {
+		return distro.Config{}, err
+	}
+
+	err = p.sanitizeConfig(&config)
+	if err != nil {
+		return distro.Config{}, err
+	}
+
+	// Repository URIs are templated while building repository URLs, as they depend on distro versions.
+	return config, nil
+}
+
+// Returns the final configuration by merging the default with the user provided.
+//
+//nolint:unparam
+func (p *Photon) mergeConfig(def distro.Config, config distro.Config) (distro.Config, error) {
+	if len(config.Archs) < 1 {
+		config.Archs = def.Archs
+	} else {
+		for _, arch := range config.Archs {
+			if arch == "" {
+				config.Archs = def.Archs
+
+				break
+			}
+		}
+	}
+
+	if len(config.Mirrors) < 1 {
+		config.Mirrors = def.Mirrors
+	} else {
+		for _, mirror := range config.Mirrors {
+			if mirror.URL == "" {
+				config.Mirrors = def.Mirrors
+
+				break
+			}
+		}
+	}
+
+	if len(config.Repositories) < 1 {
+		config.Repositories = p.getDefaultRepositories()
+	} else {
+		for _, repository := range config.Repositories {
+			if repository.URI == "" {
+				config.Repositories = p.getDefaultRepositories()
+
+				break
+			}
+		}
+	}
+
+	return config, nil
+}
+
+func (p *Photon) sanitizeConfig(config *distro.Config) error {
+	err := p.sanitizeMirrors(&config.Mirrors)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *Photon) sanitizeMirrors(mirrors *[]packages.Mirror) error {
+	for i, mirror := range *mirrors {
+		if !strings.HasSuffix(mirror.URL, "/") {
+			(*mirrors)[i].URL = mirror.URL + "/"
+		}
+
+		_, err := url.Parse(mirror.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package photon
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// Default regex to base the distro version detection on (e.g. 5.0).
+	DistroVersionRegex = `^(0|[1-9]\d*)\.(0|[1-9]\d*)\/$`
+)
+
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{Name: "vmware", URL: "https://packages.vmware.com/photon/"},
+	},
+
+	// Repositories are named after both the distro version and the architecture,
+	// so URIs are templated against both.
+	Repositories: []packages.Repository{
+		{Name: "release", URI: packages.URITemplate("/photon_release_{{ .versions }}_{{ .archs }}/")},
+		{Name: "updates", URI: packages.URITemplate("/photon_updates_{{ .versions }}_{{ .archs }}/")},
+	},
+	Archs: []packages.Architecture{
+		"x86_64",
+		"aarch64",
+	},
+	Versions: nil,
+}
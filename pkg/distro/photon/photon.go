@@ -0,0 +1,200 @@
+package photon
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/output"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/rpm"
+	"github.com/maxgio92/krawler/pkg/scrape"
+	"github.com/maxgio92/krawler/pkg/utils/template"
+)
+
+// Photon is VMware Photon OS.
+type Photon struct {
+	config distro.Config
+}
+
+func (p *Photon) Configure(config distro.Config) error {
+	cfg, err := p.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	p.config = cfg
+
+	return nil
+}
+
+// SearchPackages scrapes each mirror, for each distro version, for each repository,
+// for each architecture, and returns slice of Package and optionally an error.
+func (p *Photon) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	p.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, p.config.Mirrors, p.config.Output.Logger)
+
+	// Build available repository URLs based on provided configuration,
+	// for each distribution version.
+	repositoryURLs, err := p.buildRepositoriesURLs(mirrors, p.config.Versions, p.config.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get RPM packages from each repository.
+	searchOptions := rpm.NewSearchOptions(&options, p.config.Archs, repositoryURLs)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpmPackages, nil
+}
+
+// Returns the list of repositories URLs, for each mirror, for each distro version.
+// Repository URIs are templated against both the architectures and the distro version,
+// as repositories are named after them (e.g. /5.0/photon_release_5.0_x86_64/).
+func (p *Photon) buildRepositoriesURLs(mirrors []packages.Mirror, versions []distro.Version, repositories []packages.Repository) ([]string, error) {
+	var urls []string
+
+	archs := make([]interface{}, 0, len(p.config.Archs))
+	for _, v := range p.config.Archs {
+		archs = append(archs, string(v))
+	}
+
+	for _, mirror := range mirrors {
+		mirrorVersions, err := p.buildVersions([]packages.Mirror{mirror}, versions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range mirrorVersions {
+			vars := map[string]interface{}{
+				"archs":    archs,
+				"versions": []interface{}{string(version)},
+			}
+
+			for _, r := range repositories {
+				uris, err := template.MultiplexAndExecute(string(r.URI), vars)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, uri := range uris {
+					// User-provided repository URIs are already templated against all the
+					// configured versions, so skip the ones of other distro versions.
+					if !matchesVersion(uri, version, versions) {
+						continue
+					}
+
+					u, err := url.JoinPath(mirror.URL, string(version), uri)
+					if err != nil {
+						return nil, err
+					}
+
+					urls = append(urls, u)
+				}
+			}
+		}
+	}
+
+	if len(urls) < 1 {
+		return nil, distro.ErrNoDistroVersionSpecified
+	}
+
+	return urls, nil
+}
+
+// matchesVersion returns whether the repository URI belongs to the distro version,
+// that is it references either the version or none of the configured ones.
+func matchesVersion(uri string, version distro.Version, versions []distro.Version) bool {
+	if containsVersion(uri, version) {
+		return true
+	}
+
+	for _, v := range versions {
+		if containsVersion(uri, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsVersion returns whether the distro version is a path segment of the URI, or a part of a path segment
+// delimited by underscores or dashes (e.g. 5.0 of /photon_release_5.0_x86_64/, but not of /photon_release_15.0_x86_64/).
+func containsVersion(uri string, version distro.Version) bool {
+	parts := strings.FieldsFunc(uri, func(r rune) bool {
+		return r == '/' || r == '_' || r == '-'
+	})
+
+	return slices.Contains(parts, strings.Trim(string(version), "/"))
+}
+
+// Returns a list of distro versions, considering the user-provided configuration,
+// and if not, the ones available on configured mirrors.
+func (p *Photon) buildVersions(mirrors []packages.Mirror, staticVersions []distro.Version) ([]distro.Version, error) {
+	if staticVersions != nil {
+		return staticVersions, nil
+	}
+
+	var dynamicVersions []distro.Version
+
+	dynamicVersions, err := p.crawlVersions(mirrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamicVersions, nil
+}
+
+// Returns the list of the current available distro versions, by scraping
+// the specified mirrors, dynamically.
+func (p *Photon) crawlVersions(mirrors []packages.Mirror) ([]distro.Version, error) {
+	versions := []distro.Version{}
+
+	seedUrls := make([]*url.URL, 0, len(mirrors))
+
+	for _, mirror := range mirrors {
+		u, err := url.Parse(mirror.URL)
+		if err != nil {
+			return []distro.Version{}, err
+		}
+
+		seedUrls = append(seedUrls, u)
+	}
+
+	folderNames, err := scrape.CrawlFolders(
+		seedUrls,
+		DistroVersionRegex,
+		false,
+		p.config.Output.Verbosity >= output.DebugLevel,
+	)
+	if err != nil {
+		return []distro.Version{}, err
+	}
+
+	for _, v := range folderNames {
+		versions = append(versions, distro.Version(v))
+	}
+
+	return versions, nil
+}
+
+// Returns the list of default repositories from the default config.
+func (p *Photon) getDefaultRepositories() []packages.Repository {
+	var repositories []packages.Repository
+
+	for _, repository := range DefaultConfig.Repositories {
+		if !distro.RepositorySliceContains(repositories, repository) {
+			repositories = append(repositories, repository)
+		}
+	}
+
+	return repositories
+}
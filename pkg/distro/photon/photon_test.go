@@ -0,0 +1,142 @@
+package photon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestMatchesVersion(t *testing.T) {
+	t.Parallel()
+
+	versions := []distro.Version{"3.0", "5.0", "13.0"}
+
+	tests := map[string]struct {
+		uri     string
+		version distro.Version
+		want    bool
+	}{
+		"version": {
+			uri:     "/photon_release_3.0_x86_64/",
+			version: "3.0",
+			want:    true,
+		},
+		"version with trailing slash": {
+			uri:     "/photon_updates_5.0_aarch64/",
+			version: "5.0/",
+			want:    true,
+		},
+		"version as path segment": {
+			uri:     "/5.0/updates/",
+			version: "5.0",
+			want:    true,
+		},
+		"other version": {
+			uri:     "/photon_release_5.0_x86_64/",
+			version: "3.0",
+			want:    false,
+		},
+		"other version with the version as suffix": {
+			uri:     "/photon_release_13.0_x86_64/",
+			version: "3.0",
+			want:    false,
+		},
+		"version as suffix of another version": {
+			uri:     "/photon_release_3.0_x86_64/",
+			version: "13.0",
+			want:    false,
+		},
+		"no version": {
+			uri:     "/photon_extras_x86_64/",
+			version: "3.0",
+			want:    true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, matchesVersion(tt.uri, tt.version, versions), tt.want)
+		})
+	}
+}
+
+func TestBuildRepositoriesURLs(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/photon/" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(`<html><body>
+<a href="3.0/">3.0/</a>
+<a href="5.0/">5.0/</a>
+<a href="iso/">iso/</a>
+</body></html>`))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		config distro.Config
+		want   []string
+	}{
+		"default repositories of the crawled versions": {
+			config: distro.Config{
+				Mirrors: []packages.Mirror{{URL: server.URL + "/photon/"}},
+				Archs:   []packages.Architecture{"x86_64"},
+			},
+			want: []string{
+				server.URL + "/photon/3.0/photon_release_3.0_x86_64/",
+				server.URL + "/photon/3.0/photon_updates_3.0_x86_64/",
+				server.URL + "/photon/5.0/photon_release_5.0_x86_64/",
+				server.URL + "/photon/5.0/photon_updates_5.0_x86_64/",
+			},
+		},
+		// The user-provided repository URIs are already templated against all the versions.
+		"user-provided repositories": {
+			config: distro.Config{
+				Mirrors: []packages.Mirror{{URL: "https://packages.vmware.com/photon/"}},
+				Repositories: []packages.Repository{
+					{URI: "/photon_updates_3.0_aarch64/"},
+					{URI: "/photon_updates_13.0_aarch64/"},
+					{URI: "/photon_extras_aarch64/"},
+				},
+				Archs:    []packages.Architecture{"aarch64"},
+				Versions: []distro.Version{"3.0", "13.0"},
+			},
+			want: []string{
+				"https://packages.vmware.com/photon/3.0/photon_updates_3.0_aarch64/",
+				"https://packages.vmware.com/photon/3.0/photon_extras_aarch64/",
+				"https://packages.vmware.com/photon/13.0/photon_updates_13.0_aarch64/",
+				"https://packages.vmware.com/photon/13.0/photon_extras_aarch64/",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Photon{}
+			assert.NilError(t, p.Configure(tt.config))
+
+			got, err := p.buildRepositoriesURLs(p.config.Mirrors, p.config.Versions, p.config.Repositories)
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package rebuild
+
+const (
+	// Default regex to base the distro version detection on.
+	// Match the minor release folders (e.g. 9.3) only, as the major release ones (e.g. 9)
+	// link the latest minor release, which would be crawled twice.
+	VersionRegex = `^(0|[1-9]\d*)\.(0|[1-9]\d*)\/$`
+)
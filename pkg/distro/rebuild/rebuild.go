@@ -0,0 +1,182 @@
+package rebuild
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/output"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/rpm"
+	"github.com/maxgio92/krawler/pkg/scrape"
+)
+
+// Rebuild is a distro rebuilding RHEL from its sources (e.g. Rocky Linux and AlmaLinux), whose mirrors
+// publish a folder per minor release, with the repositories of RHEL, and move the EOL minor releases to a vault.
+type Rebuild struct {
+	config distro.Config
+}
+
+// Configure configures the distro from the user provided configuration, and the default one of the distro.
+func (r *Rebuild) Configure(def distro.Config, config distro.Config) error {
+	cfg, err := r.buildConfig(def, config)
+	if err != nil {
+		return err
+	}
+
+	r.config = cfg
+
+	return nil
+}
+
+// SearchPackages scrapes each mirror, for each distro version, for each repository,
+// for each architecture, and returns slice of Package and optionally an error.
+func (r *Rebuild) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	r.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, r.config.Mirrors, r.config.Output.Logger)
+
+	// Build distribution version-specific mirror root URLs.
+	perVersionMirrorUrls, err := r.buildPerVersionMirrorUrls(mirrors, r.config.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build available repository URLs based on provided configuration,
+	// for each distribution version.
+	repositoryURLs, err := r.buildRepositoriesUrls(perVersionMirrorUrls, r.config.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get RPM packages from each repository.
+	rss := []string{}
+	for _, ru := range repositoryURLs {
+		rss = append(rss, ru.String())
+	}
+	searchOptions := rpm.NewSearchOptions(&options, r.config.Archs, rss)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpmPackages, nil
+}
+
+// Returns the list of version-specific mirror URLs.
+// Versions are built for each mirror, as EOL minor releases are only available on vault mirrors.
+func (r *Rebuild) buildPerVersionMirrorUrls(mirrors []packages.Mirror, versions []distro.Version) ([]*url.URL, error) {
+	var versionRoots []*url.URL
+
+	for _, mirror := range mirrors {
+		mirrorVersions, err := r.buildVersions([]packages.Mirror{mirror}, versions)
+		if err != nil {
+			return []*url.URL{}, err
+		}
+
+		for _, version := range mirrorVersions {
+			versionRoot, err := url.Parse(mirror.URL + string(version))
+			if err != nil {
+				return nil, err
+			}
+
+			versionRoots = append(versionRoots, versionRoot)
+		}
+	}
+
+	if len(versionRoots) < 1 {
+		return nil, distro.ErrNoDistroVersionSpecified
+	}
+
+	return versionRoots, nil
+}
+
+// Returns a list of distro versions, considering the user-provided configuration,
+// and if not, the ones available on configured mirrors.
+func (r *Rebuild) buildVersions(mirrors []packages.Mirror, staticVersions []distro.Version) ([]distro.Version, error) {
+	if staticVersions != nil {
+		return staticVersions, nil
+	}
+
+	var dynamicVersions []distro.Version
+
+	dynamicVersions, err := r.crawlVersions(mirrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamicVersions, nil
+}
+
+// Returns the list of the current available distro versions, by scraping
+// the specified mirrors, dynamically.
+func (r *Rebuild) crawlVersions(mirrors []packages.Mirror) ([]distro.Version, error) {
+	versions := []distro.Version{}
+
+	seedUrls := make([]*url.URL, 0, len(mirrors))
+
+	for _, mirror := range mirrors {
+		u, err := url.Parse(mirror.URL)
+		if err != nil {
+			return []distro.Version{}, err
+		}
+
+		seedUrls = append(seedUrls, u)
+	}
+
+	folderNames, err := scrape.CrawlFolders(
+		seedUrls,
+		VersionRegex,
+		false,
+		r.config.Output.Verbosity >= output.DebugLevel,
+	)
+	if err != nil {
+		return []distro.Version{}, err
+	}
+
+	for _, v := range folderNames {
+		versions = append(versions, distro.Version(v))
+	}
+
+	return versions, nil
+}
+
+// Returns the list of repositories URLs.
+func (r *Rebuild) buildRepositoriesUrls(roots []*url.URL, repositories []packages.Repository) ([]*url.URL, error) {
+	var urls []*url.URL
+
+	for _, root := range roots {
+		//nolint:revive,stylecheck
+		for _, r := range repositories {
+			// Get repository URL from URI.
+			//nolint:revive,stylecheck
+			us, err := url.JoinPath(root.String(), string(r.URI))
+			if err != nil {
+				return nil, err
+			}
+
+			repositoryUrl, err := url.Parse(us)
+			if err != nil {
+				return nil, err
+			}
+
+			urls = append(urls, repositoryUrl)
+		}
+	}
+
+	return urls, nil
+}
+
+// Returns the list of default repositories from the default config.
+func (r *Rebuild) getDefaultRepositories(def distro.Config) []packages.Repository {
+	var repositories []packages.Repository
+
+	for _, repository := range def.Repositories {
+		if !distro.RepositorySliceContains(repositories, repository) {
+			repositories = append(repositories, repository)
+		}
+	}
+
+	return repositories
+}
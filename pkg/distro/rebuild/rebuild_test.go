@@ -0,0 +1,154 @@
+package rebuild
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const testPubIndex = `<html><body>
+<a href="../">../</a>
+<a href="8/">8/</a>
+<a href="8.10/">8.10/</a>
+<a href="9/">9/</a>
+<a href="9.5/">9.5/</a>
+<a href="RPM-GPG-KEY-Rocky-9">RPM-GPG-KEY-Rocky-9</a>
+</body></html>`
+
+const testVaultIndex = `<html><body>
+<a href="../">../</a>
+<a href="8.9/">8.9/</a>
+<a href="9.4/">9.4/</a>
+</body></html>`
+
+var testConfig = distro.Config{
+	Repositories: []packages.Repository{
+		{Name: "BaseOS", URI: packages.URITemplate("/BaseOS/{{ .archs }}/os/")},
+		{Name: "devel", URI: packages.URITemplate("/devel/{{ .archs }}/os/")},
+	},
+	Archs: []packages.Architecture{"x86_64", "aarch64"},
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	indexes := map[string]string{
+		"/pub/":   testPubIndex,
+		"/vault/": testVaultIndex,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		index, ok := indexes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(index))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestBuildVersions(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	tests := map[string]struct {
+		mirror   string
+		versions []distro.Version
+		want     []distro.Version
+	}{
+		"minor releases only": {
+			mirror: server.URL + "/pub/",
+			want:   []distro.Version{"8.10", "9.5"},
+		},
+		"vault": {
+			mirror: server.URL + "/vault/",
+			want:   []distro.Version{"8.9", "9.4"},
+		},
+		"configured versions": {
+			mirror:   server.URL + "/pub/",
+			versions: []distro.Version{"9.3"},
+			want:     []distro.Version{"9.3"},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &Rebuild{}
+
+			got, err := r.buildVersions([]packages.Mirror{{URL: tt.mirror}}, tt.versions)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestBuildRepositoriesUrls(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	r := &Rebuild{}
+	assert.NilError(t, r.Configure(testConfig, distro.Config{
+		Mirrors: []packages.Mirror{
+			{Name: "pub", URL: server.URL + "/pub"},
+			{Name: "vault", URL: server.URL + "/vault"},
+		},
+		Archs: []packages.Architecture{"x86_64"},
+	}))
+
+	roots, err := r.buildPerVersionMirrorUrls(r.config.Mirrors, r.config.Versions)
+	assert.NilError(t, err)
+
+	urls, err := r.buildRepositoriesUrls(roots, r.config.Repositories)
+	assert.NilError(t, err)
+
+	got := make([]string, 0, len(urls))
+	for _, u := range urls {
+		got = append(got, u.String())
+	}
+
+	want := []string{}
+
+	for _, root := range []string{"/pub/8.10", "/pub/9.5", "/vault/8.9", "/vault/9.4"} {
+		for _, repository := range []string{"BaseOS", "devel"} {
+			u, err := url.JoinPath(server.URL, root, repository, "x86_64", "os/")
+			assert.NilError(t, err)
+
+			want = append(want, u)
+		}
+	}
+
+	assert.DeepEqual(t, got, want)
+}
+
+func TestConfigureDefaults(t *testing.T) {
+	t.Parallel()
+
+	r := &Rebuild{}
+	assert.NilError(t, r.Configure(testConfig, distro.Config{}))
+
+	assert.DeepEqual(t, r.config.Archs, testConfig.Archs)
+
+	uris := []string{}
+	for _, repository := range r.config.Repositories {
+		uris = append(uris, string(repository.URI))
+	}
+
+	assert.DeepEqual(t, uris, []string{"/BaseOS/x86_64/os/", "/BaseOS/aarch64/os/", "/devel/x86_64/os/", "/devel/aarch64/os/"})
+}
@@ -0,0 +1,30 @@
+package rocky
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{Name: "pub", URL: "https://dl.rockylinux.org/pub/rocky/"},
+
+		// EOL minor releases are moved to the vault.
+		{Name: "vault", URL: "https://dl.rockylinux.org/vault/rocky/"},
+	},
+	Repositories: []packages.Repository{
+		{Name: "BaseOS", URI: packages.URITemplate("/BaseOS/{{ .archs }}/os/")},
+		{Name: "AppStream", URI: packages.URITemplate("/AppStream/{{ .archs }}/os/")},
+		{Name: "devel", URI: packages.URITemplate("/devel/{{ .archs }}/os/")},
+
+		// Rocky Linux 8 names the devel repository Devel.
+		{Name: "Devel", URI: packages.URITemplate("/Devel/{{ .archs }}/os/")},
+	},
+	Archs: []packages.Architecture{
+		"aarch64",
+		"x86_64",
+		"ppc64le",
+		"s390x",
+	},
+	Versions: nil,
+}
@@ -0,0 +1,15 @@
+package rocky
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/distro/rebuild"
+)
+
+// Rocky is Rocky Linux, a RHEL rebuild.
+type Rocky struct {
+	rebuild.Rebuild
+}
+
+func (r *Rocky) Configure(config distro.Config) error {
+	return r.Rebuild.Configure(DefaultConfig, config)
+}
@@ -0,0 +1,34 @@
+package ubi
+
+import (
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// The prefix of the content set of each UBI major version (e.g. ubi9/9).
+	contentSetPrefix = "ubi"
+)
+
+// DefaultConfig is the default configuration for the public Red Hat Universal Base Image repositories.
+// Versions are the RHEL major versions, each one mapped to its UBI content set (e.g. 9 -> ubi9/9).
+var DefaultConfig = distro.Config{
+	Mirrors: []packages.Mirror{
+		{Name: "cdn-ubi", URL: "https://cdn-ubi.redhat.com/content/public/ubi/dist/"},
+	},
+	Repositories: []packages.Repository{
+		{Name: "baseos", URI: packages.URITemplate("/{{ .archs }}/baseos/os/")},
+		{Name: "appstream", URI: packages.URITemplate("/{{ .archs }}/appstream/os/")},
+		{Name: "codeready-builder", URI: packages.URITemplate("/{{ .archs }}/codeready-builder/os/")},
+	},
+	Archs: []packages.Architecture{
+		"aarch64",
+		"x86_64",
+		"ppc64le",
+		"s390x",
+	},
+	Versions: []distro.Version{
+		"8",
+		"9",
+	},
+}
@@ -0,0 +1,127 @@
+package ubi
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/rpm"
+)
+
+// UBI is the Red Hat Universal Base Image, whose public repositories
+// provide a freely redistributable subset of RHEL content.
+type UBI struct {
+	config distro.Config
+}
+
+func (u *UBI) Configure(config distro.Config) error {
+	cfg, err := u.buildConfig(DefaultConfig, config)
+	if err != nil {
+		return err
+	}
+
+	u.config = cfg
+
+	return nil
+}
+
+// SearchPackages scrapes each mirror, for each distro version, for each repository,
+// for each architecture, and returns slice of Package and optionally an error.
+func (u *UBI) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	u.config.Output.Logger = options.Log()
+
+	// Crawl one mirror per group of equivalent mirrors, failing over to the others.
+	mirrors := distro.ResolveMirrors(ctx, u.config.Mirrors, u.config.Output.Logger)
+
+	// Build content set-specific mirror root URLs.
+	contentSetURLs, err := u.buildContentSetURLs(mirrors, u.config.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build available repository URLs based on provided configuration,
+	// for each content set.
+	repositoryURLs, err := u.buildRepositoriesUrls(contentSetURLs, u.config.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get RPM packages from each repository.
+	rss := []string{}
+	for _, ru := range repositoryURLs {
+		rss = append(rss, ru.String())
+	}
+	searchOptions := rpm.NewSearchOptions(&options, u.config.Archs, rss)
+	rpmPackages, err := rpm.SearchPackages(ctx, searchOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpmPackages, nil
+}
+
+// Returns the list of content set URLs, for each version.
+// E.g. https://cdn-ubi.redhat.com/content/public/ubi/dist/ubi9/9/.
+func (u *UBI) buildContentSetURLs(mirrors []packages.Mirror, versions []distro.Version) ([]*url.URL, error) {
+	if (len(versions) > 0) && (len(mirrors) > 0) {
+		var contentSetRoots []*url.URL
+
+		for _, mirror := range mirrors {
+			for _, version := range versions {
+				s, err := url.JoinPath(mirror.URL, contentSetPrefix+string(version), string(version))
+				if err != nil {
+					return nil, err
+				}
+
+				contentSetRoot, err := url.Parse(s)
+				if err != nil {
+					return nil, err
+				}
+
+				contentSetRoots = append(contentSetRoots, contentSetRoot)
+			}
+		}
+
+		return contentSetRoots, nil
+	}
+
+	return nil, distro.ErrNoDistroVersionSpecified
+}
+
+// Returns the list of repositories URLs.
+func (u *UBI) buildRepositoriesUrls(roots []*url.URL, repositories []packages.Repository) ([]*url.URL, error) {
+	var urls []*url.URL
+
+	for _, root := range roots {
+		for _, r := range repositories {
+			// Get repository URL from URI.
+			us, err := url.JoinPath(root.String(), string(r.URI))
+			if err != nil {
+				return nil, err
+			}
+
+			repositoryURL, err := url.Parse(us)
+			if err != nil {
+				return nil, err
+			}
+
+			urls = append(urls, repositoryURL)
+		}
+	}
+
+	return urls, nil
+}
+
+// Returns the list of default repositories from the default config.
+func (u *UBI) getDefaultRepositories() []packages.Repository {
+	var repositories []packages.Repository
+
+	for _, repository := range DefaultConfig.Repositories {
+		if !distro.RepositorySliceContains(repositories, repository) {
+			repositories = append(repositories, repository)
+		}
+	}
+
+	return repositories
+}
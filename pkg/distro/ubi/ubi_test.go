@@ -0,0 +1,87 @@
+package ubi
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/distro"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config   distro.Config
+		versions []distro.Version
+		uris     []string
+	}{
+		"default": {
+			config:   distro.Config{Archs: []packages.Architecture{"x86_64"}},
+			versions: DefaultConfig.Versions,
+			uris:     []string{"/x86_64/baseos/os/", "/x86_64/appstream/os/", "/x86_64/codeready-builder/os/"},
+		},
+		"user provided": {
+			config: distro.Config{
+				Archs:        []packages.Architecture{"aarch64"},
+				Repositories: []packages.Repository{{URI: "/{{ .archs }}/baseos/os/"}},
+				Versions:     []distro.Version{"9"},
+			},
+			versions: []distro.Version{"9"},
+			uris:     []string{"/aarch64/baseos/os/"},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &UBI{}
+			assert.NilError(t, u.Configure(tt.config))
+
+			uris := []string{}
+			for _, r := range u.config.Repositories {
+				uris = append(uris, string(r.URI))
+			}
+
+			assert.DeepEqual(t, u.config.Mirrors, DefaultConfig.Mirrors)
+			assert.DeepEqual(t, u.config.Versions, tt.versions)
+			assert.DeepEqual(t, uris, tt.uris)
+		})
+	}
+}
+
+func TestBuildRepositoriesUrls(t *testing.T) {
+	t.Parallel()
+
+	u := &UBI{}
+	assert.NilError(t, u.Configure(distro.Config{Archs: []packages.Architecture{"x86_64"}}))
+
+	roots, err := u.buildContentSetURLs(u.config.Mirrors, u.config.Versions)
+	assert.NilError(t, err)
+
+	urls, err := u.buildRepositoriesUrls(roots, u.config.Repositories[:1])
+	assert.NilError(t, err)
+
+	got := []string{}
+	for _, v := range urls {
+		got = append(got, v.String())
+	}
+
+	assert.DeepEqual(t, got, []string{
+		"https://cdn-ubi.redhat.com/content/public/ubi/dist/ubi8/8/x86_64/baseos/os/",
+		"https://cdn-ubi.redhat.com/content/public/ubi/dist/ubi9/9/x86_64/baseos/os/",
+	})
+
+	_, err = u.buildContentSetURLs(u.config.Mirrors, nil)
+	assert.Assert(t, errors.Is(err, distro.ErrNoDistroVersionSpecified))
+
+	roots, err = u.buildContentSetURLs(nil, u.config.Versions)
+	assert.Assert(t, errors.Is(err, distro.ErrNoDistroVersionSpecified))
+	assert.DeepEqual(t, roots, []*url.URL(nil))
+}
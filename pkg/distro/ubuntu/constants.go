@@ -11,6 +11,29 @@ const (
 	X8664Arch                 packages.Architecture = "amd64"
 )
 
+// Flavours are the kernel flavours, as suffixes of the kernel package names following the kernel ABI
+// (e.g. linux-headers-5.15.0-1034-aws, linux-headers-6.8.0-31-generic-64k).
+var Flavours = []string{
+	"generic",
+	"generic-64k",
+	"generic-lpae",
+	"lowlatency",
+	"lowlatency-64k",
+	"aws",
+	"azure",
+	"azure-fde",
+	"gcp",
+	"gke",
+	"gkeop",
+	"ibm",
+	"kvm",
+	"nvidia",
+	"nvidia-64k",
+	"nvidia-lowlatency",
+	"oracle",
+	"oracle-64k",
+}
+
 var DefaultConfig = distro.Config{
 	Mirrors: []packages.Mirror{
 		{URL: "https://mirrors.edge.kernel.org/ubuntu/"},
@@ -1,8 +1,14 @@
 package ubuntu
 
 import (
+	"context"
+	"strings"
+	"unicode"
+
 	"github.com/maxgio92/krawler/pkg/distro"
 	"github.com/maxgio92/krawler/pkg/distro/debian"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/deb"
 )
 
 type Ubuntu struct {
@@ -19,3 +25,34 @@ func (u *Ubuntu) Configure(config distro.Config) error {
 
 	return nil
 }
+
+// SearchPackages searches the packages as for Debian, and marks each package
+// with the kernel flavour it has been built for, if any.
+func (u *Ubuntu) SearchPackages(ctx context.Context, options packages.SearchOptions) ([]packages.Package, error) {
+	debs, err := u.Debian.SearchPackages(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range debs {
+		if p, ok := packages.Unwrap(v).(*deb.Package); ok {
+			p.Flavour = flavourFromPackageName(p.Name)
+		}
+	}
+
+	return debs, nil
+}
+
+// flavourFromPackageName returns the kernel flavour from the package name suffix following the kernel ABI,
+// or an empty string for packages common to all flavours (e.g. linux-headers-5.15.0-91).
+func flavourFromPackageName(name string) string {
+	for _, flavour := range Flavours {
+		// The flavour follows the kernel ABI (e.g. 91 of linux-headers-5.15.0-91-generic-64k).
+		abi := strings.TrimSuffix(name, "-"+flavour)
+		if abi != name && abi != "" && unicode.IsDigit(rune(abi[len(abi)-1])) {
+			return flavour
+		}
+	}
+
+	return ""
+}
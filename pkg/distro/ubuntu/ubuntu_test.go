@@ -0,0 +1,44 @@
+package ubuntu
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFlavourFromPackageName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"linux-headers-5.15.0-91-generic":                "generic",
+		"linux-headers-5.15.0-91-generic-lpae":           "generic-lpae",
+		"linux-headers-6.8.0-31-generic-64k":             "generic-64k",
+		"linux-headers-6.8.0-31-lowlatency":              "lowlatency",
+		"linux-headers-6.8.0-31-lowlatency-64k":          "lowlatency-64k",
+		"linux-headers-5.15.0-1034-aws":                  "aws",
+		"linux-headers-6.5.0-1016-azure-fde":             "azure-fde",
+		"linux-image-unsigned-6.5.0-1014-gcp":            "gcp",
+		"linux-modules-extra-5.15.0-1051-azure":          "azure",
+		"linux-modules-5.15.0-1049-gke":                  "gke",
+		"linux-headers-5.15.0-1045-oracle":               "oracle",
+		"linux-headers-6.8.0-1005-oracle-64k":            "oracle-64k",
+		"linux-headers-6.8.0-1008-nvidia-lowlatency":     "nvidia-lowlatency",
+		"linux-headers-5.15.0-91":                        "",
+		"linux-aws-headers-5.15.0-1034":                  "",
+		"linux-headers-generic":                          "",
+		"linux-headers-generic-64k":                      "",
+		"linux-headers-5.15.0-91-unknown":                "",
+		"linux-image-unsigned-5.15.0-91-generic-dbgsym":  "",
+		"linux-headers-5.15.0-1034-aws-something-custom": "",
+	}
+
+	for name, want := range tests {
+		name, want := name, want
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, flavourFromPackageName(name), want)
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package fetch
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	cacheDirName    = "krawler"
+	cacheEntryExt   = ".entry"
+	cacheTempPrefix = ".tmp-"
+)
+
+// DefaultCacheDir returns the default cache directory, under the user cache directory
+// (e.g. ~/.cache/krawler).
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, cacheDirName), nil
+}
+
+// Cache is an on-disk cache of HTTP responses, keyed by URL.
+// Each entry is a file with a JSON header line, followed by the response body.
+// The file modification time is the last time the entry was used.
+type Cache struct {
+	dir string
+}
+
+// cacheHeader is the metadata of a cached response, to revalidate it.
+type cacheHeader struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	ContentType  string    `json:"contentType,omitempty"`
+	Stored       time.Time `json:"stored"`
+}
+
+// CacheInfo is a summary of the cache content.
+type CacheInfo struct {
+	Dir     string
+	Entries int
+	Size    int64
+}
+
+// OpenCache returns the cache in the directory, creating it if it does not exist.
+func OpenCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "error creating cache directory")
+	}
+
+	return &Cache{dir: dir}, nil
+}
+
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Info returns the number of entries and the size of the cache.
+func (c *Cache) Info() (CacheInfo, error) {
+	info := CacheInfo{Dir: c.dir}
+
+	entries, err := c.entries()
+	if err != nil {
+		return info, err
+	}
+
+	for _, e := range entries {
+		info.Entries++
+		info.Size += e.Size()
+	}
+
+	return info, nil
+}
+
+// Prune removes the entries not used for longer than maxAge, then the least recently used ones
+// until the cache size is within maxSize. A zero maxAge or maxSize is not enforced.
+// It returns the number of removed entries.
+func (c *Cache) Prune(maxAge time.Duration, maxSize int64) (int, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	// Most recently used first.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().After(entries[j].ModTime())
+	})
+
+	var (
+		removed int
+		size    int64
+	)
+
+	for _, e := range entries {
+		size += e.Size()
+
+		expired := maxAge > 0 && time.Since(e.ModTime()) > maxAge
+		oversized := maxSize > 0 && size > maxSize
+
+		if !expired && !oversized {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+
+		size -= e.Size()
+		removed++
+	}
+
+	return removed, nil
+}
+
+// Clear removes all the entries, and the ones being stored.
+// It returns the number of removed entries.
+func (c *Cache) Clear() (int, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int
+
+	for _, d := range dirEntries {
+		name := d.Name()
+
+		if d.IsDir() || (filepath.Ext(name) != cacheEntryExt && !strings.HasPrefix(name, cacheTempPrefix)) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+
+		if filepath.Ext(name) == cacheEntryExt {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// Invalidate removes the entry of the URL, if any.
+func (c *Cache) Invalidate(u string) {
+	//nolint:errcheck
+	os.Remove(c.path(u))
+}
+
+func (c *Cache) entries() ([]os.FileInfo, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []os.FileInfo
+
+	for _, d := range dirEntries {
+		if d.IsDir() || filepath.Ext(d.Name()) != cacheEntryExt {
+			continue
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, info)
+	}
+
+	return entries, nil
+}
+
+func (c *Cache) path(u string) string {
+	sum := sha256.Sum256([]byte(u))
+
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+cacheEntryExt)
+}
+
+// open returns the header and the body of the cached response of the URL, if any.
+func (c *Cache) open(u string) (*cacheHeader, io.ReadCloser, bool) {
+	f, err := os.Open(c.path(u))
+	if err != nil {
+		return nil, nil, false
+	}
+
+	r := bufio.NewReader(f)
+
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		f.Close()
+
+		return nil, nil, false
+	}
+
+	header := &cacheHeader{}
+	if err = json.Unmarshal(line, header); err != nil || header.URL != u {
+		f.Close()
+
+		return nil, nil, false
+	}
+
+	return header, &cacheBody{Reader: r, Closer: f}, true
+}
+
+// touch marks the entry of the URL as used.
+func (c *Cache) touch(u string) {
+	now := time.Now()
+
+	//nolint:errcheck
+	os.Chtimes(c.path(u), now, now)
+}
+
+// store returns a reader of the body that stores it as the entry of the URL,
+// once read to the end. If the body is closed before, nothing is stored.
+func (c *Cache) store(u string, header *cacheHeader, body io.ReadCloser) io.ReadCloser {
+	f, err := os.CreateTemp(c.dir, cacheTempPrefix)
+	if err != nil {
+		return body
+	}
+
+	line, err := json.Marshal(header)
+	if err == nil {
+		_, err = f.Write(append(line, '\n'))
+	}
+
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return body
+	}
+
+	return &storeBody{ReadCloser: body, file: f, path: c.path(u)}
+}
+
+type cacheBody struct {
+	io.Reader
+	io.Closer
+}
+
+// storeBody copies the body being read to a temporary file,
+// which is moved to the entry path once the body is read to the end.
+type storeBody struct {
+	io.ReadCloser
+	file *os.File
+	path string
+
+	once sync.Once
+	err  error
+}
+
+func (b *storeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	if n > 0 && b.err == nil {
+		_, b.err = b.file.Write(p[:n])
+	}
+
+	if errors.Is(err, io.EOF) {
+		b.once.Do(b.commit)
+	} else if err != nil {
+		b.err = err
+	}
+
+	return n, err
+}
+
+func (b *storeBody) Close() error {
+	// Incomplete bodies are not stored.
+	if b.err == nil {
+		b.err = io.ErrUnexpectedEOF
+	}
+
+	b.once.Do(b.commit)
+
+	return b.ReadCloser.Close()
+}
+
+func (b *storeBody) commit() {
+	err := b.file.Close()
+
+	if err == nil && b.err == nil {
+		err = os.Rename(b.file.Name(), b.path)
+	}
+
+	if err != nil || b.err != nil {
+		os.Remove(b.file.Name())
+	}
+}
+
+// cacheTransport is an http.RoundTripper that stores the responses of GET requests in the cache,
+// and revalidates them with conditional requests (If-None-Match, If-Modified-Since).
+// Responses without validators (ETag, Last-Modified) are not cached.
+type cacheTransport struct {
+	base  http.RoundTripper
+	cache *Cache
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	u := req.URL.String()
+
+	header, body, cached := t.cache.open(u)
+	if cached {
+		req = req.Clone(req.Context())
+
+		if header.ETag != "" {
+			req.Header.Set("If-None-Match", header.ETag)
+		}
+
+		if header.LastModified != "" {
+			req.Header.Set("If-Modified-Since", header.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		if cached {
+			body.Close()
+		}
+
+		return nil, err
+	}
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		t.cache.touch(u)
+
+		return cachedResponse(req, resp, header, body), nil
+	}
+
+	if cached {
+		body.Close()
+	}
+
+	if resp.StatusCode == http.StatusOK && cacheable(resp) {
+		resp.Body = t.cache.store(u, &cacheHeader{
+			URL:          u,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			ContentType:  resp.Header.Get("Content-Type"),
+			Stored:       time.Now(),
+		}, resp.Body)
+	}
+
+	return resp, nil
+}
+
+// Returns whether the response can be cached and revalidated.
+func cacheable(resp *http.Response) bool {
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// Returns a 200 OK response with the cached body, from a 304 Not Modified response.
+func cachedResponse(req *http.Request, notModified *http.Response, header *cacheHeader, body io.ReadCloser) *http.Response {
+	h := notModified.Header.Clone()
+
+	if header.ContentType != "" && h.Get("Content-Type") == "" {
+		h.Set("Content-Type", header.ContentType)
+	}
+
+	if header.ETag != "" && h.Get("ETag") == "" {
+		h.Set("ETag", header.ETag)
+	}
+
+	if header.LastModified != "" && h.Get("Last-Modified") == "" {
+		h.Set("Last-Modified", header.LastModified)
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        h,
+		Body:          body,
+		ContentLength: -1,
+		Request:       req,
+	}
+}
@@ -0,0 +1,137 @@
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+const (
+	testETag    = `"v1"`
+	testContent = "Package: linux-headers\n"
+)
+
+// Returns a server serving the test content with an ETag, and counting the full responses.
+func newCacheTestServer(t *testing.T, full *int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == testETag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		*full++
+
+		w.Header().Set("ETag", testETag)
+		//nolint:errcheck
+		w.Write([]byte(testContent))
+	}))
+}
+
+func get(t *testing.T, client *http.Client, u string) string {
+	t.Helper()
+
+	resp, err := client.Get(u)
+	assert.NilError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NilError(t, err)
+
+	return string(body)
+}
+
+func TestCacheRevalidation(t *testing.T) {
+	var full int
+
+	server := newCacheTestServer(t, &full)
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	client, err := NewClient(Options{CacheDir: dir})
+	assert.NilError(t, err)
+
+	assert.Equal(t, get(t, client, server.URL), testContent)
+	assert.Equal(t, get(t, client, server.URL), testContent)
+	assert.Equal(t, full, 1)
+
+	cache, err := OpenCache(dir)
+	assert.NilError(t, err)
+
+	info, err := cache.Info()
+	assert.NilError(t, err)
+	assert.Equal(t, info.Entries, 1)
+
+	cache.Invalidate(server.URL)
+
+	assert.Equal(t, get(t, client, server.URL), testContent)
+	assert.Equal(t, full, 2)
+}
+
+func TestCacheIncompleteBody(t *testing.T) {
+	var full int
+
+	server := newCacheTestServer(t, &full)
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	client, err := NewClient(Options{CacheDir: dir})
+	assert.NilError(t, err)
+
+	resp, err := client.Get(server.URL)
+	assert.NilError(t, err)
+
+	_, err = resp.Body.Read(make([]byte, 1))
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+}
+
+func TestCachePrune(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := OpenCache(dir)
+	assert.NilError(t, err)
+
+	old := time.Now().Add(-48 * time.Hour)
+
+	for _, u := range []string{"https://a/1", "https://a/2", "https://a/3"} {
+		assert.NilError(t, os.WriteFile(cache.path(u), []byte("{}\n0123456789"), 0o600))
+	}
+
+	assert.NilError(t, os.Chtimes(cache.path("https://a/1"), old, old))
+
+	removed, err := cache.Prune(24*time.Hour, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, removed, 1)
+
+	removed, err = cache.Prune(0, 20)
+	assert.NilError(t, err)
+	assert.Equal(t, removed, 1)
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, cacheTempPrefix+"1"), nil, 0o600))
+
+	removed, err = cache.Clear()
+	assert.NilError(t, err)
+	assert.Equal(t, removed, 1)
+
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+}
@@ -0,0 +1,6 @@
+package fetch
+
+import "errors"
+
+// ErrCABundleEmpty is returned when the CA bundle file contains no PEM-encoded certificates.
+var ErrCABundleEmpty = errors.New("CA bundle contains no certificates")
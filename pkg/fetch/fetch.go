@@ -0,0 +1,374 @@
+// Package fetch provides the HTTP client shared by the distros and the package backends,
+// with timeouts, retries with exponential backoff, failover to equivalent mirrors,
+// concurrency and rate limits, an on-disk cache, custom CA bundles and proxy settings.
+package fetch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultTimeout         = 30 * time.Second
+	DefaultRetries         = 3
+	DefaultBackoff         = time.Second
+	DefaultMaxBackoff      = 30 * time.Second
+	DefaultParallelism     = 16
+	DefaultHostParallelism = 4
+)
+
+// Options configures the HTTP client.
+// Zero values are replaced by the defaults.
+type Options struct {
+	// The timeout for a single request to connect and receive the response headers.
+	// Response bodies are not bound to it, as packages can be large.
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// The number of times a request is retried on network errors,
+	// 429 Too Many Requests and 5xx responses. A negative value disables retries.
+	Retries int `json:"retries,omitempty"`
+
+	// The wait before the first retry, doubled on each further retry.
+	Backoff time.Duration `json:"backoff,omitempty"`
+
+	// The maximum wait between retries, including the ones requested through Retry-After.
+	MaxBackoff time.Duration `json:"maxBackoff,omitempty" mapstructure:"maxBackoff"`
+
+	// The path of a PEM file with the CA certificates to trust, in addition to the system ones.
+	CABundle string `json:"caBundle,omitempty" mapstructure:"caBundle"`
+
+	// The URL of the proxy to use. If empty, the proxy is read from the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string `json:"proxy,omitempty"`
+
+	// The maximum number of concurrent requests, to all hosts.
+	Parallelism int `json:"parallelism,omitempty"`
+
+	// The maximum number of concurrent requests to a single host.
+	HostParallelism int `json:"hostParallelism,omitempty" mapstructure:"hostParallelism"`
+
+	// The maximum number of requests per second to a single host. Unlimited if zero.
+	HostRate float64 `json:"hostRate,omitempty" mapstructure:"hostRate"`
+
+	// The directory of the on-disk cache of responses, revalidated with conditional requests.
+	// Responses are not cached if empty.
+	CacheDir string `json:"cacheDir,omitempty" mapstructure:"cacheDir"`
+
+	// Whether to try the equivalent mirrors fastest first, as for their measured response time,
+	// rather than in order of declaration. Mirrors which failed recently are tried last anyway.
+	FastestMirror bool `json:"fastestMirror,omitempty" mapstructure:"fastestMirror"`
+}
+
+var (
+	defaultClient = mustNewClient(Options{})
+	defaultCache  *Cache
+	defaultMu     sync.RWMutex
+)
+
+// Default returns the shared HTTP client.
+func Default() *http.Client {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	return defaultClient
+}
+
+// Configure replaces the shared HTTP client with one built from the options.
+func Configure(options Options) error {
+	client, cache, err := newClient(options)
+	if err != nil {
+		return err
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultClient = client
+	defaultCache = cache
+
+	return nil
+}
+
+// Invalidate removes the cached response of the URL from the shared HTTP client cache, if any.
+// It's meant for responses found to be corrupt, not to be served again.
+func Invalidate(u string) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	if defaultCache != nil {
+		defaultCache.Invalidate(u)
+	}
+}
+
+// Get requests the URL with the shared HTTP client, and returns the response body.
+// Responses other than 200 OK are an error.
+func Get(ctx context.Context, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := Default().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		//nolint:goerr113
+		return nil, errors.Errorf("download(%s): unexpected HTTP status code: got %d, want %d", u, resp.StatusCode, http.StatusOK)
+	}
+
+	return resp.Body, nil
+}
+
+// NewClient returns an HTTP client that caches, limits and retries requests, as configured by the options.
+func NewClient(options Options) (*http.Client, error) {
+	client, _, err := newClient(options)
+
+	return client, err
+}
+
+func newClient(options Options) (*http.Client, *Cache, error) {
+	options = withDefaults(options)
+
+	base, err := newTransport(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		rt    http.RoundTripper = newLimitTransport(base, options)
+		cache *Cache
+	)
+
+	if options.CacheDir != "" {
+		cache, err = OpenCache(options.CacheDir)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rt = &cacheTransport{base: rt, cache: cache}
+	}
+
+	// Requests failed over to all the equivalent mirrors are retried with backoff.
+	rt = &failoverTransport{base: rt, mirrors: DefaultMirrors(), fastest: options.FastestMirror}
+
+	return &http.Client{
+		Transport: &Transport{
+			Base:       rt,
+			Retries:    options.Retries,
+			Backoff:    options.Backoff,
+			MaxBackoff: options.MaxBackoff,
+		},
+	}, cache, nil
+}
+
+func mustNewClient(options Options) *http.Client {
+	client, err := NewClient(options)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+func withDefaults(options Options) Options {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	if options.Retries == 0 {
+		options.Retries = DefaultRetries
+	}
+
+	if options.Retries < 0 {
+		options.Retries = 0
+	}
+
+	if options.Backoff == 0 {
+		options.Backoff = DefaultBackoff
+	}
+
+	if options.MaxBackoff == 0 {
+		options.MaxBackoff = DefaultMaxBackoff
+	}
+
+	if options.Parallelism <= 0 {
+		options.Parallelism = DefaultParallelism
+	}
+
+	if options.HostParallelism <= 0 {
+		options.HostParallelism = DefaultHostParallelism
+	}
+
+	return options
+}
+
+func newTransport(options Options) (*http.Transport, error) {
+	//nolint:forcetypeassert
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	transport.DialContext = (&net.Dialer{
+		Timeout:   options.Timeout,
+		KeepAlive: DefaultTimeout,
+	}).DialContext
+	transport.TLSHandshakeTimeout = options.Timeout
+	transport.ResponseHeaderTimeout = options.Timeout
+
+	if options.Proxy != "" {
+		proxy, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "error parsing proxy URL")
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if options.CABundle != "" {
+		pool, err := loadCABundle(options.CABundle)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return transport, nil
+}
+
+// Returns the system certificate pool, with the certificates of the PEM file appended.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading CA bundle")
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Wrap(ErrCABundleEmpty, path)
+	}
+
+	return pool, nil
+}
+
+// Transport is an http.RoundTripper that retries requests failed with network errors,
+// 429 Too Many Requests or 5xx responses, with exponential backoff.
+// The Retry-After header of the responses is honoured, up to MaxBackoff.
+type Transport struct {
+	Base       http.RoundTripper
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+
+		if attempt >= t.Retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+
+		if resp != nil {
+			//nolint:errcheck
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// Returns the wait before the next retry: the Retry-After of the response if any,
+// otherwise the backoff doubled on each attempt, both capped at MaxBackoff.
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	wait := t.Backoff << attempt
+
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			wait = retryAfter
+		}
+	}
+
+	if wait > t.MaxBackoff || wait < 0 {
+		wait = t.MaxBackoff
+	}
+
+	return wait
+}
+
+// Returns whether the request can and should be retried.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// Parses the Retry-After header value, either delay seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+
+		return wait, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,160 @@
+package fetch
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func newTestClient(t *testing.T, retries int) *http.Client {
+	t.Helper()
+
+	client, err := NewClient(Options{
+		Retries:    retries,
+		Backoff:    time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+	assert.NilError(t, err)
+
+	return client
+}
+
+func TestRetryOnServerErrors(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(t, 3).Get(server.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.Equal(t, atomic.LoadInt32(&requests), int32(3))
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(t, 2).Get(server.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, resp.StatusCode, http.StatusBadGateway)
+	assert.Equal(t, atomic.LoadInt32(&requests), int32(3))
+}
+
+func TestNoRetryOnClientErrors(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(t, 3).Get(server.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	assert.Equal(t, atomic.LoadInt32(&requests), int32(1))
+}
+
+func TestGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/found" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	body, err := Get(context.Background(), server.URL+"/found")
+	assert.NilError(t, err)
+
+	content, err := io.ReadAll(body)
+	body.Close()
+
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "content")
+
+	_, err = Get(context.Background(), server.URL+"/missing")
+	assert.ErrorContains(t, err, "unexpected HTTP status code: got 404")
+}
+
+func TestBackoff(t *testing.T) {
+	transport := &Transport{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, transport.backoff(0, nil), time.Second)
+	assert.Equal(t, transport.backoff(2, nil), 4*time.Second)
+	assert.Equal(t, transport.backoff(3, nil), 5*time.Second)
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, transport.backoff(0, resp), 2*time.Second)
+
+	resp = &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+	assert.Equal(t, transport.backoff(0, resp), 5*time.Second)
+}
+
+func TestCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+
+	client, err := NewClient(Options{CABundle: path})
+	assert.Assert(t, err != nil)
+	assert.Assert(t, client == nil)
+
+	assert.NilError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+
+	_, err = NewClient(Options{CABundle: path})
+	assert.Assert(t, errors.Is(err, ErrCABundleEmpty))
+
+	_, err = newTestClient(t, -1).Get(server.URL)
+	assert.Assert(t, err != nil)
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NilError(t, os.WriteFile(path, cert, 0o600))
+
+	client, err = NewClient(Options{CABundle: path})
+	assert.NilError(t, err)
+
+	resp, err := client.Get(server.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+}
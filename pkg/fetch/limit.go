@@ -0,0 +1,169 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limitTransport is an http.RoundTripper that bounds the number of concurrent requests,
+// both overall and per host, and the rate of requests per host.
+// A request holds its slots until its response body is read or closed, or until its response
+// if not successful, as its body is often left unread (e.g. of the probes of missing files).
+type limitTransport struct {
+	base            http.RoundTripper
+	slots           chan struct{}
+	hostParallelism int
+	hostInterval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+type hostLimit struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newLimitTransport(base http.RoundTripper, options Options) *limitTransport {
+	t := &limitTransport{
+		base:            base,
+		slots:           make(chan struct{}, options.Parallelism),
+		hostParallelism: options.HostParallelism,
+		hosts:           map[string]*hostLimit{},
+	}
+
+	if options.HostRate > 0 {
+		t.hostInterval = time.Duration(float64(time.Second) / options.HostRate)
+	}
+
+	return t
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if err := acquire(ctx, t.slots); err != nil {
+		return nil, err
+	}
+
+	host := t.host(req.URL.Host)
+
+	if err := acquire(ctx, host.slots); err != nil {
+		<-t.slots
+
+		return nil, err
+	}
+
+	release := func() {
+		<-host.slots
+		<-t.slots
+	}
+
+	if err := host.wait(ctx, t.hostInterval); err != nil {
+		release()
+
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		release()
+
+		return resp, nil
+	}
+
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+// Returns the limits of the host, creating them on first use.
+func (t *limitTransport) host(name string) *hostLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.hosts[name]
+	if !ok {
+		h = &hostLimit{slots: make(chan struct{}, t.hostParallelism)}
+		t.hosts[name] = h
+	}
+
+	return h
+}
+
+// wait blocks until the next request to the host is allowed, at most one per interval.
+func (h *hostLimit) wait(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+
+	now := time.Now()
+	at := h.next
+
+	if at.Before(now) {
+		at = now
+	}
+
+	h.next = at.Add(interval)
+
+	h.mu.Unlock()
+
+	if delay := time.Until(at); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil
+}
+
+func acquire(ctx context.Context, slots chan struct{}) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseBody releases the request slots once the body is read to the end or closed,
+// whatever comes first.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.once.Do(b.release)
+	}
+
+	return n, err
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+
+	return err
+}
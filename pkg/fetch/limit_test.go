@@ -0,0 +1,147 @@
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestHostParallelism(t *testing.T) {
+	var current, max int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{Parallelism: 8, HostParallelism: 2})
+	assert.NilError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := client.Get(server.URL)
+			assert.NilError(t, err)
+
+			//nolint:errcheck
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Assert(t, atomic.LoadInt32(&max) <= 2)
+}
+
+func TestHostRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{HostRate: 20})
+	assert.NilError(t, err)
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		assert.NilError(t, err)
+		resp.Body.Close()
+	}
+
+	// The first request is immediate, the next ones are spaced by 50ms.
+	assert.Assert(t, time.Since(start) >= 100*time.Millisecond)
+}
+
+func TestReleaseOnEOF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{Parallelism: 1})
+	assert.NilError(t, err)
+
+	resp, err := client.Get(server.URL)
+	assert.NilError(t, err)
+
+	defer resp.Body.Close()
+
+	_, err = io.ReadAll(resp.Body)
+	assert.NilError(t, err)
+
+	// The body is read to the end, so a request can be made before closing it.
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		next, err := client.Get(server.URL)
+		assert.NilError(t, err)
+		next.Body.Close()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("request blocked by a response read to the end")
+	}
+}
+
+func TestReleaseOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{Parallelism: 1, HostParallelism: 1, Retries: -1})
+	assert.NilError(t, err)
+
+	// The bodies of the missing files are left unread and open, and they don't hold the host slot.
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 5; i++ {
+			resp, err := client.Get(server.URL)
+			if !assert.Check(t, err) {
+				return
+			}
+
+			assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+			t.Cleanup(func() { resp.Body.Close() })
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("request blocked by the responses of missing files")
+	}
+}
@@ -0,0 +1,266 @@
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// The weight of the last response time in the mirror latency moving average.
+const latencyWeight = 0.3
+
+var defaultMirrors = NewMirrorGroups()
+
+// DefaultMirrors returns the registry of the groups of equivalent mirrors, with their health score,
+// which the HTTP clients fail over requests with. It lives as long as the process, and is safe for
+// concurrent use: the groups and scores registered are kept until Reset, e.g. between runs.
+func DefaultMirrors() *MirrorGroups {
+	return defaultMirrors
+}
+
+// MirrorGroups is a registry of groups of equivalent mirrors, identified by their base URLs.
+// A request to a mirror of a group fails over to the other mirrors of the group, ordered by health score:
+// mirrors which failed recently are tried last.
+type MirrorGroups struct {
+	mu     sync.RWMutex
+	groups [][]string
+	scores map[string]*mirrorScore
+}
+
+// mirrorScore is the health score of a mirror host.
+type mirrorScore struct {
+	// The number of consecutive failures.
+	failures int
+
+	// The moving average of the response time.
+	latency time.Duration
+}
+
+func NewMirrorGroups() *MirrorGroups {
+	return &MirrorGroups{scores: map[string]*mirrorScore{}}
+}
+
+// Add registers the base URLs as equivalent mirrors, in order of preference.
+// Groups sharing a mirror are merged.
+func (m *MirrorGroups) Add(baseURLs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	group := []string{}
+
+	for _, u := range baseURLs {
+		u = withTrailingSlash(u)
+
+		if !slices.Contains(group, u) {
+			group = append(group, u)
+		}
+	}
+
+	if len(group) < 2 {
+		return
+	}
+
+	for i, g := range m.groups {
+		for _, u := range group {
+			if slices.Contains(g, u) {
+				for _, v := range group {
+					if !slices.Contains(g, v) {
+						g = append(g, v)
+					}
+				}
+
+				m.groups[i] = g
+
+				return
+			}
+		}
+	}
+
+	m.groups = append(m.groups, group)
+}
+
+// Alternatives returns the URL with the base URL of each mirror of its group, ordered by health score.
+// If fastest is true, mirrors with the same number of failures are ordered by response time,
+// otherwise as registered.
+// If the URL is not served by a mirror of a group, only the URL is returned.
+func (m *MirrorGroups) Alternatives(u string, fastest bool) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var (
+		group []string
+		base  string
+	)
+
+	// The longest matching base URL wins.
+	for _, g := range m.groups {
+		for _, v := range g {
+			if strings.HasPrefix(u, v) && len(v) > len(base) {
+				group, base = g, v
+			}
+		}
+	}
+
+	if group == nil {
+		return []string{u}
+	}
+
+	mirrors := make([]string, len(group))
+	copy(mirrors, group)
+
+	sort.SliceStable(mirrors, func(i, j int) bool {
+		a, b := m.score(mirrors[i]), m.score(mirrors[j])
+
+		if a.failures != b.failures {
+			return a.failures < b.failures
+		}
+
+		return fastest && a.latency < b.latency
+	})
+
+	alternatives := make([]string, 0, len(mirrors))
+	for _, v := range mirrors {
+		alternatives = append(alternatives, v+strings.TrimPrefix(u, base))
+	}
+
+	return alternatives
+}
+
+// MarkFailure lowers the health score of the mirror serving the URL.
+func (m *MirrorGroups) MarkFailure(u string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hostScore(u).failures++
+}
+
+// MarkSuccess resets the failures of the mirror serving the URL, and records its response time.
+func (m *MirrorGroups) MarkSuccess(u string, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.hostScore(u)
+	s.failures = 0
+
+	if s.latency == 0 {
+		s.latency = latency
+	} else {
+		s.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(s.latency))
+	}
+}
+
+// Reset removes all the groups and scores.
+func (m *MirrorGroups) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.groups = nil
+	m.scores = map[string]*mirrorScore{}
+}
+
+func (m *MirrorGroups) score(u string) mirrorScore {
+	if s, ok := m.scores[host(u)]; ok {
+		return *s
+	}
+
+	return mirrorScore{}
+}
+
+func (m *MirrorGroups) hostScore(u string) *mirrorScore {
+	h := host(u)
+
+	s, ok := m.scores[h]
+	if !ok {
+		s = &mirrorScore{}
+		m.scores[h] = s
+	}
+
+	return s
+}
+
+// failoverTransport is an http.RoundTripper that fails over requests to the equivalent mirrors,
+// on network errors, 429 Too Many Requests and 5xx responses.
+type failoverTransport struct {
+	base    http.RoundTripper
+	mirrors *MirrorGroups
+	fastest bool
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+
+	alternatives := t.mirrors.Alternatives(req.URL.String(), t.fastest)
+	if len(alternatives) < 2 {
+		return t.base.RoundTrip(req)
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for i, u := range alternatives {
+		target, perr := url.Parse(u)
+		if perr != nil {
+			return nil, perr
+		}
+
+		r := req.Clone(req.Context())
+		r.URL = target
+		r.Host = ""
+
+		start := time.Now()
+
+		resp, err = t.base.RoundTrip(r)
+		if err == nil && !failed(resp) {
+			t.mirrors.MarkSuccess(u, time.Since(start))
+
+			return resp, nil
+		}
+
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+
+		t.mirrors.MarkFailure(u)
+
+		// The last failure is returned as is.
+		if resp != nil && i < len(alternatives)-1 {
+			//nolint:errcheck
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+
+	return resp, err
+}
+
+// Returns whether the response is a failure of the mirror, rather than of the request.
+func failed(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+func host(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+
+	return parsed.Host
+}
+
+func withTrailingSlash(u string) string {
+	if strings.HasSuffix(u, "/") {
+		return u
+	}
+
+	return u + "/"
+}
@@ -0,0 +1,71 @@
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFailover(t *testing.T) {
+	var down int
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		down++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer secondary.Close()
+
+	DefaultMirrors().Add(primary.URL+"/fedora", secondary.URL+"/pub/fedora")
+	defer DefaultMirrors().Reset()
+
+	client, err := NewClient(Options{Retries: -1})
+	assert.NilError(t, err)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(primary.URL + "/fedora/releases/39/repodata/repomd.xml")
+		assert.NilError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		assert.Equal(t, string(body), "/pub/fedora/releases/39/repodata/repomd.xml")
+	}
+
+	// The failed mirror is tried last.
+	assert.Equal(t, down, 1)
+}
+
+func TestMirrorAlternatives(t *testing.T) {
+	m := NewMirrorGroups()
+	m.Add("https://a.example.com/debian", "https://b.example.com/debian/")
+	m.Add("https://b.example.com/debian", "https://c.example.com/mirror/debian")
+
+	assert.DeepEqual(t, m.Alternatives("https://a.example.com/debian/dists/stable/Release", false), []string{
+		"https://a.example.com/debian/dists/stable/Release",
+		"https://b.example.com/debian/dists/stable/Release",
+		"https://c.example.com/mirror/debian/dists/stable/Release",
+	})
+
+	m.MarkFailure("https://a.example.com/debian/dists/stable/Release")
+
+	assert.DeepEqual(t, m.Alternatives("https://c.example.com/mirror/debian/pool/", false), []string{
+		"https://b.example.com/debian/pool/",
+		"https://c.example.com/mirror/debian/pool/",
+		"https://a.example.com/debian/pool/",
+	})
+
+	assert.DeepEqual(t, m.Alternatives("https://d.example.com/debian/pool/", false), []string{
+		"https://d.example.com/debian/pool/",
+	})
+}
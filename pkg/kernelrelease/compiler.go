@@ -1,55 +1,38 @@
 package kernelrelease
 
 import (
-	"bufio"
-	"io"
-	"strings"
-	"unicode"
+	"context"
+	"regexp"
 
 	p "github.com/maxgio92/krawler/pkg/packages"
 )
 
 const (
 	ConfigCompilerVersion = "CONFIG_GCC_VERSION"
+
+	// ConfigCompilerVersionText is the compiler version string of the kernel configuration (Linux 5.8+),
+	// e.g. "gcc-12 (Debian 12.2.0-14) 12.2.0".
+	ConfigCompilerVersionText = "CONFIG_CC_VERSION_TEXT"
+
+	// CompileHeaderCompiler is the compiler version string of the kernel compile header
+	// (include/generated/compile.h), e.g. "gcc version 8.3.0 (Debian 8.3.0-6)".
+	CompileHeaderCompiler = "LINUX_COMPILER"
 )
 
-func GetCompilerVersionFromKernelPackage(pkg p.Package) (string, error) {
-	return getCompilerVersionFromFileReaders(pkg.FileReaders())
-}
+// gccVersionPattern matches the GCC version in a compiler version string.
+var gccVersionPattern = regexp.MustCompile(`gcc(?:-\d+)?(?: version | \([^)]*\) )(\d+)\.(\d+)(?:\.(\d+))?`)
 
-func getCompilerVersionFromFileReaders(files []io.Reader) (string, error) {
-	for _, r := range files {
-		fileScanner := bufio.NewScanner(r)
-		fileScanner.Split(bufio.ScanLines)
-
-		for fileScanner.Scan() {
-			line := fileScanner.Text()
-			if strings.Contains(line, ConfigCompilerVersion) {
-				compilerVersion, err := parseConfig(line)
-				if err == nil {
-					return compilerVersion, nil
-				}
-
-				return "", err
-			}
-		}
-
-		err := fileScanner.Err()
-		if err != nil {
-			return "", err
-		}
+// GetCompilerVersionFromKernelPackage returns the GCC version the kernel is built with, in the format
+// of CONFIG_GCC_VERSION (e.g. 120200), from the toolchain declared by the package files.
+func GetCompilerVersionFromKernelPackage(ctx context.Context, pkg p.Package) (string, error) {
+	toolchain, err := GetToolchainFromKernelPackage(ctx, pkg)
+	if err != nil {
+		return "", err
 	}
 
-	return "", ErrKernelCompilerVersionNotFound
-}
-
-func parseConfig(line string) (string, error) {
-	tokens := strings.FieldsFunc(line, func(c rune) bool {
-		return unicode.Is(unicode.Space, c) || unicode.Is(unicode.Sm, c)
-	})
-	if len(tokens) > 1 {
-		return tokens[len(tokens)-1], nil
+	if v := toolchain.GCCVersion(); v != "" {
+		return v, nil
 	}
 
-	return "", ErrKernelConfigValueNotFound
+	return "", ErrKernelCompilerVersionNotFound
 }
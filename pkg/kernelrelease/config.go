@@ -0,0 +1,224 @@
+package kernelrelease
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	p "github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	configOptionPrefix = "CONFIG_"
+
+	// The suffix of the configuration option names matching the options by name prefix (e.g. CONFIG_BPF*).
+	configOptionWildcard = "*"
+
+	// ConfigNotSet is the value of the kernel configuration options not set
+	// (i.e. # CONFIG_X is not set).
+	ConfigNotSet = "n"
+)
+
+// AllConfigOptions is the configuration option matching all the options, to show the whole configuration.
+var AllConfigOptions = ConfigOption{Name: configOptionPrefix + configOptionWildcard}
+
+// Config is the kernel configuration, as the values of its options by name (e.g. CONFIG_BPF_JIT is y).
+// The string values are unquoted, and the options not set are n.
+type Config map[string]string
+
+// String returns the options, sorted by name (e.g. CONFIG_BPF_JIT=y, CONFIG_KPROBES=y).
+func (c Config) String() string {
+	options := make([]string, 0, len(c))
+
+	for name, value := range c {
+		options = append(options, name+"="+value)
+	}
+
+	sort.Strings(options)
+
+	return strings.Join(options, ", ")
+}
+
+// Select returns the options of the configuration matching the options by name.
+// The selection of a nil configuration, i.e. not available, is nil.
+func (c Config) Select(options ...ConfigOption) Config {
+	if c == nil {
+		return nil
+	}
+
+	selected := make(Config)
+
+	for name, value := range c {
+		for _, o := range options {
+			if o.selects(name) {
+				selected[name] = value
+
+				break
+			}
+		}
+	}
+
+	return selected
+}
+
+// ParseConfig parses the kernel configuration (i.e. the .config file).
+// The configuration is nil if no line sets an option.
+func ParseConfig(r io.Reader) (Config, error) {
+	return parseConfig(r, nil)
+}
+
+// parseConfig parses the kernel configuration, passing each line to visitLine too, if not nil.
+// The configuration is nil if no line sets an option, as for files other than a kernel configuration.
+func parseConfig(r io.Reader, visitLine func(string)) (Config, error) {
+	var config Config
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if visitLine != nil {
+			visitLine(line)
+		}
+
+		if name, value, ok := parseConfigLine(line); ok {
+			if config == nil {
+				config = make(Config)
+			}
+
+			config[name] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// parseConfigLine returns the option of a kernel configuration line, and whether the line sets an option.
+func parseConfigLine(line string) (string, string, bool) {
+	if strings.HasPrefix(line, "# "+configOptionPrefix) && strings.HasSuffix(line, " is not set") {
+		return strings.TrimSuffix(strings.TrimPrefix(line, "# "), " is not set"), ConfigNotSet, true
+	}
+
+	name, value, ok := strings.Cut(line, "=")
+	if !ok || !strings.HasPrefix(name, configOptionPrefix) {
+		return "", "", false
+	}
+
+	return name, unquote(value), true
+}
+
+// ConfigOption is a kernel configuration option, and the value it's expected to have, if any.
+// A name ending with * matches the options by name prefix, without expected value.
+type ConfigOption struct {
+	Name  string
+	Value string
+}
+
+// ParseConfigOption parses a kernel configuration option in the form NAME[=VALUE]
+// (e.g. CONFIG_DEBUG_INFO_BTF=y), or NAME* (e.g. CONFIG_BPF*). The CONFIG_ prefix of the name is optional.
+func ParseConfigOption(option string) (ConfigOption, error) {
+	name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+	if name == "" || name == configOptionPrefix {
+		return ConfigOption{}, errors.Wrapf(ErrConfigOptionNotValid, "%q", option)
+	}
+
+	if strings.HasSuffix(name, configOptionWildcard) && value != "" {
+		return ConfigOption{}, errors.Wrapf(ErrConfigOptionNotValid, "%q: options matched by prefix have no value", option)
+	}
+
+	if !strings.HasPrefix(name, configOptionPrefix) {
+		name = configOptionPrefix + name
+	}
+
+	return ConfigOption{Name: name, Value: unquote(value)}, nil
+}
+
+// selects returns whether the option is the option with the name, or matches it by prefix.
+func (o ConfigOption) selects(name string) bool {
+	if prefix := strings.TrimSuffix(o.Name, configOptionWildcard); prefix != o.Name {
+		return strings.HasPrefix(name, prefix)
+	}
+
+	return o.Name == name
+}
+
+// Matches returns whether the kernel configuration has the option with the expected value.
+// The options missing from the configuration are not set, and any value matches if none is expected.
+// A nil configuration, i.e. not available, does not match any expected value.
+func (o ConfigOption) Matches(config Config) bool {
+	if o.Value == "" {
+		return true
+	}
+
+	if config == nil {
+		return false
+	}
+
+	value, ok := config[o.Name]
+	if !ok {
+		value = ConfigNotSet
+	}
+
+	return value == o.Value
+}
+
+// readKernelFiles reads the toolchain, and the values of the options, from the kernel configuration
+// or otherwise the kernel compile header, shipped with the package.
+// The package files are visited until a kernel configuration declaring the compiler is found.
+// The configuration is nil if the package does not ship one.
+func readKernelFiles(ctx context.Context, pkg p.Package, options ...ConfigOption) (Toolchain, Config, error) {
+	var (
+		toolchain, fallback Toolchain
+		config, fallbackCfg Config
+	)
+
+	err := pkg.Files(ctx, func(_ string, content io.Reader) error {
+		var tp toolchainParser
+
+		parsed, err := parseConfig(content, tp.parseLine)
+		if err != nil {
+			return err
+		}
+
+		// Only the options looked for are kept, for the configuration not to be held.
+		cfg := parsed.Select(options...)
+
+		if tp.fromConfig {
+			toolchain, config = tp.toolchain(), cfg
+
+			return p.ErrSkipFiles
+		}
+
+		if t := tp.toolchain(); fallback.Compiler == "" {
+			fallback = t
+		}
+
+		if fallbackCfg == nil {
+			fallbackCfg = cfg
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Toolchain{}, nil, err
+	}
+
+	if toolchain.Compiler == "" {
+		toolchain, config = fallback, fallbackCfg
+	}
+
+	if toolchain.Compiler == "" {
+		return Toolchain{}, config, ErrKernelCompilerVersionNotFound
+	}
+
+	return toolchain, config, nil
+}
@@ -0,0 +1,215 @@
+package kernelrelease_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/kernelrelease"
+	"github.com/maxgio92/krawler/pkg/output"
+	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/packages/deb"
+)
+
+const testConfig = `#
+# Automatically generated file; DO NOT EDIT.
+# Linux/x86 6.1.0 Kernel Configuration
+#
+CONFIG_CC_VERSION_TEXT="gcc-12 (Debian 12.2.0-14) 12.2.0"
+CONFIG_CC_IS_GCC=y
+CONFIG_GCC_VERSION=120200
+CONFIG_BPF_JIT=y
+CONFIG_KPROBES=y
+CONFIG_DEBUG_INFO_BTF=y
+# CONFIG_FTRACE_SYSCALLS is not set
+CONFIG_NR_CPUS=8192
+`
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := kernelrelease.ParseConfig(strings.NewReader(testConfig))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, config, kernelrelease.Config{
+		"CONFIG_CC_VERSION_TEXT": "gcc-12 (Debian 12.2.0-14) 12.2.0",
+		"CONFIG_CC_IS_GCC":       "y",
+		"CONFIG_GCC_VERSION":     "120200",
+		"CONFIG_BPF_JIT":         "y",
+		"CONFIG_KPROBES":         "y",
+		"CONFIG_DEBUG_INFO_BTF":  "y",
+		"CONFIG_FTRACE_SYSCALLS": "n",
+		"CONFIG_NR_CPUS":         "8192",
+	})
+
+	assert.Equal(t, kernelrelease.Config{"CONFIG_KPROBES": "y", "CONFIG_BPF_JIT": "m"}.String(), "CONFIG_BPF_JIT=m, CONFIG_KPROBES=y")
+
+	// Files other than a kernel configuration have none.
+	config, err = kernelrelease.ParseConfig(strings.NewReader("#define LINUX_COMPILER \"gcc version 12.2.0\"\n"))
+	assert.NilError(t, err)
+	assert.Assert(t, config == nil)
+}
+
+func TestConfigSelect(t *testing.T) {
+	t.Parallel()
+
+	config, err := kernelrelease.ParseConfig(strings.NewReader(testConfig))
+	assert.NilError(t, err)
+
+	tests := map[string]struct {
+		options []string
+		want    kernelrelease.Config
+	}{
+		"name": {
+			options: []string{"CONFIG_KPROBES=y", "FTRACE_SYSCALLS", "CONFIG_MISSING"},
+			want:    kernelrelease.Config{"CONFIG_KPROBES": "y", "CONFIG_FTRACE_SYSCALLS": "n"},
+		},
+		"prefix": {
+			options: []string{"CONFIG_BPF*", "GCC*"},
+			want:    kernelrelease.Config{"CONFIG_BPF_JIT": "y", "CONFIG_GCC_VERSION": "120200"},
+		},
+		"no options": {
+			want: kernelrelease.Config{},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			options := make([]kernelrelease.ConfigOption, 0, len(tt.options))
+
+			for _, v := range tt.options {
+				o, err := kernelrelease.ParseConfigOption(v)
+				assert.NilError(t, err)
+
+				options = append(options, o)
+			}
+
+			assert.DeepEqual(t, config.Select(options...), tt.want)
+		})
+	}
+
+	assert.DeepEqual(t, config.Select(kernelrelease.AllConfigOptions), config)
+	assert.Assert(t, kernelrelease.Config(nil).Select(kernelrelease.AllConfigOptions) == nil)
+}
+
+func TestConfigOptionMatches(t *testing.T) {
+	t.Parallel()
+
+	config := kernelrelease.Config{"CONFIG_DEBUG_INFO_BTF": "y", "CONFIG_FTRACE_SYSCALLS": "n"}
+
+	tests := map[string]struct {
+		option string
+		config kernelrelease.Config
+		want   bool
+	}{
+		"value":                       {option: "CONFIG_DEBUG_INFO_BTF=y", config: config, want: true},
+		"other value":                 {option: "CONFIG_DEBUG_INFO_BTF=m", config: config, want: false},
+		"name without prefix":         {option: "DEBUG_INFO_BTF=y", config: config, want: true},
+		"not set":                     {option: "CONFIG_FTRACE_SYSCALLS=n", config: config, want: true},
+		"missing":                     {option: "CONFIG_KPROBES=n", config: config, want: true},
+		"missing set":                 {option: "CONFIG_KPROBES=y", config: config, want: false},
+		"no value":                    {option: "CONFIG_KPROBES", config: config, want: true},
+		"configuration not available": {option: "CONFIG_KPROBES=n", config: nil, want: false},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			o, err := kernelrelease.ParseConfigOption(tt.option)
+			assert.NilError(t, err)
+			assert.Equal(t, o.Matches(tt.config), tt.want)
+		})
+	}
+
+	_, err := kernelrelease.ParseConfigOption("=y")
+	assert.Assert(t, errors.Is(err, kernelrelease.ErrConfigOptionNotValid))
+
+	_, err = kernelrelease.ParseConfigOption("CONFIG_BPF*=y")
+	assert.Assert(t, errors.Is(err, kernelrelease.ErrConfigOptionNotValid))
+}
+
+func TestGetKernelReleasesFromPackagesWithConfigOptions(t *testing.T) {
+	t.Parallel()
+
+	btf := &testFilePackage{
+		Package: deb.Package{Name: "linux-headers", Version: "6.1.0", Release: "1", Arch: "amd64"},
+		files:   []string{testConfig},
+	}
+	noBTF := &testFilePackage{
+		Package: deb.Package{Name: "linux-headers", Version: "4.19.0", Release: "1", Arch: "amd64"},
+		files:   []string{"CONFIG_CC_IS_GCC=y\nCONFIG_GCC_VERSION=80300\n# CONFIG_DEBUG_INFO_BTF is not set\n"},
+	}
+	noConfig := &testFilePackage{
+		Package: deb.Package{Name: "linux-headers", Version: "4.9.0", Release: "1", Arch: "amd64"},
+	}
+
+	options := []kernelrelease.ConfigOption{
+		{Name: "CONFIG_DEBUG_INFO_BTF", Value: "y"},
+		{Name: "CONFIG_BPF_JIT"},
+	}
+
+	releases, err := kernelrelease.GetKernelReleasesFromPackages(context.Background(),
+		[]packages.Package{btf, noBTF, noConfig}, output.NewLogger(), 0, options...)
+	assert.NilError(t, err)
+	assert.Equal(t, len(releases), 1)
+	assert.Equal(t, releases[0].Fullversion, "6.1.0")
+	assert.DeepEqual(t, releases[0].Config, kernelrelease.Config{"CONFIG_DEBUG_INFO_BTF": "y", "CONFIG_BPF_JIT": "y"})
+	assert.Equal(t, releases[0].CompilerVersion, "120200")
+
+	releases, err = kernelrelease.GetKernelReleasesFromPackages(context.Background(),
+		[]packages.Package{btf, noBTF, noConfig}, output.NewLogger(), 0, kernelrelease.ConfigOption{Name: "CONFIG_DEBUG_INFO_BTF"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(releases), 3)
+	assert.DeepEqual(t, releases[1].Config, kernelrelease.Config{"CONFIG_DEBUG_INFO_BTF": "n"})
+	assert.Assert(t, releases[2].Config == nil)
+
+	// The whole configuration is kept with all the options.
+	releases, err = kernelrelease.GetKernelReleasesFromPackages(context.Background(),
+		[]packages.Package{btf}, output.NewLogger(), 0, kernelrelease.AllConfigOptions)
+	assert.NilError(t, err)
+	assert.Equal(t, len(releases), 1)
+	assert.Equal(t, len(releases[0].Config), 8)
+	assert.Equal(t, releases[0].Config["CONFIG_NR_CPUS"], "8192")
+}
+
+// testCancelPackage cancels the context visiting its files, and visits them until some time after.
+type testCancelPackage struct {
+	deb.Package
+	cancel  context.CancelFunc
+	visited atomic.Bool
+}
+
+func (p *testCancelPackage) Files(_ context.Context, _ packages.FileVisitor) error {
+	p.cancel()
+
+	time.Sleep(50 * time.Millisecond)
+	p.visited.Store(true)
+
+	return nil
+}
+
+func TestGetKernelReleasesFromPackagesCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pkg := &testCancelPackage{Package: deb.Package{Name: "linux-headers-6.1.0-18-amd64", Version: "6.1.76"}, cancel: cancel}
+	next := &deb.Package{Name: "linux-headers-6.1.0-18-cloud-amd64", Version: "6.1.76"}
+
+	// The package files being visited are waited for, once canceled.
+	_, err := kernelrelease.GetKernelReleasesFromPackages(ctx, []packages.Package{pkg, next}, output.NewLogger(), 1)
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	assert.Assert(t, pkg.visited.Load())
+}
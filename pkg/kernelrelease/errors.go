@@ -5,4 +5,5 @@ import "fmt"
 var (
 	ErrKernelCompilerVersionNotFound = fmt.Errorf("compiler version not found")
 	ErrKernelConfigValueNotFound     = fmt.Errorf("the line does not contain the config value")
+	ErrConfigOptionNotValid          = fmt.Errorf("the kernel configuration option is not valid")
 )
@@ -1,10 +1,15 @@
 package kernelrelease
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
 
 	p "github.com/maxgio92/krawler/pkg/packages"
 )
@@ -14,24 +19,56 @@ type Arch string
 type Archs map[Arch]string
 
 type KernelRelease struct {
-	Fullversion      string `json:"full_version"`
-	Version          int    `json:"version"`
-	PatchLevel       int    `json:"patch_level"`
-	Sublevel         int    `json:"sublevel"`
-	Extraversion     string `json:"extra_version"`
-	FullExtraversion string `json:"full_extra_version"`
-	Architecture     Arch   `json:"architecture"`
-	PackageName      string `json:"package_name"`
-	PackageURL       string `json:"package_url"`
-	CompilerVersion  string `json:"compiler_version"`
+	Fullversion      string     `json:"full_version"`
+	Version          int        `json:"version"`
+	PatchLevel       int        `json:"patch_level"`
+	Sublevel         int        `json:"sublevel"`
+	Extraversion     string     `json:"extra_version"`
+	FullExtraversion string     `json:"full_extra_version"`
+	Architecture     Arch       `json:"architecture"`
+	PackageName      string     `json:"package_name"`
+	PackageURL       string     `json:"package_url"`
+	PackageVersion   Version    `json:"package_version"`
+	CompilerVersion  string     `json:"compiler_version"`
+	Toolchain        Toolchain  `json:"toolchain"`
+	Config           Config     `json:"config,omitempty"`
+	Packages         PackageSet `json:"packages,omitempty"`
+	Flavour          string     `json:"flavour,omitempty"`
+}
+
+// BuildFromPackage builds the kernel release from the package metadata, and the toolchain
+// and the values of the kernel configuration options from the package files.
+// If the package files cannot be read, the release is built without compiler version
+// and configuration, and the error is returned.
+func (k *KernelRelease) BuildFromPackage(ctx context.Context, pkg p.Package, options ...ConfigOption) error {
+	k.buildFromPackageMetadata(pkg)
+
+	toolchain, config, err := readKernelFiles(ctx, pkg, options...)
+	if err != nil && !errors.Is(err, ErrKernelCompilerVersionNotFound) {
+		// The release is built anyway, without compiler version.
+		return errors.Wrap(err, "error reading package files")
+	}
+
+	k.Toolchain = toolchain
+	k.Config = config
+	k.CompilerVersion = toolchain.GCCVersion()
+
+	return nil
 }
 
+// buildFromPackageMetadata builds the kernel release from the package metadata only.
+//
 //nolint:cyclop
-func (k *KernelRelease) BuildFromPackage(pkg p.Package) error {
+func (k *KernelRelease) buildFromPackageMetadata(pkg p.Package) {
 	k.PackageName = pkg.GetName()
 	k.PackageURL = pkg.URL()
+	k.PackageVersion = versionFromPackage(pkg)
 	k.Architecture = Arch(pkg.GetArch())
 
+	if f, ok := pkg.(p.Flavoured); ok {
+		k.Flavour = f.GetFlavour()
+	}
+
 	kernelVersion := versionStringFromPackage(pkg)
 	match := kernelVersionPattern.FindStringSubmatch(kernelVersion)
 
@@ -57,15 +94,17 @@ func (k *KernelRelease) BuildFromPackage(pkg p.Package) error {
 			}
 		}
 	}
+}
 
-	compilerVersion, err := GetCompilerVersionFromKernelPackage(pkg)
-	if err != nil {
-		k.CompilerVersion = ""
+// IsRelease returns whether the kernel release is the release, as its full version and extra version
+// (e.g. 4.18.0-331.el8.x86_64, as uname -r of RPM kernels), its package name, or its package name suffix
+// beginning with the version (e.g. 6.1.0-18-amd64 of linux-headers-6.1.0-18-amd64, as uname -r of Debian kernels).
+func (k *KernelRelease) IsRelease(release string) bool {
+	if release == k.Fullversion+k.FullExtraversion || release == k.PackageName {
+		return true
 	}
 
-	k.CompilerVersion = compilerVersion
-
-	return nil
+	return release != "" && unicode.IsDigit(rune(release[0])) && strings.HasSuffix(k.PackageName, "-"+release)
 }
 
 func (k *KernelRelease) SHA256Sum() string {
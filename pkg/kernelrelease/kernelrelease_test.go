@@ -1,6 +1,9 @@
 package kernelrelease_test
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/maxgio92/krawler/pkg/kernelrelease"
@@ -33,6 +36,7 @@ func TestBuildFromPackage(t *testing.T) {
 				Extraversion:     "",
 				FullExtraversion: "",
 				PackageName:      "linux-headers",
+				PackageVersion:   kernelrelease.Version{Version: "5.5.2", Scheme: packages.VersionSchemeDeb},
 				Architecture:     kernelrelease.Arch(""),
 			},
 		},
@@ -51,6 +55,7 @@ func TestBuildFromPackage(t *testing.T) {
 				Extraversion:     "",
 				FullExtraversion: "",
 				PackageName:      "linux-headers",
+				PackageVersion:   kernelrelease.Version{Scheme: packages.VersionSchemeDeb},
 				Architecture:     kernelrelease.Arch(""),
 			},
 		},
@@ -69,6 +74,7 @@ func TestBuildFromPackage(t *testing.T) {
 				Extraversion:     "arch2-1",
 				FullExtraversion: "-arch2-1.x86_64",
 				PackageName:      "linux-headers",
+				PackageVersion:   kernelrelease.Version{Version: "6.1.5-arch2", Release: "1", Scheme: packages.VersionSchemeDeb},
 				Architecture:     kernelrelease.Arch("x86_64"),
 			},
 		},
@@ -87,6 +93,7 @@ func TestBuildFromPackage(t *testing.T) {
 				Extraversion:     "10",
 				FullExtraversion: "-10.amd64",
 				PackageName:      "linux-headers",
+				PackageVersion:   kernelrelease.Version{Version: "3.16.0", Release: "10", Scheme: packages.VersionSchemeDeb},
 				Architecture:     kernelrelease.Arch("amd64"),
 			},
 		},
@@ -105,6 +112,7 @@ func TestBuildFromPackage(t *testing.T) {
 				Extraversion:     "6",
 				FullExtraversion: "-6.amd64",
 				PackageName:      "linux-headers",
+				PackageVersion:   kernelrelease.Version{Version: "4.19.0", Release: "6", Scheme: packages.VersionSchemeDeb},
 				Architecture:     kernelrelease.Arch("amd64"),
 			},
 		},
@@ -123,6 +131,7 @@ func TestBuildFromPackage(t *testing.T) {
 				Extraversion:     "2",
 				FullExtraversion: "-2+grsecunoff1~bpo9+1.amd64",
 				PackageName:      "linux-headers",
+				PackageVersion:   kernelrelease.Version{Version: "4.9.65", Release: "2+grsecunoff1~bpo9+1", Scheme: packages.VersionSchemeDeb},
 				Architecture:     kernelrelease.Arch("amd64"),
 			},
 		},
@@ -141,9 +150,31 @@ func TestBuildFromPackage(t *testing.T) {
 				Extraversion:     "deb10u4",
 				FullExtraversion: "-deb10u4~bpo9+1.amd64",
 				PackageName:      "linux-headers",
+				PackageVersion:   kernelrelease.Version{Version: "4.19+105", Release: "deb10u4~bpo9+1", Scheme: packages.VersionSchemeDeb},
 				Architecture:     kernelrelease.Arch("amd64"),
 			},
 		},
+		"Ubuntu cloud flavour version": {
+			pkg: &deb.Package{
+				Name:    "linux-headers-5.15.0-1034-aws",
+				Version: "5.15.0",
+				Release: "1034.38",
+				Arch:    "amd64",
+				Flavour: "aws",
+			},
+			want: kernelrelease.KernelRelease{
+				Fullversion:      "5.15.0",
+				Version:          5,
+				PatchLevel:       15,
+				Sublevel:         0,
+				Extraversion:     "1034",
+				FullExtraversion: "-1034.38.amd64",
+				PackageName:      "linux-headers-5.15.0-1034-aws",
+				PackageVersion:   kernelrelease.Version{Version: "5.15.0", Release: "1034.38", Scheme: packages.VersionSchemeDeb},
+				Architecture:     kernelrelease.Arch("amd64"),
+				Flavour:          "aws",
+			},
+		},
 	}
 	for name, tt := range tests {
 		tt := tt
@@ -151,11 +182,140 @@ func TestBuildFromPackage(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
+			// The package has no files.
+			pkg := &testFilePackage{Package: *tt.pkg.(*deb.Package)}
+
 			got := kernelrelease.KernelRelease{}
-			err := got.BuildFromPackage(tt.pkg)
+			err := got.BuildFromPackage(context.Background(), pkg)
 
 			assert.NilError(t, err)
 			assert.DeepEqual(t, tt.want, got)
 		})
 	}
 }
+
+type testFilePackage struct {
+	deb.Package
+	files   []string
+	visited int
+}
+
+func (p *testFilePackage) Files(_ context.Context, visit packages.FileVisitor) error {
+	for _, f := range p.files {
+		p.visited++
+
+		if skip, err := packages.VisitFile(visit, ".config", strings.NewReader(f)); skip {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestGetCompilerVersionFromKernelPackage(t *testing.T) {
+	t.Parallel()
+
+	pkg := &testFilePackage{files: []string{
+		"CONFIG_64BIT=y\n",
+		"CONFIG_64BIT=y\nCONFIG_GCC_VERSION=120200\n",
+		"CONFIG_GCC_VERSION=110300\n",
+	}}
+
+	got, err := kernelrelease.GetCompilerVersionFromKernelPackage(context.Background(), pkg)
+	assert.NilError(t, err)
+	assert.Equal(t, got, "120200")
+
+	// The remaining files are skipped once found.
+	assert.Equal(t, pkg.visited, 2)
+
+	_, err = kernelrelease.GetCompilerVersionFromKernelPackage(context.Background(), &testFilePackage{})
+	assert.Equal(t, err, kernelrelease.ErrKernelCompilerVersionNotFound)
+}
+
+func TestGetCompilerVersionFromVersionText(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		file string
+		want string
+	}{
+		"GCC version preferred": {
+			file: "CONFIG_CC_VERSION_TEXT=\"gcc-12 (Debian 12.2.0-14) 12.2.0\"\nCONFIG_GCC_VERSION=120299\n",
+			want: "120299",
+		},
+		"config version text": {
+			file: "CONFIG_CC_VERSION_TEXT=\"x86_64-linux-gnu-gcc-12 (Ubuntu 12.3.0-1ubuntu1~22.04) 12.3.0\"\n",
+			want: "120300",
+		},
+		"compile header": {
+			file: "#define UTS_MACHINE \"x86_64\"\n#define LINUX_COMPILER \"gcc version 8.3.0 (Debian 8.3.0-6) \"\n",
+			want: "80300",
+		},
+		"compile header with linker": {
+			file: "#define LINUX_COMPILER \"gcc-10 (Debian 10.2.1-6) 10.2.1 20210110, GNU ld (GNU Binutils for Debian) 2.35.2\"\n",
+			want: "100201",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := kernelrelease.GetCompilerVersionFromKernelPackage(context.Background(), &testFilePackage{files: []string{tt.file}})
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+
+	// Kernels not built with GCC have no GCC version.
+	clang := &testFilePackage{files: []string{"CONFIG_CC_VERSION_TEXT=\"Debian clang version 14.0.6\"\n"}}
+
+	_, err := kernelrelease.GetCompilerVersionFromKernelPackage(context.Background(), clang)
+	assert.Equal(t, err, kernelrelease.ErrKernelCompilerVersionNotFound)
+}
+
+func TestIsRelease(t *testing.T) {
+	t.Parallel()
+
+	rpm := kernelrelease.KernelRelease{Fullversion: "4.18.0", FullExtraversion: "-331.el8.x86_64", PackageName: "kernel-devel"}
+	assert.Assert(t, rpm.IsRelease("4.18.0-331.el8.x86_64"))
+	assert.Assert(t, rpm.IsRelease("kernel-devel"))
+	assert.Assert(t, !rpm.IsRelease("4.18.0-331.el8"))
+	assert.Assert(t, !rpm.IsRelease("devel"))
+
+	deb := kernelrelease.KernelRelease{Fullversion: "6.1.76", FullExtraversion: "-1.amd64", PackageName: "linux-headers-6.1.0-18-amd64"}
+	assert.Assert(t, deb.IsRelease("6.1.0-18-amd64"))
+	assert.Assert(t, deb.IsRelease("linux-headers-6.1.0-18-amd64"))
+	assert.Assert(t, !deb.IsRelease("6.1.0-18-cloud-amd64"))
+}
+
+func TestFilterPackagesByRelease(t *testing.T) {
+	t.Parallel()
+
+	pkgs := []packages.Package{
+		&testPackage{name: "linux-headers-6.1.0-18-amd64", version: "6.1.76", release: "1", arch: "amd64"},
+		&testPackage{name: "linux-headers-6.1.0-18-cloud-amd64", version: "6.1.76", release: "1", arch: "amd64"},
+		&testPackage{name: "kernel-devel", version: "4.18.0", release: "331.el8", arch: "x86_64"},
+		&testPackage{name: "kernel-devel", version: "4.18.0", release: "348.el8", arch: "x86_64"},
+	}
+
+	assert.DeepEqual(t, packageNames(kernelrelease.FilterPackagesByRelease(pkgs, "6.1.0-18-amd64")), []string{"linux-headers-6.1.0-18-amd64"})
+	assert.Equal(t, kernelrelease.FilterPackagesByRelease(pkgs, "4.18.0-331.el8.x86_64")[0], pkgs[2])
+	assert.Equal(t, len(kernelrelease.FilterPackagesByRelease(pkgs, "4.18.0-331.el8.x86_64")), 1)
+	assert.Equal(t, len(kernelrelease.FilterPackagesByRelease(pkgs, "5.14.0")), 0)
+}
+
+func TestKernelReleaseFlavourJSON(t *testing.T) {
+	t.Parallel()
+
+	// The flavour is omitted for the kernels not built in multiple flavours.
+	data, err := json.Marshal(kernelrelease.KernelRelease{PackageName: "kernel-devel"})
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(data), `"flavour"`))
+
+	data, err = json.Marshal(kernelrelease.KernelRelease{PackageName: "linux-headers-5.15.0-1034-aws", Flavour: "aws"})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(data), `"flavour":"aws"`))
+}
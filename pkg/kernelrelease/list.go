@@ -1,24 +1,103 @@
 package kernelrelease
 
-import p "github.com/maxgio92/krawler/pkg/packages"
+import (
+	"context"
+	"sync"
+
+	"github.com/maxgio92/krawler/pkg/output"
+	p "github.com/maxgio92/krawler/pkg/packages"
+)
+
+// GetKernelReleasesFromPackages builds the kernel releases from the packages, visiting the files
+// of up to parallelism packages at once. A non-positive parallelism is the default.
+// Packages whose files cannot be read are logged, and their releases are returned without compiler version.
+// The releases have the values of the kernel configuration options, and only the releases
+// whose configuration matches all the options' expected values are returned.
+func GetKernelReleasesFromPackages(ctx context.Context, packages []p.Package, logger *output.Logger,
+	parallelism int, options ...ConfigOption,
+) ([]KernelRelease, error) {
+	if parallelism <= 0 {
+		parallelism = p.DefaultParallelism
+	}
+
+	var (
+		wg      sync.WaitGroup
+		workers = make(chan struct{}, parallelism)
+		built   = make([]*KernelRelease, len(packages))
+	)
+
+	for i, v := range packages {
+		i, pkg := i, v
+
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+			// The releases being built are waited for, not to be written once returned.
+			wg.Wait()
+
+			return []KernelRelease{}, ctx.Err()
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			kr := &KernelRelease{}
+
+			if err := kr.BuildFromPackage(ctx, pkg, options...); err != nil && ctx.Err() == nil {
+				logger.WithField("url", pkg.URL()).WithError(err).Warn("Compiler version not available")
+			}
+
+			built[i] = kr
+		}()
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return []KernelRelease{}, err
+	}
 
-func GetKernelReleasesFromPackages(packages []p.Package, prefix string) ([]KernelRelease, error) {
 	releases := []KernelRelease{}
 
+	for _, kr := range built {
+		if kr.Fullversion != "" && kr.matches(options) {
+			releases = append(releases, *kr)
+		}
+	}
+
+	return unique(releases), nil
+}
+
+// FilterPackagesByRelease returns the packages of the kernel release, as matched by IsRelease
+// on the package metadata, for the files of the other packages not to be visited.
+func FilterPackagesByRelease(packages []p.Package, release string) []p.Package {
+	filtered := []p.Package{}
+
 	for _, pkg := range packages {
 		kr := &KernelRelease{}
+		kr.buildFromPackageMetadata(pkg)
 
-		err := kr.BuildFromPackage(pkg)
-		if err != nil {
-			return []KernelRelease{}, err
+		if kr.IsRelease(release) {
+			filtered = append(filtered, pkg)
 		}
+	}
 
-		if kr.Fullversion != "" {
-			releases = append(releases, *kr)
+	return filtered
+}
+
+func (k *KernelRelease) matches(options []ConfigOption) bool {
+	for _, o := range options {
+		if !o.Matches(k.Config) {
+			return false
 		}
 	}
 
-	return unique(releases), nil
+	return true
 }
 
 func unique(kernelReleases []KernelRelease) []KernelRelease {
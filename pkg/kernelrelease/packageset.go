@@ -0,0 +1,311 @@
+package kernelrelease
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	p "github.com/maxgio92/krawler/pkg/packages"
+)
+
+// The kinds of the packages of a kernel release.
+const (
+	PackageKindHeaders       = "headers"
+	PackageKindDevel         = "devel"
+	PackageKindCommonHeaders = "common-headers"
+	PackageKindKbuild        = "kbuild"
+	PackageKindImage         = "image"
+	PackageKindModules       = "modules"
+	PackageKindModulesExtra  = "modules-extra"
+	PackageKindDebugInfo     = "debuginfo"
+	PackageKindDependency    = "dependency"
+)
+
+// PackageKinds are the kinds of the packages of a kernel release, in order.
+var PackageKinds = []string{
+	PackageKindHeaders,
+	PackageKindDevel,
+	PackageKindCommonHeaders,
+	PackageKindKbuild,
+	PackageKindImage,
+	PackageKindModules,
+	PackageKindModulesExtra,
+	PackageKindDebugInfo,
+	PackageKindDependency,
+}
+
+// debPackagePrefix is the name prefix of the Debian and Ubuntu kernel headers packages,
+// followed by the kernel ABI and flavour (e.g. linux-headers-6.1.0-18-cloud-amd64).
+const debPackagePrefix = "linux-headers-"
+
+// The package architectures of the packages independent of the architecture.
+var noArchs = []string{"all", "noarch", "any"}
+
+// Artifact is a package of a kernel release, with the checksum and the size declared by the repository metadata.
+type Artifact struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Checksum string `json:"checksum,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+}
+
+// PackageSet is the set of the packages of a kernel release (e.g. the headers, the image and the modules).
+type PackageSet []Artifact
+
+// String returns the kinds of the packages (e.g. headers, image, modules).
+func (s PackageSet) String() string {
+	kinds := make([]string, 0, len(s))
+
+	for _, a := range s {
+		if !slices.Contains(kinds, a.Kind) {
+			kinds = append(kinds, a.Kind)
+		}
+	}
+
+	return strings.Join(kinds, ", ")
+}
+
+// SplitRelatedPackages returns the kernel packages with the name, and the packages related to them,
+// as searched by the related package names. The related package names are matched exactly, or as part
+// of the package names if they begin or end with a dash (e.g. linux-image-).
+// The packages the others depend on are related too, if independent of the architecture or without the name
+// (e.g. linux-headers-6.1.0-18-common of linux-headers-6.1.0-18-amd64).
+func SplitRelatedPackages(packages []p.Package, packageName string, relatedPackageNames []string) ([]p.Package, []p.Package) {
+	var kernel, related []p.Package
+
+	dependencies := make(map[string]bool)
+
+	for _, pkg := range packages {
+		if d, ok := pkg.(p.Dependent); ok {
+			for _, u := range d.GetDependencies() {
+				dependencies[u] = true
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		name := pkg.GetName()
+
+		isRelated := slices.ContainsFunc(relatedPackageNames, func(r string) bool {
+			if strings.HasPrefix(r, "-") || strings.HasSuffix(r, "-") {
+				return strings.Contains(name, r)
+			}
+
+			return name == r
+		})
+
+		isDependency := dependencies[pkg.URL()]
+
+		switch {
+		case (isRelated || isDependency) && !strings.Contains(name, packageName):
+			related = append(related, pkg)
+		case isDependency && slices.Contains(noArchs, pkg.GetArch()):
+			related = append(related, pkg)
+		default:
+			kernel = append(kernel, pkg)
+		}
+	}
+
+	return kernel, related
+}
+
+// ResolvePackageSets sets the package set of the kernel releases, from the packages of the same version
+// and architecture, or independent of the architecture, whose names are related to the release package name,
+// and from the packages the release package depends on, if declared.
+// The common headers are independent of the architecture.
+//
+//nolint:cyclop
+func ResolvePackageSets(releases []KernelRelease, packages []p.Package) {
+	byVersion := make(map[string][]p.Package)
+	byURL := make(map[string]p.Package)
+
+	for _, pkg := range packages {
+		version := versionFromPackage(pkg).String()
+		byVersion[version] = append(byVersion[version], pkg)
+		byURL[pkg.URL()] = pkg
+	}
+
+	for i := range releases {
+		k := &releases[i]
+		k.Packages = PackageSet{}
+
+		for _, pkg := range byVersion[k.PackageVersion.String()] {
+			if pkg.GetArch() != string(k.Architecture) && !slices.Contains(noArchs, pkg.GetArch()) {
+				continue
+			}
+
+			kind, ok := packageKind(k.PackageName, pkg.GetName())
+			if !ok || (pkg.GetName() == k.PackageName && pkg.URL() != k.PackageURL) {
+				continue
+			}
+
+			// The common headers are of all the architectures.
+			if kind == PackageKindCommonHeaders && !slices.Contains(noArchs, pkg.GetArch()) {
+				continue
+			}
+
+			k.Packages.add(newArtifact(kind, pkg), k.PackageURL)
+		}
+
+		// The dependencies are the ones of the release package, whatever their names and versions.
+		if d, ok := byURL[k.PackageURL].(p.Dependent); ok {
+			for _, u := range d.GetDependencies() {
+				pkg, ok := byURL[u]
+				if !ok {
+					continue
+				}
+
+				kind, ok := packageKind(k.PackageName, pkg.GetName())
+				if !ok {
+					kind = PackageKindDependency
+				}
+
+				k.Packages.add(newArtifact(kind, pkg), k.PackageURL)
+			}
+		}
+
+		sort.SliceStable(k.Packages, func(i, j int) bool {
+			ki, kj := slices.Index(PackageKinds, k.Packages[i].Kind), slices.Index(PackageKinds, k.Packages[j].Kind)
+			if ki != kj {
+				return ki < kj
+			}
+
+			return k.Packages[i].Name < k.Packages[j].Name
+		})
+	}
+}
+
+func newArtifact(kind string, pkg p.Package) Artifact {
+	a := Artifact{Kind: kind, Name: pkg.GetName(), URL: pkg.URL()}
+
+	if d, ok := pkg.(p.Downloadable); ok {
+		a.Checksum, a.Size = d.GetChecksum(), d.GetSize()
+	}
+
+	return a
+}
+
+// add adds the package to the set, unless already in it. The packages found on multiple mirrors
+// are preferred from the mirror of the release package.
+func (s *PackageSet) add(a Artifact, releaseURL string) {
+	j := slices.IndexFunc(*s, func(v Artifact) bool { return v.Name == a.Name })
+
+	switch {
+	case j < 0:
+		*s = append(*s, a)
+	case sameHost(a.URL, releaseURL) && !sameHost((*s)[j].URL, releaseURL):
+		(*s)[j] = a
+	}
+}
+
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return ua.Host == ub.Host
+}
+
+// packageKind returns the kind of the package with the name, and whether it's related to the kernel
+// release package with the release name.
+func packageKind(release, name string) (string, bool) {
+	if name == release {
+		if strings.Contains(release, PackageKindHeaders) {
+			return PackageKindHeaders, true
+		}
+
+		return PackageKindDevel, true
+	}
+
+	if strings.HasPrefix(release, debPackagePrefix) {
+		return debPackageKind(strings.TrimPrefix(release, debPackagePrefix), name)
+	}
+
+	return basePackageKind(release, name)
+}
+
+// debPackageKind returns the kind of the Debian or Ubuntu package related to the kernel headers package
+// of the kernel ABI and flavour (e.g. 6.1.0-18-cloud-amd64).
+//
+//nolint:cyclop
+func debPackageKind(flavour, name string) (string, bool) {
+	switch name {
+	case "linux-image-" + flavour, "linux-image-unsigned-" + flavour:
+		return PackageKindImage, true
+	case "linux-modules-" + flavour:
+		return PackageKindModules, true
+	case "linux-modules-extra-" + flavour:
+		return PackageKindModulesExtra, true
+	case "linux-image-" + flavour + "-dbg", "linux-image-" + flavour + "-dbgsym", "linux-image-unsigned-" + flavour + "-dbgsym":
+		return PackageKindDebugInfo, true
+	}
+
+	// The kbuild package is of the kernel version (e.g. linux-kbuild-6.1).
+	if strings.HasPrefix(name, "linux-kbuild-") {
+		version := strings.TrimPrefix(name, "linux-kbuild-")
+
+		return PackageKindKbuild, strings.HasPrefix(flavour, version+".") || strings.HasPrefix(flavour, version+"-")
+	}
+
+	// The common headers are of the kernel ABI (e.g. linux-headers-6.1.0-18-common, linux-aws-headers-5.15.0-1034),
+	// and the realtime ones of the realtime flavours only (e.g. linux-headers-6.1.0-18-common-rt).
+	if _, abi, ok := strings.Cut(name, "-headers-"); ok && strings.HasPrefix(name, "linux-") {
+		rt := strings.HasSuffix(abi, "-rt")
+		abi = strings.TrimSuffix(strings.TrimSuffix(abi, "-rt"), "-common")
+
+		return PackageKindCommonHeaders, strings.HasPrefix(flavour, abi+"-") && rt == strings.HasPrefix(flavour, abi+"-rt-")
+	}
+
+	return "", false
+}
+
+// basePackageKind returns the kind of the package related to the kernel headers or development package
+// of the other distributions, named after the kernel package (e.g. kernel-devel, linux-lts-dev).
+//
+//nolint:cyclop
+func basePackageKind(release, name string) (string, bool) {
+	base := release
+
+	for _, suffix := range []string{"-devel", "-dev", "-headers"} {
+		if strings.HasSuffix(release, suffix) {
+			base = strings.TrimSuffix(release, suffix)
+
+			break
+		}
+	}
+
+	switch name {
+	case base, base + "-core":
+		return PackageKindImage, true
+	case base + "-modules", base + "-modules-core":
+		return PackageKindModules, true
+	case base + "-modules-extra", base + "-extra":
+		return PackageKindModulesExtra, true
+	case base + "-headers":
+		return PackageKindHeaders, true
+	case base + "-devel", base + "-dev":
+		return PackageKindDevel, true
+	case base + "-debuginfo", base + "-dbg":
+		return PackageKindDebugInfo, true
+	}
+
+	if strings.HasPrefix(name, base+"-debuginfo-common") {
+		return PackageKindDebugInfo, true
+	}
+
+	// The common development files are of all the kernel flavours (e.g. kernel-devel of kernel-default-devel).
+	if strings.HasSuffix(name, "-devel") && strings.HasPrefix(release, strings.TrimSuffix(name, "-devel")+"-") {
+		return PackageKindCommonHeaders, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,196 @@
+package kernelrelease_test
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/kernelrelease"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+type testPackage struct {
+	name, version, release, arch, url string
+	dependencies                      []string
+}
+
+func (p *testPackage) GetName() string     { return p.name }
+func (p *testPackage) GetVersion() string  { return p.version }
+func (p *testPackage) GetRelease() string  { return p.release }
+func (p *testPackage) GetArch() string     { return p.arch }
+func (p *testPackage) GetLocation() string { return "" }
+func (p *testPackage) URL() string         { return p.url }
+func (p *testPackage) GetChecksum() string { return "sha256:" + p.name }
+func (p *testPackage) GetSize() int64      { return int64(len(p.name)) }
+
+func (p *testPackage) GetDependencies() []string { return p.dependencies }
+
+func (p *testPackage) Files(_ context.Context, _ packages.FileVisitor) error { return nil }
+
+func packageNames(ps []packages.Package) []string {
+	names := make([]string, 0, len(ps))
+
+	for _, p := range ps {
+		names = append(names, p.GetName())
+	}
+
+	return names
+}
+
+func artifactNames(releases []kernelrelease.KernelRelease) map[string][]string {
+	names := make(map[string][]string)
+
+	for _, k := range releases {
+		for _, a := range k.Packages {
+			names[k.PackageName] = append(names[k.PackageName], a.Kind+" "+a.Name)
+		}
+	}
+
+	return names
+}
+
+func TestSplitRelatedPackages(t *testing.T) {
+	t.Parallel()
+
+	ps := []packages.Package{
+		&testPackage{name: "linux-headers-6.1.0-18-amd64"},
+		&testPackage{name: "linux-headers-6.1.0-18-common"},
+		&testPackage{name: "linux-image-6.1.0-18-amd64"},
+		&testPackage{name: "linux-aws-headers-5.15.0-1034"},
+		&testPackage{name: "linux-kbuild-6.1"},
+	}
+
+	kernel, related := kernelrelease.SplitRelatedPackages(ps, "linux-headers", []string{"linux-image-", "linux-kbuild-", "-headers-"})
+	assert.DeepEqual(t, packageNames(kernel), []string{"linux-headers-6.1.0-18-amd64", "linux-headers-6.1.0-18-common"})
+	assert.DeepEqual(t, packageNames(related), []string{"linux-image-6.1.0-18-amd64", "linux-aws-headers-5.15.0-1034", "linux-kbuild-6.1"})
+
+	ps = []packages.Package{
+		&testPackage{name: "kernel-devel"},
+		&testPackage{name: "kernel-core"},
+		&testPackage{name: "linux-virt-dev"},
+	}
+
+	kernel, related = kernelrelease.SplitRelatedPackages(ps, "kernel-devel", []string{"kernel", "kernel-core", "kernel-devel"})
+	assert.DeepEqual(t, packageNames(kernel), []string{"kernel-devel", "linux-virt-dev"})
+	assert.DeepEqual(t, packageNames(related), []string{"kernel-core"})
+
+	// The dependencies are related, unless of the architecture and with the name.
+	ps = []packages.Package{
+		&testPackage{name: "linux-headers-generic", arch: "amd64", url: "generic", dependencies: []string{"6.8.0-31-generic"}},
+		&testPackage{name: "linux-headers-6.8.0-31-generic", arch: "amd64", url: "6.8.0-31-generic", dependencies: []string{"6.8.0-31"}},
+		&testPackage{name: "linux-headers-6.8.0-31", arch: "all", url: "6.8.0-31"},
+		&testPackage{name: "linux-tools-6.8.0-31", arch: "amd64", url: "tools"},
+	}
+
+	kernel, related = kernelrelease.SplitRelatedPackages(ps, "linux-headers", nil)
+	assert.DeepEqual(t, packageNames(kernel), []string{"linux-headers-generic", "linux-headers-6.8.0-31-generic", "linux-tools-6.8.0-31"})
+	assert.DeepEqual(t, packageNames(related), []string{"linux-headers-6.8.0-31"})
+}
+
+//nolint:funlen
+func TestResolvePackageSets(t *testing.T) {
+	t.Parallel()
+
+	const mirror, other = "https://mirror.example.com/", "https://other.example.com/"
+
+	ps := []packages.Package{
+		// RPM.
+		&testPackage{name: "kernel-devel", version: "5.14.0", release: "70.13.1.el9_0", arch: "x86_64", url: mirror + "kernel-devel.rpm"},
+		&testPackage{name: "kernel-core", version: "5.14.0", release: "70.13.1.el9_0", arch: "x86_64", url: other + "kernel-core.rpm"},
+		&testPackage{name: "kernel-core", version: "5.14.0", release: "70.13.1.el9_0", arch: "x86_64", url: mirror + "kernel-core.rpm"},
+		&testPackage{name: "kernel-core", version: "5.14.0", release: "70.13.1.el9_0", arch: "aarch64", url: mirror + "kernel-core-aarch64.rpm"},
+		&testPackage{name: "kernel-core", version: "5.14.0", release: "70.2.1.el9_0", arch: "x86_64", url: mirror + "kernel-core-old.rpm"},
+		&testPackage{name: "kernel-modules-extra", version: "5.14.0", release: "70.13.1.el9_0", arch: "x86_64", url: mirror + "kernel-modules-extra.rpm"},
+		&testPackage{name: "kernel-debuginfo-common-x86_64", version: "5.14.0", release: "70.13.1.el9_0", arch: "x86_64", url: mirror + "kernel-debuginfo-common.rpm"},
+		// openSUSE.
+		&testPackage{name: "kernel-default-devel", version: "5.14.21", release: "150500.55.19.1", arch: "x86_64", url: mirror + "kernel-default-devel.rpm"},
+		&testPackage{name: "kernel-devel", version: "5.14.21", release: "150500.55.19.1", arch: "noarch", url: mirror + "kernel-devel.noarch.rpm"},
+		&testPackage{name: "kernel-default", version: "5.14.21", release: "150500.55.19.1", arch: "x86_64", url: mirror + "kernel-default.rpm"},
+		// Debian.
+		&testPackage{name: "linux-headers-6.1.0-18-amd64", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-headers-amd64.deb"},
+		&testPackage{name: "linux-headers-6.1.0-18-cloud-amd64", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-headers-cloud-amd64.deb"},
+		&testPackage{name: "linux-headers-6.1.0-18-common", version: "6.1.76-1", arch: "all", url: mirror + "linux-headers-common.deb"},
+		&testPackage{name: "linux-image-6.1.0-18-amd64", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-image-amd64.deb"},
+		&testPackage{name: "linux-image-6.1.0-18-cloud-amd64", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-image-cloud-amd64.deb"},
+		&testPackage{name: "linux-image-6.1.0-18-amd64-dbg", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-image-amd64-dbg.deb"},
+		&testPackage{name: "linux-kbuild-6.1", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-kbuild.deb"},
+		&testPackage{name: "linux-image-amd64", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-image-meta.deb"},
+		// Debian, with dependencies.
+		&testPackage{name: "linux-headers-6.1.0-18-rt-amd64", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-headers-rt-amd64.deb", dependencies: []string{
+			mirror + "linux-headers-common-rt.deb", mirror + "linux-compiler.deb", mirror + "missing.deb",
+		}},
+		&testPackage{name: "linux-headers-6.1.0-18-common-rt", version: "6.1.76-1", arch: "all", url: mirror + "linux-headers-common-rt.deb"},
+		&testPackage{name: "linux-compiler-gcc-12-x86", version: "6.1.76-1", arch: "amd64", url: mirror + "linux-compiler.deb"},
+		// Ubuntu.
+		&testPackage{name: "linux-headers-5.15.0-1034-aws", version: "5.15.0-1034.38", arch: "amd64", url: mirror + "linux-headers-aws.deb"},
+		&testPackage{name: "linux-aws-headers-5.15.0-1034", version: "5.15.0-1034.38", arch: "all", url: mirror + "linux-aws-headers.deb"},
+		&testPackage{name: "linux-modules-5.15.0-1034-aws", version: "5.15.0-1034.38", arch: "amd64", url: mirror + "linux-modules-aws.deb"},
+	}
+
+	var releases []kernelrelease.KernelRelease
+
+	for _, pkg := range ps {
+		switch pkg.GetName() {
+		case "kernel-default-devel", "linux-headers-6.1.0-18-amd64", "linux-headers-6.1.0-18-cloud-amd64", "linux-headers-6.1.0-18-rt-amd64",
+			"linux-headers-5.15.0-1034-aws":
+		case "kernel-devel":
+			if pkg.GetArch() == "noarch" {
+				continue
+			}
+		default:
+			continue
+		}
+
+		k := kernelrelease.KernelRelease{}
+		assert.NilError(t, k.BuildFromPackage(context.Background(), pkg))
+
+		releases = append(releases, k)
+	}
+
+	kernelrelease.ResolvePackageSets(releases, ps)
+
+	assert.DeepEqual(t, artifactNames(releases), map[string][]string{
+		"kernel-devel": {
+			"devel kernel-devel",
+			"image kernel-core",
+			"modules-extra kernel-modules-extra",
+			"debuginfo kernel-debuginfo-common-x86_64",
+		},
+		"kernel-default-devel": {
+			"devel kernel-default-devel",
+			"common-headers kernel-devel",
+			"image kernel-default",
+		},
+		"linux-headers-6.1.0-18-amd64": {
+			"headers linux-headers-6.1.0-18-amd64",
+			"common-headers linux-headers-6.1.0-18-common",
+			"kbuild linux-kbuild-6.1",
+			"image linux-image-6.1.0-18-amd64",
+			"debuginfo linux-image-6.1.0-18-amd64-dbg",
+		},
+		"linux-headers-6.1.0-18-cloud-amd64": {
+			"headers linux-headers-6.1.0-18-cloud-amd64",
+			"common-headers linux-headers-6.1.0-18-common",
+			"kbuild linux-kbuild-6.1",
+			"image linux-image-6.1.0-18-cloud-amd64",
+		},
+		"linux-headers-6.1.0-18-rt-amd64": {
+			"headers linux-headers-6.1.0-18-rt-amd64",
+			"common-headers linux-headers-6.1.0-18-common-rt",
+			"kbuild linux-kbuild-6.1",
+			"dependency linux-compiler-gcc-12-x86",
+		},
+		"linux-headers-5.15.0-1034-aws": {
+			"headers linux-headers-5.15.0-1034-aws",
+			"common-headers linux-aws-headers-5.15.0-1034",
+			"modules linux-modules-5.15.0-1034-aws",
+		},
+	})
+
+	// The packages are preferred from the mirror of the release package.
+	assert.Equal(t, releases[0].Packages[1].URL, mirror+"kernel-core.rpm")
+	assert.Equal(t, releases[0].Packages[1].Checksum, "sha256:kernel-core")
+	assert.Equal(t, releases[0].Packages[1].Size, int64(len("kernel-core")))
+	assert.Equal(t, releases[0].Packages.String(), "devel, image, modules-extra, debuginfo")
+}
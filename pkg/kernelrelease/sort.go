@@ -0,0 +1,65 @@
+package kernelrelease
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortVersion sorts the kernel releases by package version.
+const SortVersion = "version"
+
+// FilterByVersion returns the kernel releases whose package version is between the minimum and
+// the maximum version included, if not nil. The versions with the bounds as prefix are included too
+// (e.g. 6.1.5-1 is included with the maximum version 6.1).
+func FilterByVersion(releases []KernelRelease, minVersion, maxVersion *Version) []KernelRelease {
+	krs := make([]KernelRelease, 0, len(releases))
+
+	for _, v := range releases {
+		if minVersion != nil && v.PackageVersion.Compare(*minVersion) < 0 && !v.PackageVersion.HasPrefix(*minVersion) {
+			continue
+		}
+
+		if maxVersion != nil && v.PackageVersion.Compare(*maxVersion) > 0 && !v.PackageVersion.HasPrefix(*maxVersion) {
+			continue
+		}
+
+		krs = append(krs, v)
+	}
+
+	return krs
+}
+
+// SortByVersion sorts the kernel releases by package version, from the oldest.
+func SortByVersion(releases []KernelRelease) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].PackageVersion.Compare(releases[j].PackageVersion) < 0
+	})
+}
+
+// LatestPerMinor returns the latest kernel release of each minor version (e.g. 6.1),
+// by architecture and flavour, in the order of the releases.
+func LatestPerMinor(releases []KernelRelease) []KernelRelease {
+	latest := make(map[string]int)
+	keys := []string{}
+
+	for i, v := range releases {
+		key := fmt.Sprintf("%d.%d/%s/%s", v.Version, v.PatchLevel, v.Architecture, v.Flavour)
+
+		j, ok := latest[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+
+		if !ok || v.PackageVersion.Compare(releases[j].PackageVersion) > 0 {
+			latest[key] = i
+		}
+	}
+
+	krs := make([]KernelRelease, 0, len(keys))
+
+	for _, key := range keys {
+		krs = append(krs, releases[latest[key]])
+	}
+
+	return krs
+}
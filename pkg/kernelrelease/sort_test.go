@@ -0,0 +1,57 @@
+package kernelrelease_test
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/kernelrelease"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func testRelease(version, patchLevel int, packageVersion string) kernelrelease.KernelRelease {
+	return kernelrelease.KernelRelease{
+		Version:        version,
+		PatchLevel:     patchLevel,
+		Architecture:   "amd64",
+		PackageVersion: kernelrelease.ParseVersion(packageVersion, packages.VersionSchemeDeb),
+	}
+}
+
+func packageVersions(releases []kernelrelease.KernelRelease) []string {
+	versions := make([]string, 0, len(releases))
+
+	for _, v := range releases {
+		versions = append(versions, v.PackageVersion.String())
+	}
+
+	return versions
+}
+
+func TestSortAndFilterByVersion(t *testing.T) {
+	t.Parallel()
+
+	releases := []kernelrelease.KernelRelease{
+		testRelease(6, 1, "6.1.76-1"),
+		testRelease(6, 10, "6.10.6-1~bpo12+1"),
+		testRelease(6, 1, "6.1.76-1~bpo11+1"),
+		testRelease(6, 1, "6.1.69-1"),
+		testRelease(5, 10, "5.10.209-2"),
+	}
+
+	kernelrelease.SortByVersion(releases)
+	assert.DeepEqual(t, packageVersions(releases), []string{
+		"5.10.209-2", "6.1.69-1", "6.1.76-1~bpo11+1", "6.1.76-1", "6.10.6-1~bpo12+1",
+	})
+
+	minVersion, maxVersion := kernelrelease.ParseVersion("6.1.70", ""), kernelrelease.ParseVersion("6.1", "")
+	assert.DeepEqual(t, packageVersions(kernelrelease.FilterByVersion(releases, &minVersion, &maxVersion)), []string{
+		"6.1.76-1~bpo11+1", "6.1.76-1",
+	})
+
+	assert.DeepEqual(t, packageVersions(kernelrelease.FilterByVersion(releases, nil, nil)), packageVersions(releases))
+
+	assert.DeepEqual(t, packageVersions(kernelrelease.LatestPerMinor(releases)), []string{
+		"5.10.209-2", "6.1.76-1", "6.10.6-1~bpo12+1",
+	})
+}
@@ -0,0 +1,308 @@
+package kernelrelease
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	p "github.com/maxgio92/krawler/pkg/packages"
+)
+
+// Compiler families.
+const (
+	CompilerGCC   = "gcc"
+	CompilerClang = "clang"
+)
+
+// Linkers.
+const (
+	LinkerBFD = "bfd"
+	LinkerLLD = "lld"
+)
+
+// Assemblers.
+const (
+	AssemblerGNU  = "gnu"
+	AssemblerLLVM = "llvm"
+)
+
+// The kernel configuration options about the toolchain.
+const (
+	ConfigCompilerIsGCC      = "CONFIG_CC_IS_GCC"
+	ConfigCompilerIsClang    = "CONFIG_CC_IS_CLANG"
+	ConfigClangVersion       = "CONFIG_CLANG_VERSION"
+	ConfigLinkerIsBFD        = "CONFIG_LD_IS_BFD"
+	ConfigLinkerIsLLD        = "CONFIG_LD_IS_LLD"
+	ConfigLinkerVersion      = "CONFIG_LD_VERSION"
+	ConfigLLDVersion         = "CONFIG_LLD_VERSION"
+	ConfigAssemblerIsGNU     = "CONFIG_AS_IS_GNU"
+	ConfigAssemblerIsLLVM    = "CONFIG_AS_IS_LLVM"
+	ConfigAssemblerVersion   = "CONFIG_AS_VERSION"
+	ConfigRustcVersion       = "CONFIG_RUSTC_VERSION"
+	ConfigRustcVersionText   = "CONFIG_RUSTC_VERSION_TEXT"
+	configCompilerTextHeader = "# Compiler: "
+)
+
+var (
+	clangVersionPattern = regexp.MustCompile(`clang version (\d+)\.(\d+)\.(\d+)`)
+	rustcVersionPattern = regexp.MustCompile(`rustc (\d+)\.(\d+)\.(\d+)`)
+	semverPattern       = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+)
+
+// Toolchain is the toolchain the kernel is built with, as declared by the kernel configuration,
+// or by the kernel compile header for kernels older than Linux 5.8.
+type Toolchain struct {
+	// The compiler family (i.e. gcc, clang).
+	Compiler string `json:"compiler"`
+
+	// The compiler semantic version (e.g. 12.2.0).
+	CompilerVersion string `json:"compiler_version"`
+
+	// The compiler version string, specific to the distribution (e.g. gcc-12 (Debian 12.2.0-14) 12.2.0).
+	CompilerText string `json:"compiler_text"`
+
+	// The linker (i.e. bfd, lld) and its semantic version.
+	Linker        string `json:"linker"`
+	LinkerVersion string `json:"linker_version"`
+
+	// The assembler (i.e. gnu, llvm) and its semantic version.
+	Assembler        string `json:"assembler"`
+	AssemblerVersion string `json:"assembler_version"`
+
+	// The Rust compiler semantic version, and version string, for kernels with Rust support.
+	RustcVersion string `json:"rustc_version"`
+	RustcText    string `json:"rustc_text"`
+}
+
+// String returns the compiler and linker, with their versions (e.g. gcc 12.2.0, bfd 2.40).
+func (t Toolchain) String() string {
+	var parts []string
+
+	if t.Compiler != "" {
+		parts = append(parts, strings.TrimSpace(t.Compiler+" "+t.CompilerVersion))
+	}
+
+	if t.Linker != "" {
+		parts = append(parts, strings.TrimSpace(t.Linker+" "+t.LinkerVersion))
+	}
+
+	if t.RustcVersion != "" {
+		parts = append(parts, "rustc "+t.RustcVersion)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// GCCVersion returns the GCC version in the format of CONFIG_GCC_VERSION (e.g. 12.2.0 is 120200),
+// or an empty string if the kernel is not built with GCC.
+func (t Toolchain) GCCVersion() string {
+	if t.Compiler != CompilerGCC {
+		return ""
+	}
+
+	match := semverPattern.FindStringSubmatch(t.CompilerVersion)
+	if match == nil {
+		return ""
+	}
+
+	version := 0
+
+	for _, v := range match[1:] {
+		n, _ := strconv.Atoi(v)
+		version = version*100 + n
+	}
+
+	return strconv.Itoa(version)
+}
+
+// GetToolchainFromKernelPackage returns the toolchain from the kernel configuration, or otherwise
+// the kernel compile header, shipped with the package.
+// The package files are visited until a kernel configuration declaring the compiler is found.
+func GetToolchainFromKernelPackage(ctx context.Context, pkg p.Package) (Toolchain, error) {
+	toolchain, _, err := readKernelFiles(ctx, pkg)
+
+	return toolchain, err
+}
+
+// toolchainParser parses the toolchain from the lines of the kernel configuration or compile header file.
+type toolchainParser struct {
+	t Toolchain
+
+	// Whether the compiler is declared by the kernel configuration.
+	fromConfig bool
+}
+
+//nolint:cyclop
+func (tp *toolchainParser) parseLine(line string) {
+	t := &tp.t
+
+	if strings.HasPrefix(line, configCompilerTextHeader) && t.CompilerText == "" {
+		// Kernel configurations older than Linux 5.8 declare the compiler in the header.
+		t.CompilerText = strings.TrimPrefix(line, configCompilerTextHeader)
+
+		return
+	}
+
+	if strings.HasPrefix(line, "#define "+CompileHeaderCompiler+" ") {
+		compiler, linker, _ := strings.Cut(unquote(strings.TrimPrefix(line, "#define "+CompileHeaderCompiler+" ")), ", ")
+		t.CompilerText = compiler
+		t.Linker, t.LinkerVersion = linkerFromText(linker)
+
+		return
+	}
+
+	key, value, ok := parseConfigLine(line)
+	if !ok || value == ConfigNotSet {
+		return
+	}
+
+	switch key {
+	case ConfigCompilerVersionText:
+		t.CompilerText = value
+	case ConfigCompilerIsGCC:
+		t.Compiler, tp.fromConfig = CompilerGCC, true
+	case ConfigCompilerIsClang:
+		t.Compiler, tp.fromConfig = CompilerClang, true
+	case ConfigCompilerVersion:
+		if v := versionFromConfig(value); v != "" {
+			t.Compiler, t.CompilerVersion, tp.fromConfig = CompilerGCC, v, true
+		}
+	case ConfigClangVersion:
+		if v := versionFromConfig(value); v != "" {
+			t.Compiler, t.CompilerVersion, tp.fromConfig = CompilerClang, v, true
+		}
+	case ConfigLinkerIsBFD:
+		t.Linker = LinkerBFD
+	case ConfigLinkerIsLLD:
+		t.Linker = LinkerLLD
+	case ConfigLinkerVersion, ConfigLLDVersion:
+		if v := linkerVersionFromConfig(value); v != "" {
+			t.LinkerVersion = v
+		}
+	case ConfigAssemblerIsGNU:
+		t.Assembler = AssemblerGNU
+	case ConfigAssemblerIsLLVM:
+		t.Assembler = AssemblerLLVM
+	case ConfigAssemblerVersion:
+		t.AssemblerVersion = versionFromConfig(value)
+	case ConfigRustcVersion:
+		t.RustcVersion = versionFromConfig(value)
+	case ConfigRustcVersionText:
+		t.RustcText = value
+	}
+}
+
+// toolchain returns the toolchain parsed, reading the versions not declared as numbers
+// from the version strings.
+func (tp *toolchainParser) toolchain() Toolchain {
+	t := tp.t
+
+	if t.Compiler == "" || t.CompilerVersion == "" {
+		compiler, version := compilerFromText(t.CompilerText)
+
+		if t.Compiler == "" {
+			t.Compiler = compiler
+		}
+
+		if t.Compiler == compiler {
+			t.CompilerVersion = version
+		}
+	}
+
+	if t.RustcVersion == "" {
+		t.RustcVersion = versionFromPattern(rustcVersionPattern, t.RustcText)
+	}
+
+	return t
+}
+
+// compilerFromText returns the compiler family and semantic version from the compiler version string.
+func compilerFromText(text string) (string, string) {
+	if v := versionFromPattern(clangVersionPattern, text); v != "" {
+		return CompilerClang, v
+	}
+
+	if v := versionFromPattern(gccVersionPattern, text); v != "" {
+		return CompilerGCC, v
+	}
+
+	return "", ""
+}
+
+// linkerFromText returns the linker and its semantic version from the linker version string
+// (e.g. GNU ld (GNU Binutils for Debian) 2.35.2, LLD 14.0.6).
+func linkerFromText(text string) (string, string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	version := semverPattern.FindString(fields[len(fields)-1])
+
+	switch {
+	case strings.HasPrefix(text, "GNU ld"):
+		return LinkerBFD, version
+	case strings.HasPrefix(text, "LLD"), strings.Contains(text, " LLD "):
+		return LinkerLLD, version
+	default:
+		return "", ""
+	}
+}
+
+// versionFromConfig returns the semantic version from a numeric version of the kernel configuration
+// (e.g. 120200 is 12.2.0), or an empty string if not set.
+func versionFromConfig(value string) string {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d.%d.%d", n/10000, n/100%100, n%100)
+}
+
+// linkerVersionFromConfig returns the semantic version from the numeric linker version of the kernel configuration.
+// Kernels older than Linux 5.12 have a different format (e.g. 235000000 is 2.35.0).
+func linkerVersionFromConfig(value string) string {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return ""
+	}
+
+	//nolint:gomnd
+	if n >= 100000000 {
+		return fmt.Sprintf("%d.%d.%d", n/100000000, n/1000000%100, n/10000%100)
+	}
+
+	return versionFromConfig(value)
+}
+
+// versionFromPattern returns the semantic version matched by the pattern in the text,
+// or an empty string if not matched.
+func versionFromPattern(pattern *regexp.Regexp, text string) string {
+	match := pattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+
+	version := make([]string, 0, len(match)-1)
+
+	for _, v := range match[1:] {
+		if v == "" {
+			v = "0"
+		}
+
+		version = append(version, v)
+	}
+
+	return strings.Join(version, ".")
+}
+
+func unquote(value string) string {
+	if v, err := strconv.Unquote(strings.TrimSpace(value)); err == nil {
+		return v
+	}
+
+	return value
+}
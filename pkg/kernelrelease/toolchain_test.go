@@ -0,0 +1,136 @@
+package kernelrelease_test
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/kernelrelease"
+)
+
+//nolint:funlen
+func TestGetToolchainFromKernelPackage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		files []string
+		want  kernelrelease.Toolchain
+	}{
+		"GCC and binutils": {
+			files: []string{`CONFIG_CC_VERSION_TEXT="gcc-12 (Debian 12.2.0-14) 12.2.0"
+CONFIG_CC_IS_GCC=y
+CONFIG_GCC_VERSION=120200
+CONFIG_CLANG_VERSION=0
+CONFIG_AS_IS_GNU=y
+CONFIG_AS_VERSION=24000
+CONFIG_LD_IS_BFD=y
+CONFIG_LD_VERSION=24000
+CONFIG_LLD_VERSION=0
+`},
+			want: kernelrelease.Toolchain{
+				Compiler:         kernelrelease.CompilerGCC,
+				CompilerVersion:  "12.2.0",
+				CompilerText:     "gcc-12 (Debian 12.2.0-14) 12.2.0",
+				Linker:           kernelrelease.LinkerBFD,
+				LinkerVersion:    "2.40.0",
+				Assembler:        kernelrelease.AssemblerGNU,
+				AssemblerVersion: "2.40.0",
+			},
+		},
+		"Clang, LLD and Rust": {
+			files: []string{`CONFIG_CC_VERSION_TEXT="Android (11368308, +pgo, +bolt, +lto, +mlgo, based on r510928) clang version 18.0.0"
+CONFIG_GCC_VERSION=0
+CONFIG_CC_IS_CLANG=y
+CONFIG_CLANG_VERSION=180000
+CONFIG_AS_IS_LLVM=y
+CONFIG_AS_VERSION=180000
+CONFIG_LD_VERSION=0
+CONFIG_LD_IS_LLD=y
+CONFIG_LLD_VERSION=180000
+CONFIG_RUSTC_VERSION_TEXT="rustc 1.73.0 (cc66ad468 2023-10-03)"
+`},
+			want: kernelrelease.Toolchain{
+				Compiler:         kernelrelease.CompilerClang,
+				CompilerVersion:  "18.0.0",
+				CompilerText:     "Android (11368308, +pgo, +bolt, +lto, +mlgo, based on r510928) clang version 18.0.0",
+				Linker:           kernelrelease.LinkerLLD,
+				LinkerVersion:    "18.0.0",
+				Assembler:        kernelrelease.AssemblerLLVM,
+				AssemblerVersion: "18.0.0",
+				RustcVersion:     "1.73.0",
+				RustcText:        "rustc 1.73.0 (cc66ad468 2023-10-03)",
+			},
+		},
+		"configuration older than Linux 5.8": {
+			files: []string{`#
+# Automatically generated file; DO NOT EDIT.
+# Linux/x86 4.19.0 Kernel Configuration
+# Compiler: gcc (GCC) 8.3.1 20190311 (Red Hat 8.3.1-3)
+#
+CONFIG_CC_IS_GCC=y
+CONFIG_GCC_VERSION=80301
+CONFIG_CLANG_VERSION=0
+`},
+			want: kernelrelease.Toolchain{
+				Compiler:        kernelrelease.CompilerGCC,
+				CompilerVersion: "8.3.1",
+				CompilerText:    "gcc (GCC) 8.3.1 20190311 (Red Hat 8.3.1-3)",
+			},
+		},
+		"compile header": {
+			files: []string{
+				"#define LINUX_COMPILER \"gcc-10 (Debian 10.2.1-6) 10.2.1 20210110, GNU ld (GNU Binutils for Debian) 2.35.2\"\n",
+			},
+			want: kernelrelease.Toolchain{
+				Compiler:        kernelrelease.CompilerGCC,
+				CompilerVersion: "10.2.1",
+				CompilerText:    "gcc-10 (Debian 10.2.1-6) 10.2.1 20210110",
+				Linker:          kernelrelease.LinkerBFD,
+				LinkerVersion:   "2.35.2",
+			},
+		},
+		"configuration preferred to compile header": {
+			files: []string{
+				"#define LINUX_COMPILER \"clang version 14.0.6, LLD 14.0.6\"\n",
+				"CONFIG_CC_IS_GCC=y\nCONFIG_GCC_VERSION=120200\n",
+			},
+			want: kernelrelease.Toolchain{
+				Compiler:        kernelrelease.CompilerGCC,
+				CompilerVersion: "12.2.0",
+			},
+		},
+		"binutils version older than Linux 5.12": {
+			files: []string{"CONFIG_GCC_VERSION=100201\nCONFIG_LD_VERSION=235020000\n"},
+			want: kernelrelease.Toolchain{
+				Compiler:        kernelrelease.CompilerGCC,
+				CompilerVersion: "10.2.1",
+				LinkerVersion:   "2.35.2",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := kernelrelease.GetToolchainFromKernelPackage(context.Background(), &testFilePackage{files: tt.files})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestToolchainGCCVersion(t *testing.T) {
+	t.Parallel()
+
+	gcc := kernelrelease.Toolchain{Compiler: kernelrelease.CompilerGCC, CompilerVersion: "12.2.0", Linker: kernelrelease.LinkerBFD, LinkerVersion: "2.40.0"}
+	assert.Equal(t, gcc.GCCVersion(), "120200")
+	assert.Equal(t, gcc.String(), "gcc 12.2.0, bfd 2.40.0")
+
+	clang := kernelrelease.Toolchain{Compiler: kernelrelease.CompilerClang, CompilerVersion: "18.0.0"}
+	assert.Equal(t, clang.GCCVersion(), "")
+	assert.Equal(t, clang.String(), "clang 18.0.0")
+}
@@ -2,10 +2,104 @@ package kernelrelease
 
 import (
 	"fmt"
+	"strings"
 
 	p "github.com/maxgio92/krawler/pkg/packages"
 )
 
+// Version is a package version, in the form [epoch:]version[-release], compared with the scheme
+// of its package manager (i.e. rpm, deb, alpm). Versions without scheme are compared as RPM versions.
+type Version struct {
+	Epoch   string `json:"epoch"`
+	Version string `json:"version"`
+	Release string `json:"release"`
+	Scheme  string `json:"scheme"`
+}
+
+// ParseVersion parses the version in the form [epoch:]version[-release], with the scheme.
+func ParseVersion(version, scheme string) Version {
+	v := Version{Scheme: scheme}
+
+	if epoch, rest, ok := strings.Cut(version, ":"); ok && epoch != "" && strings.Trim(epoch, "0123456789") == "" {
+		v.Epoch, version = epoch, rest
+	}
+
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		v.Version, v.Release = version[:i], version[i+1:]
+	} else {
+		v.Version = version
+	}
+
+	return v
+}
+
+// String returns the version in the form [epoch:]version[-release].
+func (v Version) String() string {
+	s := v.Version
+
+	if v.Epoch != "" {
+		s = v.Epoch + ":" + s
+	}
+
+	if v.Release != "" {
+		s += "-" + v.Release
+	}
+
+	return s
+}
+
+// Compare returns -1, 0 or +1 whether v is older, the same or newer than o, with the scheme of v.
+// Like RPM and Arch Linux dependencies, the releases are compared only if both versions have one,
+// while a missing Debian revision is older than any.
+func (v Version) Compare(o Version) int {
+	cmp := rpmvercmp
+
+	switch v.Scheme {
+	case p.VersionSchemeDeb:
+		cmp = verrevcmp
+	case p.VersionSchemeAlpm:
+		cmp = alpmvercmp
+	}
+
+	if c := cmp(epochOrZero(v.Epoch), epochOrZero(o.Epoch)); c != 0 {
+		return c
+	}
+
+	if c := cmp(v.Version, o.Version); c != 0 {
+		return c
+	}
+
+	if v.Scheme != p.VersionSchemeDeb && (v.Release == "" || o.Release == "") {
+		return 0
+	}
+
+	return cmp(v.Release, o.Release)
+}
+
+// HasPrefix returns whether the version is the prefix, or one of its versions
+// (e.g. 6.1.5-1 has the prefix 6.1, but 6.10 does not).
+func (v Version) HasPrefix(prefix Version) bool {
+	if epochOrZero(v.Epoch) != epochOrZero(prefix.Epoch) {
+		return false
+	}
+
+	s := v.Version
+	if prefix.Release != "" {
+		s = v.Version + "-" + v.Release
+	}
+
+	pv := prefix.Version
+	if prefix.Release != "" {
+		pv += "-" + prefix.Release
+	}
+
+	if !strings.HasPrefix(s, pv) {
+		return false
+	}
+
+	return len(s) == len(pv) || !isAlnum(s[len(pv)])
+}
+
 func versionStringFromPackage(pkg p.Package) string {
 	version := pkg.GetVersion()
 	if pkg.GetRelease() != "" {
@@ -18,3 +112,295 @@ func versionStringFromPackage(pkg p.Package) string {
 
 	return version
 }
+
+// versionFromPackage returns the version of the package, with the epoch if declared apart
+// and the scheme of its package manager.
+func versionFromPackage(pkg p.Package) Version {
+	version := pkg.GetVersion()
+	if pkg.GetRelease() != "" {
+		version += "-" + pkg.GetRelease()
+	}
+
+	var scheme string
+	if v, ok := pkg.(p.Versioned); ok {
+		scheme = v.GetVersionScheme()
+	}
+
+	v := ParseVersion(version, scheme)
+
+	// The zero epoch is the default one, and it's omitted.
+	if e, ok := pkg.(p.Epoched); ok && epochOrZero(e.GetEpoch()) != "0" {
+		v.Epoch = e.GetEpoch()
+	}
+
+	return v
+}
+
+func epochOrZero(epoch string) string {
+	if epoch == "" {
+		return "0"
+	}
+
+	return epoch
+}
+
+// rpmvercmp compares the versions as RPM does, by segments of digits or letters,
+// where the tilde sorts before anything and the caret after the version only.
+//
+//nolint:cyclop,gocognit
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		// The tilde sorts before anything, even the end of the version.
+		if at(a, i) == '~' || at(b, j) == '~' {
+			if at(a, i) != '~' {
+				return 1
+			}
+
+			if at(b, j) != '~' {
+				return -1
+			}
+
+			i, j = i+1, j+1
+
+			continue
+		}
+
+		// The caret sorts after the end of the version only.
+		if at(a, i) == '^' || at(b, j) == '^' {
+			switch {
+			case i == len(a):
+				return -1
+			case j == len(b):
+				return 1
+			case a[i] != '^':
+				return 1
+			case b[j] != '^':
+				return -1
+			}
+
+			i, j = i+1, j+1
+
+			continue
+		}
+
+		if i == len(a) || j == len(b) {
+			break
+		}
+
+		if c, ok := compareSegments(a, b, &i, &j); !ok || c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case i == len(a) && j == len(b):
+		return 0
+	case i == len(a):
+		return -1
+	default:
+		return 1
+	}
+}
+
+// alpmvercmp compares the versions as pacman's vercmp does, by segments of digits or letters,
+// where an alphabetic segment sorts before the end of the version.
+//
+//nolint:cyclop
+func alpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		si, sj := i, j
+
+		for i < len(a) && !isAlnum(a[i]) {
+			i++
+		}
+
+		for j < len(b) && !isAlnum(b[j]) {
+			j++
+		}
+
+		if i == len(a) || j == len(b) {
+			break
+		}
+
+		// The segments are separated by a different number of characters.
+		if i-si != j-sj {
+			if i-si < j-sj {
+				return -1
+			}
+
+			return 1
+		}
+
+		if c, ok := compareSegments(a, b, &i, &j); !ok || c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case i == len(a) && j == len(b):
+		return 0
+	case i == len(a) && !isAlpha(at(b, j)), isAlpha(at(a, i)):
+		return -1
+	default:
+		return 1
+	}
+}
+
+// compareSegments compares the segments of digits or letters of the versions at i and j,
+// and moves them after the segments. The result is not ok, and returned, if the segments
+// are of different types, where numbers are newer than letters.
+func compareSegments(a, b string, i, j *int) (int, bool) {
+	isNum := isDigit(a[*i])
+	isSegment := isAlpha
+
+	if isNum {
+		isSegment = isDigit
+	}
+
+	si, sj := *i, *j
+
+	for *i < len(a) && isSegment(a[*i]) {
+		*i++
+	}
+
+	for *j < len(b) && isSegment(b[*j]) {
+		*j++
+	}
+
+	sa, sb := a[si:*i], b[sj:*j]
+
+	if sb == "" {
+		if isNum {
+			return 1, false
+		}
+
+		return -1, false
+	}
+
+	if isNum {
+		sa, sb = strings.TrimLeft(sa, "0"), strings.TrimLeft(sb, "0")
+
+		if len(sa) != len(sb) {
+			if len(sa) < len(sb) {
+				return -1, true
+			}
+
+			return 1, true
+		}
+	}
+
+	return strings.Compare(sa, sb), true
+}
+
+// verrevcmp compares the versions as dpkg does, by alternating non-digit parts, compared
+// by characters where letters sort before non-letters and the tilde before anything,
+// and numbers.
+//
+//nolint:cyclop
+func verrevcmp(a, b string) int {
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			if ac, bc := dpkgOrder(at(a, i)), dpkgOrder(at(b, j)); ac != bc {
+				return sign(ac - bc)
+			}
+
+			i, j = i+1, j+1
+		}
+
+		for at(a, i) == '0' {
+			i++
+		}
+
+		for at(b, j) == '0' {
+			j++
+		}
+
+		firstDiff := 0
+
+		for isDigit(at(a, i)) && isDigit(at(b, j)) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+
+			i, j = i+1, j+1
+		}
+
+		if isDigit(at(a, i)) {
+			return 1
+		}
+
+		if isDigit(at(b, j)) {
+			return -1
+		}
+
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+
+	return 0
+}
+
+// dpkgOrder returns the weight of the character in the non-digit parts of Debian versions.
+func dpkgOrder(c byte) int {
+	switch {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	case c != 0:
+		return int(c) + 256 //nolint:gomnd
+	default:
+		return 0
+	}
+}
+
+// at returns the character of the version at i, or 0 past its end.
+func at(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+
+	return 0
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isAlpha(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+func isAlnum(c byte) bool { return isDigit(c) || isAlpha(c) }
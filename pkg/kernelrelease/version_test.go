@@ -0,0 +1,148 @@
+package kernelrelease_test
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/kernelrelease"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	v := kernelrelease.ParseVersion("1:6.1.76-1~bpo11+1", packages.VersionSchemeDeb)
+	assert.DeepEqual(t, v, kernelrelease.Version{Epoch: "1", Version: "6.1.76", Release: "1~bpo11+1", Scheme: packages.VersionSchemeDeb})
+	assert.Equal(t, v.String(), "1:6.1.76-1~bpo11+1")
+
+	v = kernelrelease.ParseVersion("6.1", "")
+	assert.DeepEqual(t, v, kernelrelease.Version{Version: "6.1"})
+	assert.Equal(t, v.String(), "6.1")
+}
+
+//nolint:funlen
+func TestVersionCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		scheme string
+		a, b   string
+		want   int
+	}{
+		"rpm same":                       {scheme: packages.VersionSchemeRPM, a: "4.18.0-331.el8", b: "4.18.0-331.el8", want: 0},
+		"rpm numeric segments":           {scheme: packages.VersionSchemeRPM, a: "5.14.0-70.13.1.el9_0", b: "5.14.0-70.2.1.el9_0", want: 1},
+		"rpm release":                    {scheme: packages.VersionSchemeRPM, a: "4.18.0-80.el8", b: "4.18.0-331.el8", want: -1},
+		"rpm leading zeros":              {scheme: packages.VersionSchemeRPM, a: "1.0010", b: "1.9", want: 1},
+		"rpm separators":                 {scheme: packages.VersionSchemeRPM, a: "2.0", b: "2_0", want: 0},
+		"rpm letters after the end":      {scheme: packages.VersionSchemeRPM, a: "1.0a", b: "1.0", want: 1},
+		"rpm numbers newer":              {scheme: packages.VersionSchemeRPM, a: "1.0.a", b: "1.0.1", want: -1},
+		"rpm tilde":                      {scheme: packages.VersionSchemeRPM, a: "6.1~rc1", b: "6.1", want: -1},
+		"rpm tildes":                     {scheme: packages.VersionSchemeRPM, a: "6.1~rc1", b: "6.1~rc2", want: -1},
+		"rpm caret":                      {scheme: packages.VersionSchemeRPM, a: "1.0^git1", b: "1.0", want: 1},
+		"rpm caret before segment":       {scheme: packages.VersionSchemeRPM, a: "1.0^git1", b: "1.0.1", want: -1},
+		"rpm missing release":            {scheme: packages.VersionSchemeRPM, a: "4.18.0-331.el8", b: "4.18.0", want: 0},
+		"rpm epoch":                      {scheme: packages.VersionSchemeRPM, a: "1:4.18.0-80.el8", b: "4.19.0-1", want: 1},
+		"without scheme as rpm":          {scheme: "", a: "6.1~rc1", b: "6.1", want: -1},
+		"deb same":                       {scheme: packages.VersionSchemeDeb, a: "6.1.76-1", b: "6.1.76-1", want: 0},
+		"deb tilde":                      {scheme: packages.VersionSchemeDeb, a: "6.1.76-1~bpo11+1", b: "6.1.76-1", want: -1},
+		"deb plus":                       {scheme: packages.VersionSchemeDeb, a: "4.19+105+deb10u4", b: "4.19+105", want: 1},
+		"deb letters before non-letters": {scheme: packages.VersionSchemeDeb, a: "1.0a", b: "1.0+", want: -1},
+		"deb numbers":                    {scheme: packages.VersionSchemeDeb, a: "5.15.0-1034.38", b: "5.15.0-101.111", want: 1},
+		"deb missing revision":           {scheme: packages.VersionSchemeDeb, a: "6.1.76", b: "6.1.76-1", want: -1},
+		"deb epoch":                      {scheme: packages.VersionSchemeDeb, a: "1:3.16.0-10", b: "6.1.76-1", want: 1},
+		"alpm same":                      {scheme: packages.VersionSchemeAlpm, a: "6.1.5.arch2-1", b: "6.1.5.arch2-1", want: 0},
+		"alpm letters":                   {scheme: packages.VersionSchemeAlpm, a: "6.1.5.arch2-1", b: "6.1.5.arch1-2", want: 1},
+		"alpm letters before the end":    {scheme: packages.VersionSchemeAlpm, a: "1.0a", b: "1.0", want: -1},
+		"alpm dotted letters":            {scheme: packages.VersionSchemeAlpm, a: "1.0.a", b: "1.0", want: 1},
+		"alpm separators":                {scheme: packages.VersionSchemeAlpm, a: "1.0.1", b: "1.0a", want: 1},
+		"alpm release":                   {scheme: packages.VersionSchemeAlpm, a: "6.1.5-2", b: "6.1.5-10", want: -1},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := kernelrelease.ParseVersion(tt.a, tt.scheme), kernelrelease.ParseVersion(tt.b, tt.scheme)
+			assert.Equal(t, a.Compare(b), tt.want)
+			assert.Equal(t, b.Compare(a), -tt.want)
+		})
+	}
+}
+
+func TestVersionHasPrefix(t *testing.T) {
+	t.Parallel()
+
+	v := kernelrelease.ParseVersion("6.1.5-1", packages.VersionSchemeRPM)
+
+	assert.Assert(t, v.HasPrefix(kernelrelease.ParseVersion("6.1", "")))
+	assert.Assert(t, v.HasPrefix(kernelrelease.ParseVersion("6.1.5", "")))
+	assert.Assert(t, v.HasPrefix(kernelrelease.ParseVersion("6.1.5-1", "")))
+	assert.Assert(t, !v.HasPrefix(kernelrelease.ParseVersion("6.10", "")))
+	assert.Assert(t, !v.HasPrefix(kernelrelease.ParseVersion("6.1.50", "")))
+	assert.Assert(t, !v.HasPrefix(kernelrelease.ParseVersion("1:6.1", "")))
+}
+
+type testEpochPackage struct {
+	testPackage
+	epoch string
+}
+
+func (p *testEpochPackage) GetEpoch() string         { return p.epoch }
+func (p *testEpochPackage) GetVersionScheme() string { return packages.VersionSchemeRPM }
+
+func TestVersionFromPackageEpoch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		epoch string
+		want  kernelrelease.Version
+	}{
+		"epoch": {
+			epoch: "1",
+			want:  kernelrelease.Version{Epoch: "1", Version: "5.14.0", Release: "70.el9", Scheme: packages.VersionSchemeRPM},
+		},
+		"zero epoch": {
+			epoch: "0",
+			want:  kernelrelease.Version{Version: "5.14.0", Release: "70.el9", Scheme: packages.VersionSchemeRPM},
+		},
+		"no epoch": {
+			want: kernelrelease.Version{Version: "5.14.0", Release: "70.el9", Scheme: packages.VersionSchemeRPM},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var k kernelrelease.KernelRelease
+
+			assert.NilError(t, k.BuildFromPackage(context.Background(), &testEpochPackage{
+				testPackage: testPackage{name: "kernel-devel", version: "5.14.0", release: "70.el9", arch: "x86_64"},
+				epoch:       tt.epoch,
+			}))
+
+			assert.DeepEqual(t, k.PackageVersion, tt.want)
+
+			// The kernel version is not affected by the epoch.
+			assert.Equal(t, k.Fullversion, "5.14.0")
+		})
+	}
+
+	// The epoch orders the versions before the version itself.
+	var newer, older kernelrelease.KernelRelease
+
+	assert.NilError(t, newer.BuildFromPackage(context.Background(), &testEpochPackage{
+		testPackage: testPackage{name: "kernel-devel", version: "4.18.0", release: "80.el8", arch: "x86_64"},
+		epoch:       "1",
+	}))
+	assert.NilError(t, older.BuildFromPackage(context.Background(), &testEpochPackage{
+		testPackage: testPackage{name: "kernel-devel", version: "5.14.0", release: "70.el9", arch: "x86_64"},
+	}))
+	assert.Equal(t, newer.PackageVersion.Compare(older.PackageVersion), 1)
+}
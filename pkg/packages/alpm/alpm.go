@@ -6,6 +6,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,7 +18,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 
+	"github.com/maxgio92/krawler/pkg/fetch"
 	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/signature"
 )
 
 type Package struct {
@@ -27,29 +30,47 @@ type Package struct {
 	Architecture string
 	Location     string
 	url          string
-	fileReaders  []io.Reader
-	Files        []string
+
+	// The SHA256 checksum and the size declared by the repository DB.
+	checksum string
+	size     int64
 }
 
-func (p *Package) GetName() string          { return p.Name }
-func (p *Package) GetVersion() string       { return p.Version }
-func (p *Package) GetRelease() string       { return p.Release }
-func (p *Package) GetArch() string          { return p.Architecture }
-func (p *Package) GetLocation() string      { return p.Location }
-func (p *Package) URL() string              { return p.url }
-func (p *Package) FileReaders() []io.Reader { return p.fileReaders }
+func (p *Package) GetName() string     { return p.Name }
+func (p *Package) GetVersion() string  { return p.Version }
+func (p *Package) GetRelease() string  { return p.Release }
+func (p *Package) GetArch() string     { return p.Architecture }
+func (p *Package) GetLocation() string { return p.Location }
+func (p *Package) URL() string         { return p.url }
+
+func (p *Package) GetVersionScheme() string { return packages.VersionSchemeAlpm }
+func (p *Package) GetSize() int64           { return p.size }
+
+func (p *Package) GetChecksum() string {
+	if p.checksum == "" {
+		return ""
+	}
+
+	return "sha256:" + p.checksum
+}
+
+// Files visits no files, as the package files are not looked for.
+func (p *Package) Files(_ context.Context, _ packages.FileVisitor) error { return nil }
 
 const (
 	root              = "/"
 	ALPMDBVersionFile = "ALPM_DB_VERSION"
 	ALPMDBVersion     = 9
+	DBSignatureSuffix = ".sig"
 )
 
-func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
+// SearchPackages searches the packages from the repository DBs, until ctx is done.
+func SearchPackages(ctx context.Context, so *SearchOptions) ([]packages.Package, error) {
 	var result []packages.Package
 
 	search := func(dbURL string) {
 		searchPackagesFromDB(
+			ctx,
 			func() {
 				so.Progress(1)
 				so.SigProducerCompletion()
@@ -75,7 +96,7 @@ func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
 	// Run search producers.
 	for _, v := range so.SeedURLs() {
 		dbURL := v
-		go search(dbURL)
+		so.Go(ctx, func() { search(dbURL) })
 	}
 
 	// Run collect consumer.
@@ -84,23 +105,34 @@ func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
 	// Wait for producers and consumers to complete and cleanup.
 	so.WaitAndClose()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
-func searchPackagesFromDB(doneFunc func(), so *SearchOptions, dbURL string) {
+func searchPackagesFromDB(ctx context.Context, doneFunc func(), so *SearchOptions, dbURL string) {
 	defer doneFunc()
 
-	p, err := doSearchPackagesFromDB(dbURL, so.PackageNames())
+	p, err := doSearchPackagesFromDB(ctx, so, dbURL)
 	if err != nil {
-		so.SendError(errors.Wrap(err, "searching packages from db"))
+		so.SendError(ctx, errors.Wrap(err, "searching packages from db"))
 	}
-	so.SendMessage(p...)
+	so.SendMessage(ctx, p...)
 }
 
 // doSearchPackagesFromDB looks for the package of which the specified package names, parsing the remote
 // repository DB, and returns a slice of packages.Package.
+// If a keyring is specified, the DB detached signature (.db.sig) is verified.
+// If the DB is unchanged since the last run, as for its modification time, the packages found then are returned.
 // It possibly returns an error.
-func doSearchPackagesFromDB(dbURL string, packageNames []string) ([]packages.Package, error) {
+//
+//nolint:funlen,cyclop
+func doSearchPackagesFromDB(ctx context.Context, so *SearchOptions, dbURL string) ([]packages.Package, error) {
+	packageNames := so.PackageNames()
+	keyring := so.Keyring()
+
 	fs := afero.NewOsFs()
 
 	tmpdir, err := afero.TempDir(fs, os.TempDir(), "krawler")
@@ -108,11 +140,11 @@ func doSearchPackagesFromDB(dbURL string, packageNames []string) ([]packages.Pac
 		return nil, errors.Wrap(err, "error creating local DB temporeary directory")
 	}
 
-	req, err := http.NewRequest(http.MethodGet, dbURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dbURL, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating HTTP request")
 	}
-	res, err := http.DefaultClient.Do(req)
+	res, err := fetch.Default().Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "error doing HTTP request")
 	}
@@ -122,7 +154,28 @@ func doSearchPackagesFromDB(dbURL string, packageNames []string) ([]packages.Pac
 		return nil, nil
 	}
 
-	gzr, err := gzip.NewReader(res.Body)
+	key := packages.StateKey(fmt.Sprintf("%s %v", dbURL, packageNames), so.SearchOptions)
+	revision := dbRevision(res)
+
+	if records, ok := so.State().Lookup(key, revision); ok {
+		so.Log().WithField("url", dbURL).Info("DB unchanged since the last run")
+		os.Remove(tmpdir)
+
+		return packagesFromRecords(so.State(), records), nil
+	}
+
+	var db bytes.Buffer
+	if _, err = io.Copy(&db, res.Body); err != nil {
+		return nil, errors.Wrap(err, "error reading DB")
+	}
+
+	if keyring != nil {
+		if err = verifyDB(ctx, keyring, dbURL, db.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	gzr, err := gzip.NewReader(&db)
 	if err != nil {
 		return nil, errors.Wrap(err, "error reading gzip response")
 	}
@@ -169,13 +222,44 @@ func doSearchPackagesFromDB(dbURL string, packageNames []string) ([]packages.Pac
 			Architecture: p.Architecture(),
 			Location:     p.FileName(),
 			url:          p.URL(),
-			fileReaders:  nil,
+			checksum:     p.SHA256Sum(),
+			size:         p.Size(),
 		})
 	}
 
+	ps = so.State().Track(ps...)
+	so.State().Update(key, revision, ps)
+
 	return ps, nil
 }
 
+// verifyDB verifies the repository DB against its detached signature.
+// A missing signature fails the verification.
+func verifyDB(ctx context.Context, keyring signature.Keyring, dbURL string, db []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dbURL+DBSignatureSuffix, nil)
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+	res, err := fetch.Default().Do(req)
+	if err != nil {
+		return errors.Wrap(err, "error doing HTTP request")
+	}
+	defer res.Body.Close()
+
+	var sig bytes.Buffer
+	if res.StatusCode == http.StatusOK {
+		if _, err = io.Copy(&sig, res.Body); err != nil {
+			return errors.Wrap(err, "error reading DB signature")
+		}
+	}
+
+	if err = signature.VerifyDetached(keyring, db, sig.Bytes()); err != nil {
+		return errors.Wrap(err, dbURL)
+	}
+
+	return nil
+}
+
 func untar(source *tar.Reader, fs afero.Fs, target string) error {
 	err := fs.MkdirAll(target, 0755)
 	if err != nil {
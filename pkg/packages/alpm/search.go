@@ -14,7 +14,7 @@ type SearchOptions struct {
 // NewSearchOptions returns a pointer to a SearchOptions object from a pointer to a packages.SearchOptions, and
 // overriding architectures and seedURLs.
 func NewSearchOptions(options *packages.SearchOptions, seedURLs []string, packageNames []string) *SearchOptions {
-	return &SearchOptions{
+	so := &SearchOptions{
 		packages.NewSearchOptions(
 			options.PackageName(),
 			nil,
@@ -25,6 +25,12 @@ func NewSearchOptions(options *packages.SearchOptions, seedURLs []string, packag
 		),
 		packageNames,
 	}
+	so.SetRelatedPackageNames(options.RelatedPackageNames()...)
+	so.SetKeyring(options.Keyring())
+	so.SetState(options.State())
+	so.SetParallelism(options.Parallelism())
+
+	return so
 }
 
 func (o *SearchOptions) PackageNames() []string {
@@ -0,0 +1,40 @@
+//go:build archlinux
+
+package alpm
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// dbRevision returns the revision marker of the repository DB, from its modification time,
+// or its entity tag if the modification time is not declared.
+func dbRevision(res *http.Response) string {
+	if v := res.Header.Get("Last-Modified"); v != "" {
+		return v
+	}
+
+	return res.Header.Get("ETag")
+}
+
+// packagesFromRecords returns the packages found by a previous run, from their records in the state.
+func packagesFromRecords(state *packages.State, records []*packages.PackageRecord) []packages.Package {
+	ps := make([]packages.Package, 0, len(records))
+
+	for _, r := range records {
+		ps = append(ps, state.Restore(r, &Package{
+			Name:         r.Name,
+			Version:      r.Version,
+			Release:      r.Release,
+			Architecture: r.Arch,
+			Location:     r.Location,
+			url:          r.URL,
+			checksum:     strings.TrimPrefix(r.Checksum, "sha256:"),
+			size:         r.Size,
+		}))
+	}
+
+	return ps
+}
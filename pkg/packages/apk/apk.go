@@ -0,0 +1,332 @@
+package apk
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// SearchPackages crawls packages from the specified APKINDEX URLs,
+// and returns a list of package of type Package with the specified names.
+// The search stops when ctx is done, returning the context error.
+func SearchPackages(ctx context.Context, so *SearchOptions) ([]packages.Package, error) {
+	var result []packages.Package
+
+	search := func(indexURL string) {
+		searchPackagesFromIndex(
+			ctx,
+			func() {
+				so.Progress(1)
+				so.SigProducerCompletion()
+			},
+			so, indexURL)
+	}
+
+	collect := func() {
+		so.Consume(
+			func(p ...packages.Package) {
+				so.Log().Debug("Scanned index")
+				if len(p) > 0 {
+					result = append(result, p...)
+					so.Log().Infof("New %d packages found", len(p))
+				}
+			},
+			func(e error) {
+				so.Log().Error(e)
+			},
+		)
+	}
+
+	// Run search producers.
+	for _, v := range so.SeedURLs() {
+		indexURL := v
+		so.Go(ctx, func() { search(indexURL) })
+	}
+
+	// Run collect consumer.
+	go collect()
+
+	// Wait for producers and consumers to complete and cleanup.
+	so.WaitAndClose()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// searchPackagesFromIndex looks for the packages with the specified names in the
+// APKINDEX archive available at indexURL, and sends them to the search options queue.
+// E.g. /v3.18/main/x86_64/APKINDEX.tar.gz -> /v3.18/main/x86_64/linux-lts-dev-6.1.55-r0.apk.
+func searchPackagesFromIndex(ctx context.Context, doneFunc func(), so *SearchOptions, indexURL string) {
+	defer doneFunc()
+
+	so.Log().WithField("url", indexURL).Info("Analysing index")
+
+	index, err := getIndexFromURL(ctx, indexURL)
+	if err != nil {
+		so.SendError(ctx, errors.Wrap(err, indexURL))
+
+		return
+	}
+
+	repoURL := strings.TrimSuffix(indexURL, IndexArchive)
+
+	matches := []*Package{}
+
+	for i := range index {
+		p := &index[i]
+
+		if !slices.Contains(so.PackageNames(), p.Name) {
+			continue
+		}
+
+		if len(so.Architectures()) > 0 && !slices.Contains(so.Architectures(), packages.Architecture(p.Architecture)) {
+			continue
+		}
+
+		matches = append(matches, p)
+	}
+
+	queue := packages.NewMPSCQueue(len(matches), so.Parallelism())
+
+	for _, v := range matches {
+		p := v
+
+		queue.Go(ctx, func() {
+			defer queue.SigProducerCompletion()
+
+			var err error
+
+			p.url, err = url.JoinPath(repoURL, p.GetLocation())
+			if err != nil {
+				queue.SendError(ctx, err)
+
+				return
+			}
+
+			p.fileNames = so.PackageFileNames()
+
+			so.Log().WithField("version", p.Version).WithField("release", p.Release).WithField("name", p.Name).Debug("found package")
+			queue.SendMessage(ctx, p)
+		})
+	}
+
+	go func() {
+		queue.Consume(
+			func(p ...packages.Package) {
+				so.SendMessage(ctx, p...)
+			},
+			func(e error) {
+				so.Log().Error(e)
+			},
+		)
+	}()
+
+	queue.WaitAndClose()
+}
+
+func getIndexFromURL(ctx context.Context, indexURL string) ([]Package, error) {
+	u, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := fetch.Default().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Wrapf(errIndexURLNotValid, "%s: HTTP status code %d", u.String(), resp.StatusCode)
+	}
+
+	return LoadIndex(resp.Body)
+}
+
+// LoadIndex parses an APKINDEX.tar.gz archive and returns the packages listed in its index.
+// The archive is made of concatenated gzip streams (signature and index), which are read as a single tarball.
+func LoadIndex(r io.Reader) ([]Package, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Name == indexFile {
+			return parseIndex(tr)
+		}
+	}
+
+	return nil, ErrIndexNotFound
+}
+
+// parseIndex parses the APKINDEX text format, where each package is a block of
+// "<field>:<value>" lines, and blocks are separated by empty lines.
+func parseIndex(r io.Reader) ([]Package, error) {
+	var (
+		index []Package
+		p     Package
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if p.Name != "" {
+				index = append(index, p)
+			}
+
+			p = Package{}
+
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		switch field {
+		case fieldName:
+			p.Name = value
+		case fieldVersion:
+			p.Version, p.Release = splitVersion(value)
+		case fieldArch:
+			p.Architecture = value
+		case fieldChecksum:
+			p.Checksum = value
+		case fieldSize:
+			p.Size = value
+		case fieldOrigin:
+			p.Origin = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if p.Name != "" {
+		index = append(index, p)
+	}
+
+	return index, nil
+}
+
+// splitVersion splits a full package version (e.g. 6.1.55-r0) into
+// the upstream version and the package release number.
+func splitVersion(fullVersion string) (string, string) {
+	i := strings.LastIndex(fullVersion, releaseSeparator)
+	if i < 0 {
+		return fullVersion, ""
+	}
+
+	return fullVersion[:i], fullVersion[i+len(releaseSeparator):]
+}
+
+// walkPackageFiles downloads the package, and visits the package files as they are read.
+func walkPackageFiles(ctx context.Context, packageURL string, fileNames []string, visit packages.FileVisitor) error {
+	u, err := url.Parse(packageURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := fetch.Default().Do(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+
+		return errors.Wrap(errPackageURLNotFound, packageURL)
+	}
+
+	if resp.Body == nil {
+		return errPackageURLInvalidResponse
+	}
+	defer resp.Body.Close()
+
+	// APK packages are concatenated gzip streams of signature, control and data tarballs.
+	gr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	return walkTarFiles(tar.NewReader(gr), fileNames, visit)
+}
+
+// walkTarFiles visits the regular files of the tarball with the names, or all of them if none is specified.
+func walkTarFiles(tr *tar.Reader, names []string, visit packages.FileVisitor) error {
+	found := 0
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if len(names) > 0 && !slices.Contains(names, filepath.Base(header.Name)) {
+			continue
+		}
+
+		if skip, err := packages.VisitFile(visit, header.Name, tr); skip {
+			return err
+		}
+
+		found++
+
+		if len(names) > 0 && found == len(names) {
+			return nil
+		}
+	}
+}
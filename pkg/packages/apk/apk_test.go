@@ -0,0 +1,86 @@
+package apk_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maxgio92/krawler/pkg/packages/apk"
+)
+
+const index = `C:Q1ZGZ1Gf3Cq7m8iy3sh5Ph/2xVNPM=
+P:linux-lts-dev
+V:6.1.55-r0
+A:x86_64
+S:12890871
+o:linux-lts
+
+C:Q1h0Aw4H4yDWxvb6FiXJpYzZ9CZ4E=
+P:linux-virt-dev
+V:6.1.55-r0
+A:x86_64
+S:12843311
+o:linux-lts
+`
+
+func buildIndexArchive(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		assert.NoError(t, err)
+
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+
+	return buf
+}
+
+func TestLoadIndex(t *testing.T) {
+	t.Parallel()
+
+	archive := buildIndexArchive(t, map[string]string{
+		"DESCRIPTION": "v3.18.4-160-g3f4bf6ac4fd",
+		"APKINDEX":    index,
+	})
+
+	got, err := apk.LoadIndex(archive)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	assert.Equal(t, "linux-lts-dev", got[0].GetName())
+	assert.Equal(t, "6.1.55", got[0].GetVersion())
+	assert.Equal(t, "0", got[0].GetRelease())
+	assert.Equal(t, "x86_64", got[0].GetArch())
+	assert.Equal(t, "linux-lts", got[0].Origin)
+	assert.Equal(t, "linux-lts-dev-6.1.55-r0.apk", got[0].GetLocation())
+
+	assert.Equal(t, "linux-virt-dev", got[1].GetName())
+}
+
+func TestLoadIndexNotFound(t *testing.T) {
+	t.Parallel()
+
+	archive := buildIndexArchive(t, map[string]string{
+		"DESCRIPTION": "v3.18.4-160-g3f4bf6ac4fd",
+	})
+
+	_, err := apk.LoadIndex(archive)
+	assert.ErrorIs(t, err, apk.ErrIndexNotFound)
+}
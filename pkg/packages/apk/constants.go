@@ -0,0 +1,20 @@
+package apk
+
+const (
+	// IndexArchive is the name of the signed, gzipped tarball containing the repository index.
+	IndexArchive = "APKINDEX.tar.gz"
+
+	indexFile = "APKINDEX"
+
+	// Fields of the index records.
+	// More on this here: https://wiki.alpinelinux.org/wiki/Apk_spec.
+	fieldName     = "P"
+	fieldVersion  = "V"
+	fieldArch     = "A"
+	fieldChecksum = "C"
+	fieldSize     = "S"
+	fieldOrigin   = "o"
+
+	releaseSeparator = "-r"
+	packageExtension = ".apk"
+)
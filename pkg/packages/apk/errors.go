@@ -0,0 +1,10 @@
+package apk
+
+import "errors"
+
+var (
+	ErrIndexNotFound             = errors.New("APKINDEX file not found in the index archive")
+	errIndexURLNotValid          = errors.New("index url is not valid")
+	errPackageURLNotFound        = errors.New("package url not found")
+	errPackageURLInvalidResponse = errors.New("package url returned an invalid response")
+)
@@ -0,0 +1,67 @@
+package apk
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+type Package struct {
+	Name         string
+	Version      string
+	Release      string
+	Architecture string
+	Origin       string
+	Checksum     string
+	Size         string
+	url          string
+
+	// The names of the package files looked for.
+	fileNames []string
+}
+
+func (p *Package) GetName() string {
+	return p.Name
+}
+
+func (p *Package) GetVersion() string {
+	return p.Version
+}
+
+func (p *Package) GetRelease() string {
+	return p.Release
+}
+
+func (p *Package) GetArch() string {
+	return p.Architecture
+}
+
+// GetLocation returns the file name of the package, relative to the repository architecture folder.
+func (p *Package) GetLocation() string {
+	if p.Release == "" {
+		return p.Name + "-" + p.Version + packageExtension
+	}
+
+	return p.Name + "-" + p.Version + releaseSeparator + p.Release + packageExtension
+}
+
+func (p *Package) URL() string {
+	return p.url
+}
+
+// GetChecksum returns no checksum, as the APKINDEX checksum is of the package control section only.
+func (p *Package) GetChecksum() string {
+	return ""
+}
+
+func (p *Package) GetSize() int64 {
+	size, _ := strconv.ParseInt(p.Size, 10, 64)
+
+	return size
+}
+
+// Files downloads the package, and visits the files looked for.
+func (p *Package) Files(ctx context.Context, visit packages.FileVisitor) error {
+	return walkPackageFiles(ctx, p.url, p.fileNames, visit)
+}
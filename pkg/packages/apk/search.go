@@ -0,0 +1,34 @@
+package apk
+
+import (
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+type SearchOptions struct {
+	*packages.SearchOptions
+	packageNames []string
+}
+
+// NewSearchOptions returns a pointer to a SearchOptions object from a pointer to a packages.SearchOptions, and
+// overriding architectures, seedURLs and the names of the packages to look for.
+func NewSearchOptions(options *packages.SearchOptions, architectures []packages.Architecture, seedURLs []string, packageNames []string) *SearchOptions {
+	so := &SearchOptions{
+		packages.NewSearchOptions(
+			options.PackageName(),
+			architectures,
+			seedURLs,
+			options.Verbosity(),
+			options.ProgressMessage(),
+			options.PackageFileNames()...,
+		),
+		packageNames,
+	}
+	so.SetRelatedPackageNames(options.RelatedPackageNames()...)
+	so.SetParallelism(options.Parallelism())
+
+	return so
+}
+
+func (o *SearchOptions) PackageNames() []string {
+	return o.packageNames
+}
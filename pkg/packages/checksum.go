@@ -0,0 +1,113 @@
+package packages
+
+import (
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+var (
+	ErrChecksumMismatch              = errors.New("checksum mismatch")
+	ErrChecksumAlgorithmNotSupported = errors.New("checksum algorithm not supported")
+)
+
+// ChecksumError is returned when downloaded content does not match the checksum
+// declared by the repository metadata.
+type ChecksumError struct {
+	URL       string
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: %s %s: expected %s, got %s", e.URL, e.Algorithm, ErrChecksumMismatch, e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// ChecksumReader computes the checksum of the content read through it,
+// to verify it against the expected one once read.
+type ChecksumReader struct {
+	io.Reader
+	hash      hash.Hash
+	url       string
+	algorithm string
+	expected  string
+}
+
+// NewChecksumReader returns a ChecksumReader reading from r, the content downloaded from url,
+// that is expected to match the checksum computed with the algorithm (e.g. sha256).
+func NewChecksumReader(r io.Reader, url, algorithm, expected string) (*ChecksumReader, error) {
+	h, err := newHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChecksumReader{
+		Reader:    io.TeeReader(r, h),
+		hash:      h,
+		url:       url,
+		algorithm: algorithm,
+		expected:  strings.ToLower(expected),
+	}, nil
+}
+
+// Verify reads the remaining content, and verifies the checksum of the whole content.
+// On mismatch, it returns a *ChecksumError and marks the mirror serving the content as unhealthy.
+func (c *ChecksumReader) Verify() error {
+	if _, err := io.Copy(io.Discard, c.Reader); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(c.hash.Sum(nil))
+	if actual == c.expected {
+		return nil
+	}
+
+	err := &ChecksumError{
+		URL:       c.url,
+		Algorithm: c.algorithm,
+		Expected:  c.expected,
+		Actual:    actual,
+	}
+
+	DefaultHealth().MarkUnhealthy(c.url, err)
+
+	// Do not serve the corrupt content from the cache again.
+	fetch.Invalidate(c.url)
+
+	return err
+}
+
+func newHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	case "sha384":
+		return sha512.New384(), nil
+	case "sha224":
+		return sha256.New224(), nil
+	case "sha1", "sha":
+		//nolint:gosec
+		return sha1.New(), nil
+	case "md5":
+		//nolint:gosec
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrChecksumAlgorithmNotSupported, algorithm)
+	}
+}
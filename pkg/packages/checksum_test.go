@@ -0,0 +1,78 @@
+package packages_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestChecksumReader(t *testing.T) {
+	t.Parallel()
+
+	content := "Package: linux-headers-amd64\n"
+	sum := sha256.Sum256([]byte(content))
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := map[string]struct {
+		url      string
+		content  string
+		checksum string
+		mismatch bool
+	}{
+		"matching content": {
+			url:      "https://good.example.com/dists/stable/main/binary-amd64/Packages",
+			content:  content,
+			checksum: strings.ToUpper(checksum),
+		},
+		"corrupt content": {
+			url:      "https://corrupt.example.com/dists/stable/main/binary-amd64/Packages",
+			content:  "Package: linux-headers-arm64\n",
+			checksum: checksum,
+			mismatch: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cr, err := packages.NewChecksumReader(strings.NewReader(tt.content), tt.url, "sha256", tt.checksum)
+			assert.NilError(t, err)
+
+			// Read partially, the remaining content is read on verification.
+			_, err = io.ReadFull(cr, make([]byte, 8))
+			assert.NilError(t, err)
+
+			err = cr.Verify()
+			if !tt.mismatch {
+				assert.NilError(t, err)
+				assert.Assert(t, packages.DefaultHealth().IsHealthy(tt.url))
+
+				return
+			}
+
+			assert.Assert(t, errors.Is(err, packages.ErrChecksumMismatch))
+
+			var checksumErr *packages.ChecksumError
+			assert.Assert(t, errors.As(err, &checksumErr))
+			assert.Equal(t, checksumErr.Expected, checksum)
+			assert.Assert(t, !packages.DefaultHealth().IsHealthy(tt.url))
+		})
+	}
+}
+
+func TestChecksumReaderAlgorithmNotSupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := packages.NewChecksumReader(strings.NewReader(""), "https://example.com", "crc32", "")
+	assert.Assert(t, errors.Is(err, packages.ErrChecksumAlgorithmNotSupported))
+}
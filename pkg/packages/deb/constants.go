@@ -1,6 +1,15 @@
 package deb
 
 const (
-	InRelease           = "InRelease"
-	PackagesIndexFormat = ".xz"
+	InRelease        = "InRelease"
+	Release          = "Release"
+	ReleaseSignature = "Release.gpg"
+	PackagesIndex    = "Packages"
+
+	// checksumAlgorithm is the algorithm of the package checksums verified, declared by the Packages index.
+	checksumAlgorithm = "sha256"
 )
+
+// PackagesIndexFormats are the compression formats of the Packages index files,
+// in order of preference.
+var PackagesIndexFormats = []string{".xz", ".gz", ".bz2", ""}
@@ -1,27 +1,29 @@
 package deb
 
 import (
+	"context"
 	"fmt"
-	"net/http"
-	"net/url"
-	"os"
+	"io"
 	"path"
 	"strings"
 
 	"github.com/maxgio92/krawler/pkg/packages"
 
+	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
 	"pault.ag/go/archive"
 	"pault.ag/go/debian/deb"
+	"pault.ag/go/debian/dependency"
 )
 
 // SearchPackages returns a slice of pault.ag/go/archive.Package objects, filtering as for search options.
-// The function crawls the repositories with asynchronous and parallel workers.
-func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
+// The function crawls the repositories with asynchronous and parallel workers, until ctx is done.
+func SearchPackages(ctx context.Context, so *SearchOptions) ([]packages.Package, error) {
 	var result []packages.Package
 
 	search := func(distURL string) {
 		searchPackagesFromDist(
+			ctx,
 			func() {
 				so.Progress(1)
 				so.SigProducerCompletion()
@@ -47,7 +49,7 @@ func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
 	// Run search producers.
 	for _, v := range so.SeedURLs() {
 		distURL := v
-		go search(distURL)
+		so.Go(ctx, func() { search(distURL) })
 	}
 
 	// Run collect consumer.
@@ -56,120 +58,228 @@ func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
 	// Wait for producers and consumers to complete and cleanup.
 	so.WaitAndClose()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
 // searchPackagesFromDist writes to a channel pault.ag/go/archive.Package objects, writes errors to a channel, through usage
 // of asynchronous workers. It needs a function doneFunc to be executed on completion.
 // Accepts as argument for filtering packages the package name as string and the deb dist URL where to look for packages.
-func searchPackagesFromDist(doneFunc func(), distSO *SearchOptions, distURL string) {
+// The dist URL is either a dist under the dists/ folder, or the root of a flat repository.
+//
+//nolint:funlen,cyclop
+func searchPackagesFromDist(ctx context.Context, doneFunc func(), distSO *SearchOptions, distURL string) {
 	defer doneFunc()
 
-	inRelease, err := getInReleaseFromDistURL(distURL)
+	flat := isFlat(distURL)
+
+	var indexes []packagesIndex
+
+	rel, err := getReleaseFromDistURL(ctx, distURL, distSO.Keyring())
+
+	switch {
+	case err == nil:
+		indexes, err = getPackagesIndexesFromRelease(rel, distURL)
+	case flat && errors.Is(err, ErrReleaseNotFound) && distSO.Keyring() == nil:
+		// Flat repositories are not required to publish a Release file,
+		// unless they're expected to be signed.
+		indexes, err = getFlatPackagesIndexes(distURL)
+	}
+
 	if err != nil {
-		distSO.SendError(err)
+		distSO.SendError(ctx, err)
 
 		return
 	}
 
-	indexURLs, err := getPackagesIndexURLsFromInRelease(inRelease, distURL)
-	if err != nil {
-		distSO.SendError(err)
+	// Package file names are relative to the repository root.
+	rootURL := distURL
+	if i := strings.LastIndex(distURL, "/dists/"); i >= 0 {
+		rootURL = distURL[:i]
+	}
+
+	indexURLs := make([]string, 0, len(indexes))
+	for _, v := range indexes {
+		indexURLs = append(indexURLs, v.url)
+	}
+
+	// Skip the dist if its indexes are unchanged since the last run.
+	// Flat repositories without Release file cannot be tracked.
+	var revision string
+	if rel != nil {
+		revision = distRevision(selectIndexes(indexes, distSO.Components(), flat))
+	}
+
+	key := packages.StateKey(distURL, distSO.SearchOptions)
+	if records, ok := distSO.State().Lookup(key, revision); ok {
+		distSO.Log().WithField("url", distURL).Info("Dist unchanged since the last run")
+		distSO.SendMessage(ctx, packagesFromRecords(distSO.State(), records, distSO.PackageFileNames())...)
 
 		return
 	}
 
-	o := packages.NewSearchOptions(distSO.PackageName(), distSO.Architectures(), indexURLs, distSO.Verbosity(), fmt.Sprintf("Indexing packages for dist %s", path.Base(distURL)))
+	var (
+		result   []packages.Package
+		complete = true
+	)
+
+	o := packages.NewSearchOptions(distSO.PackageName(), distSO.Architectures(), indexURLs, distSO.Verbosity(), fmt.Sprintf("Indexing packages for dist %s", path.Base(distURL)), distSO.PackageFileNames()...)
+	o.SetRelatedPackageNames(distSO.RelatedPackageNames()...)
+	o.SetParallelism(distSO.Parallelism())
 	indexSO := NewSearchOptions(o, o.Architectures(), o.SeedURLs(), distSO.Components())
 
-	// Run producers, to search packages from Packages index files.
-	for _, v := range indexSO.SeedURLs() {
-		ss := strings.Split(v, "/")
-		component := ss[len(ss)-3]
+	// The packages of all the indexes are kept, for the dependencies to be resolved across the indexes.
+	index := newDistIndex()
+
+	// Run producers, to load the packages from Packages index files.
+	for _, v := range indexes {
+		packagesIndex := v
 
-		if !slices.Contains(indexSO.Components(), component) {
+		// Flat repositories have no components.
+		if !flat && !slices.Contains(indexSO.Components(), packagesIndex.component) {
 			indexSO.SigProducerCompletion()
 
 			continue
 		}
 
-		go searchPackagesFromIndex(
-			func() {
-				indexSO.Progress(1)
-				indexSO.SigProducerCompletion()
-			},
-			indexSO, v)
+		indexSO.Go(ctx, func() {
+			searchPackagesFromIndex(
+				ctx,
+				func() {
+					indexSO.Progress(1)
+					indexSO.SigProducerCompletion()
+				},
+				indexSO, packagesIndex, index)
+		})
 	}
 
-	// Run consumer from child option set, to fill the parent search option set.
+	// Run consumer from child option set, to report the errors to the parent search option set.
 	go indexSO.Consume(
-		func(p ...packages.Package) {
-			indexSO.Log().Debug("got a response from DB")
-			if len(p) > 0 {
-				distSO.SendMessage(p...)
-			}
-		},
+		func(...packages.Package) {},
 		func(e error) {
 			indexSO.Log().Debug("got an error from DB")
-			distSO.SendError(e)
+			complete = false
+			distSO.SendError(ctx, e)
 		},
 	)
 
 	// Wait for producersWG and consumer to complete.
 	indexSO.WaitAndClose()
+
+	// The packages found in the indexes loaded are sent, even if some indexes could not be loaded.
+	if ps := packagesFromIndex(index, distSO, rootURL); len(ps) > 0 {
+		result = distSO.State().Track(ps...)
+		distSO.SendMessage(ctx, result...)
+	}
+
+	// Partial results are not recorded, for the dist to be crawled again by the next run.
+	if !complete || ctx.Err() != nil {
+		return
+	}
+
+	distSO.State().Update(key, revision, result)
 }
 
-// searchPackagesFromIndex searches and fills with a channel of deb packages from Packages index files.
+// selectIndexes returns the Packages index files of the components.
+// Flat repositories have no components.
+func selectIndexes(indexes []packagesIndex, components []string, flat bool) []packagesIndex {
+	if flat {
+		return indexes
+	}
+
+	selected := make([]packagesIndex, 0, len(indexes))
+
+	for _, v := range indexes {
+		if slices.Contains(components, v.component) {
+			selected = append(selected, v)
+		}
+	}
+
+	return selected
+}
+
+// searchPackagesFromIndex loads the packages of the Packages index file into the dist index,
+// with the ones matching the search options as found.
 // E.g. /dists/stable/main/binary-amd64/Packages.xz -> /pool/main/l/linux-signed-amd64/linux-headers-amd64_5.10.140-1_amd64.deb
 //
 //nolint:funlen,cyclop
-func searchPackagesFromIndex(doneFunc func(), so *SearchOptions, indexURL string) {
+func searchPackagesFromIndex(ctx context.Context, doneFunc func(), so *SearchOptions, index packagesIndex, dist *distIndex) {
 	defer doneFunc()
 
-	so.Log().WithField("URL", indexURL).Debug("Downloading compressed index file")
+	so.Log().WithField("URL", index.url).Debug("Downloading index file")
 
-	//nolint:gosec,noctx
-	resp, err := http.Get(indexURL)
+	indexURL, format, body, err := getPackagesIndex(ctx, index)
 	if err != nil {
-		so.SendError(err)
+		so.SendError(ctx, err)
 
 		return
 	}
+	defer body.Close()
 
-	if got, want := resp.StatusCode, http.StatusOK; got != want {
-		//nolint:goerr113
-		so.SendError(fmt.Errorf("download(%s): unexpected HTTP status code: got %d, want %d", indexURL, got, want))
+	var content io.Reader = body
 
-		return
+	// Verify the index file against its checksum, as declared by the Release file.
+	verify := func() error { return nil }
+
+	if checksum, ok := index.checksums[format]; ok {
+		cr, err := packages.NewChecksumReader(body, indexURL, index.algorithm, checksum)
+		if err != nil {
+			so.SendError(ctx, err)
+
+			return
+		}
+
+		content, verify = cr, cr.Verify
 	}
-	defer resp.Body.Close()
 
 	so.Log().WithField("URL", indexURL).Debug("Decompressing index file")
 
-	debDecompressor := deb.DecompressorFor(PackagesIndexFormat)
-	rd, err := debDecompressor(resp.Body)
-
-	defer rd.Close()
+	debDecompressor := deb.DecompressorFor(format)
 
+	rd, err := debDecompressor(content)
 	if err != nil {
-		so.SendError(err)
+		if verr := verify(); verr != nil {
+			err = verr
+		}
+
+		so.SendError(ctx, err)
 
 		return
 	}
+	defer rd.Close()
 
 	so.Log().WithField("URL", indexURL).Debug("Loading packages DB from index file")
 
 	db, err := archive.LoadPackages(rd)
+
+	// Corrupt content is reported as such, rather than as a parse error.
+	if verr := verify(); verr != nil {
+		so.SendError(ctx, verr)
+
+		return
+	}
+
 	if err != nil {
-		so.SendError(err)
+		so.SendError(ctx, err)
 
 		return
 	}
 
 	so.Log().WithField("URL", indexURL).Debug("Querying packages from DB")
 
-	query := func(p *archive.Package) bool {
-		if strings.Contains(p.Package, so.PackageName()) {
+	if err = dist.load(db, packageQuery(so)); err != nil {
+		so.SendError(ctx, err)
+	}
+}
+
+// packageQuery returns the query of the packages of a Packages index matching the search options.
+// The architecture independent packages match only if no architecture is looked for.
+func packageQuery(so *SearchOptions) func(p *archive.Package) bool {
+	return func(p *archive.Package) bool {
+		if slices.ContainsFunc(so.PackageNames(), func(name string) bool { return strings.Contains(p.Package, name) }) {
 
 			if so.Architectures() == nil {
 				return true
@@ -186,88 +296,77 @@ func searchPackagesFromIndex(doneFunc func(), so *SearchOptions, indexURL string
 
 		return false
 	}
+}
 
-	ds, err := db.Map(query)
-	if err != nil {
-		so.SendError(err)
-
-		return
-	}
-
+// packagesFromIndex returns the packages found in the dist index, and the packages they depend on,
+// as resolved within the dist index.
+// The architecture independent packages are returned only as dependencies (e.g. linux-headers-6.1.0-18-common).
+// The dependencies not resolved are logged, as they could be published by another dist (e.g. the -updates one).
+func packagesFromIndex(index *distIndex, so *SearchOptions, rootURL string) []packages.Package {
 	// Convert deb packages to a standard type.
 	ps := []packages.Package{}
+	converted := make(map[string]*Package)
 
-	rootURL := strings.Split(indexURL, string(os.PathSeparator)+"dists")[0]
-
-	for _, d := range ds {
-		packageURL, _ := url.JoinPath(rootURL, d.Filename)
+	convert := func(e *indexEntry) *Package {
+		if p, ok := converted[e.filename]; ok {
+			return p
+		}
 
 		p := &Package{
-			Name:    d.Package,
-			Arch:    d.Architecture.String(),
-			Version: d.Version.String(),
-			Url:     packageURL,
+			Name:      e.name,
+			Arch:      e.arch.String(),
+			Version:   e.version.String(),
+			Url:       e.url(rootURL),
+			checksum:  e.checksum,
+			size:      int64(e.size),
+			fileNames: so.PackageFileNames(),
 		}
+		converted[e.filename] = p
 		ps = append(ps, p)
-	}
-
-	so.SendMessage(ps...)
-}
 
-// getInReleaseFromDistURL returns a *archive.Release object from the deb dist URL.
-// It leverages pault.ag/go/archive and pault.ag/go/debian/deb libraries to parse and build the Release object.
-func getInReleaseFromDistURL(distURL string) (*archive.Release, error) {
-	inReleaseURL, err := url.JoinPath(distURL, InRelease)
-	if err != nil {
-		return nil, err
+		return p
 	}
 
-	//nolint:gosec,noctx
-	inReleaseResp, err := http.Get(inReleaseURL)
-	if err != nil {
-		return nil, err
-	}
-	defer inReleaseResp.Body.Close()
+	for _, e := range index.found {
+		p := convert(e)
 
-	if got, want := inReleaseResp.StatusCode, http.StatusOK; got != want {
-		if inReleaseResp.StatusCode == http.StatusNotFound {
-			//nolint:goerr113
-			return nil, fmt.Errorf("InRelease file not found with dist URL %s", distURL)
-		}
+		closure, unresolved := index.index.resolveDependencies(e)
 
-		if inReleaseResp.StatusCode >= 500 && inReleaseResp.StatusCode < 600 {
-			//nolint:goerr113
-			return nil, fmt.Errorf("internal error from mirror for release file with dist URL %s", distURL)
+		for _, dep := range closure {
+			p.dependencies = append(p.dependencies, convert(dep).Url)
 		}
 
-		//nolint:goerr113
-		return nil, fmt.Errorf("download(%s): unexpected HTTP status code: got %d, want %d", inReleaseURL, got, want)
-	}
-
-	release, err := archive.LoadInRelease(inReleaseResp.Body, nil)
-	if err != nil {
-		return nil, err
+		if len(unresolved) > 0 {
+			so.Log().WithField("package", e.filename).
+				Debugf("Dependencies not found in the dist: %s", dependency.Dependency{Relations: unresolved})
+		}
 	}
 
-	return release, nil
+	return ps
 }
 
-// getPackagesIndexURLsFromInRelease returns from per dist Release index file, the URLs of the per component Packages
-// index files.
-// E.g. from /dists/stable/Release -> /dists/stable/main/binary-amd64/Packages.xz.
-func getPackagesIndexURLsFromInRelease(inRelease *archive.Release, distURL string) ([]string, error) {
-	indexURLs := []string{}
-
-	for _, v := range inRelease.MD5Sum {
-		if strings.Contains(v.Filename, "Packages"+PackagesIndexFormat) {
-			u, err := url.JoinPath(distURL, v.Filename)
-			if err != nil {
-				return nil, err
-			}
+// getPackagesIndex downloads the Packages index file, in the first available compression format,
+// and returns its URL, compression format and body.
+func getPackagesIndex(ctx context.Context, index packagesIndex) (string, string, io.ReadCloser, error) {
+	for _, format := range index.formats {
+		indexURL := index.url + format
+
+		body, err := get(ctx, indexURL)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
 
-			indexURLs = append(indexURLs, u)
+		if err != nil {
+			return "", "", nil, err
 		}
+
+		return indexURL, format, body, nil
 	}
 
-	return indexURLs, nil
+	return "", "", nil, errors.Wrap(ErrPackagesIndexNotFound, index.url)
+}
+
+// isFlat returns whether the dist URL is the root of a flat repository, which has no dists/ folder.
+func isFlat(distURL string) bool {
+	return !strings.Contains(distURL, "/dists/")
 }
@@ -0,0 +1,170 @@
+package deb
+
+import (
+	"net/url"
+	"sync"
+
+	"pault.ag/go/archive"
+	"pault.ag/go/debian/dependency"
+	"pault.ag/go/debian/version"
+)
+
+// indexEntry is a package of a Packages index, as needed to resolve the dependencies of the packages found,
+// without keeping the whole index paragraphs.
+type indexEntry struct {
+	name     string
+	source   string
+	arch     dependency.Arch
+	version  version.Version
+	filename string
+	checksum string
+	size     int
+	depends  dependency.Dependency
+}
+
+func newIndexEntry(p *archive.Package) *indexEntry {
+	source := p.Source.Name
+	if source == "" {
+		source = p.Package
+	}
+
+	return &indexEntry{
+		name:     p.Package,
+		source:   source,
+		arch:     p.Architecture,
+		version:  p.Version,
+		filename: p.Filename,
+		checksum: p.SHA256,
+		size:     p.Size,
+		depends:  p.Depends,
+	}
+}
+
+// packageIndex is the packages of a Packages index, by name.
+type packageIndex map[string][]*indexEntry
+
+func (idx packageIndex) add(p *archive.Package) *indexEntry {
+	e := newIndexEntry(p)
+	idx[p.Package] = append(idx[p.Package], e)
+
+	return e
+}
+
+// distIndex is the packages of the Packages indexes of a dist, as loaded concurrently, for the dependencies
+// of the packages found to be resolved across the indexes (e.g. of the main and contrib components).
+type distIndex struct {
+	mu    sync.Mutex
+	index packageIndex
+	found []*indexEntry
+}
+
+func newDistIndex() *distIndex {
+	return &distIndex{index: packageIndex{}}
+}
+
+// load adds the packages of the Packages index to the dist index, and the ones matching the query to the found ones.
+func (d *distIndex) load(db *archive.Packages, query func(*archive.Package) bool) error {
+	var (
+		index = packageIndex{}
+		found []*indexEntry
+	)
+
+	if _, err := db.Map(func(p *archive.Package) bool {
+		e := index.add(p)
+		if query(p) {
+			found = append(found, e)
+		}
+
+		return false
+	}); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, entries := range index {
+		d.index[name] = append(d.index[name], entries...)
+	}
+
+	d.found = append(d.found, found...)
+
+	return nil
+}
+
+// resolveDependencies returns the dependency closure of the package in the index, as declared by Depends,
+// and the relations of the closure satisfied by no package of the index (e.g. as published by another dist).
+// Only the dependencies built from the same source package are resolved, for the closure to be the one
+// of the kernel packages (e.g. linux-headers-6.1.0-18-common and linux-kbuild-6.1 of
+// linux-headers-6.1.0-18-amd64), and not the one of the toolchain and the system libraries.
+func (idx packageIndex) resolveDependencies(e *indexEntry) ([]*indexEntry, []dependency.Relation) {
+	var (
+		closure    []*indexEntry
+		unresolved []dependency.Relation
+		visited    = map[string]bool{e.filename: true}
+		queue      = []*indexEntry{e}
+	)
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, relation := range current.depends.Relations {
+			dep := idx.resolveRelation(current, relation)
+			if dep == nil {
+				unresolved = append(unresolved, relation)
+
+				continue
+			}
+
+			if dep.source != e.source || visited[dep.filename] {
+				continue
+			}
+
+			visited[dep.filename] = true
+			closure = append(closure, dep)
+			queue = append(queue, dep)
+		}
+	}
+
+	return closure, unresolved
+}
+
+// resolveRelation returns the package of the index satisfying the first possible alternative
+// of the relation, in its latest version, or nil if none.
+func (idx packageIndex) resolveRelation(e *indexEntry, relation dependency.Relation) *indexEntry {
+	for _, possibility := range relation.Possibilities {
+		if possibility.Substvar || (possibility.Architectures != nil && !possibility.Architectures.Matches(&e.arch)) {
+			continue
+		}
+
+		var found *indexEntry
+
+		for _, candidate := range idx[possibility.Name] {
+			if candidate.arch.CPU != "all" && !candidate.arch.Is(&e.arch) {
+				continue
+			}
+
+			if possibility.Version != nil && !possibility.Version.SatisfiedBy(candidate.version) {
+				continue
+			}
+
+			if found == nil || version.Compare(candidate.version, found.version) > 0 {
+				found = candidate
+			}
+		}
+
+		if found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// url returns the URL of the package, from the repository root URL.
+func (e *indexEntry) url(rootURL string) string {
+	packageURL, _ := url.JoinPath(rootURL, e.filename)
+
+	return packageURL
+}
@@ -0,0 +1,163 @@
+package deb
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	"pault.ag/go/archive"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const testPackagesIndex = `Package: linux-headers-6.1.0-18-amd64
+Source: linux
+Version: 6.1.76-1
+Architecture: amd64
+Maintainer: Debian Kernel Team <debian-kernel@lists.debian.org>
+Depends: linux-headers-6.1.0-18-common (= 6.1.76-1), linux-kbuild-6.1 (= 6.1.76-1), linux-compiler-gcc-12-x86 (= 6.1.76-1), libc6 (>= 2.34)
+Description: Header files for Linux 6.1.0-18-amd64
+Filename: pool/main/l/linux/linux-headers-6.1.0-18-amd64_6.1.76-1_amd64.deb
+Size: 1402304
+SHA256: 0c5e9a2b4c3d1f6e8a7b9c0d2e4f6a8b0c1d3e5f7a9b2c4d6e8f0a1b3c5d7e9f
+
+Package: linux-kbuild-6.1
+Source: linux
+Version: 6.1.76-1
+Architecture: amd64
+Maintainer: Debian Kernel Team <debian-kernel@lists.debian.org>
+Depends: libc6 (>= 2.34), libssl3 (>= 3.0.0)
+Description: Kbuild infrastructure for Linux 6.1
+Filename: pool/main/l/linux/linux-kbuild-6.1_6.1.76-1_amd64.deb
+Size: 1211680
+
+Package: linux-compiler-gcc-12-x86
+Source: linux
+Version: 6.1.76-1
+Architecture: amd64
+Maintainer: Debian Kernel Team <debian-kernel@lists.debian.org>
+Depends: gcc-12
+Description: Compiler for Linux on x86 (meta-package)
+Filename: pool/main/l/linux/linux-compiler-gcc-12-x86_6.1.76-1_amd64.deb
+Size: 1032
+
+Package: gcc-12
+Source: gcc-12 (12.2.0-14)
+Version: 12.2.0-14
+Architecture: amd64
+Maintainer: Debian GCC Maintainers <debian-gcc@lists.debian.org>
+Depends: cpp-12 (= 12.2.0-14), libc6 (>= 2.34)
+Description: GNU C compiler
+Filename: pool/main/g/gcc-12/gcc-12_12.2.0-14_amd64.deb
+Size: 19541852
+
+Package: libc6
+Source: glibc
+Version: 2.36-9+deb12u4
+Architecture: amd64
+Maintainer: GNU Libc Maintainers <debian-glibc@lists.debian.org>
+Description: GNU C Library: Shared libraries
+Filename: pool/main/g/glibc/libc6_2.36-9+deb12u4_amd64.deb
+Size: 2757936
+`
+
+// The architecture independent packages, as published by the binary-all index.
+const testAllPackagesIndex = `Package: linux-headers-6.1.0-18-common
+Source: linux
+Version: 6.1.69-1
+Architecture: all
+Maintainer: Debian Kernel Team <debian-kernel@lists.debian.org>
+Description: Common header files for Linux 6.1.0-18
+Filename: pool/main/l/linux/linux-headers-6.1.0-18-common_6.1.69-1_all.deb
+Size: 10480420
+
+Package: linux-headers-6.1.0-18-common
+Source: linux
+Version: 6.1.76-1
+Architecture: all
+Maintainer: Debian Kernel Team <debian-kernel@lists.debian.org>
+Description: Common header files for Linux 6.1.0-18
+Filename: pool/main/l/linux/linux-headers-6.1.0-18-common_6.1.76-1_all.deb
+Size: 10483148
+SHA256: 7f1d3b5a9c2e4f6a8b0d1c3e5f7a9b2c4d6e8f0a1b3c5d7e9f0c5e9a2b4c3d1f
+`
+
+func loadTestDistIndex(t *testing.T, so *SearchOptions, contents ...string) *distIndex {
+	t.Helper()
+
+	index := newDistIndex()
+
+	for _, content := range contents {
+		db, err := archive.LoadPackages(strings.NewReader(content))
+		assert.NilError(t, err)
+		assert.NilError(t, index.load(db, packageQuery(so)))
+	}
+
+	return index
+}
+
+func TestPackagesFromIndex(t *testing.T) {
+	t.Parallel()
+
+	so := NewSearchOptions(
+		packages.NewSearchOptions("linux-headers", []packages.Architecture{"amd64"}, nil, 0, ""),
+		[]packages.Architecture{"amd64"}, nil, nil,
+	)
+
+	const rootURL = "https://deb.debian.org/debian"
+
+	// The dependencies are resolved across the indexes of the dist.
+	ps := packagesFromIndex(loadTestDistIndex(t, so, testPackagesIndex, testAllPackagesIndex), so, rootURL)
+
+	// The architecture independent common headers are found as dependencies only, in the version depended on.
+	urls := make([]string, 0, len(ps))
+	for _, p := range ps {
+		urls = append(urls, p.URL())
+	}
+
+	want := []string{
+		rootURL + "/pool/main/l/linux/linux-headers-6.1.0-18-amd64_6.1.76-1_amd64.deb",
+		rootURL + "/pool/main/l/linux/linux-headers-6.1.0-18-common_6.1.76-1_all.deb",
+		rootURL + "/pool/main/l/linux/linux-kbuild-6.1_6.1.76-1_amd64.deb",
+		rootURL + "/pool/main/l/linux/linux-compiler-gcc-12-x86_6.1.76-1_amd64.deb",
+	}
+	assert.DeepEqual(t, urls, want)
+
+	// The dependencies not built from the kernel source package are not resolved.
+	headers := ps[0].(*Package)
+	assert.DeepEqual(t, headers.GetDependencies(), want[1:])
+	assert.Equal(t, headers.GetChecksum(), "sha256:0c5e9a2b4c3d1f6e8a7b9c0d2e4f6a8b0c1d3e5f7a9b2c4d6e8f0a1b3c5d7e9f")
+	assert.Equal(t, headers.GetSize(), int64(1402304))
+
+	assert.Equal(t, ps[1].GetArch(), "all")
+	assert.Assert(t, ps[1].(*Package).GetDependencies() == nil)
+}
+
+func TestResolveDependenciesUnresolved(t *testing.T) {
+	t.Parallel()
+
+	so := NewSearchOptions(
+		packages.NewSearchOptions("linux-headers", []packages.Architecture{"amd64"}, nil, 0, ""),
+		[]packages.Architecture{"amd64"}, nil, nil,
+	)
+
+	// The common headers are published by another index, not loaded.
+	index := loadTestDistIndex(t, so, testPackagesIndex)
+	assert.Equal(t, len(index.found), 1)
+
+	closure, unresolved := index.index.resolveDependencies(index.found[0])
+
+	names := make([]string, 0, len(closure))
+	for _, e := range closure {
+		names = append(names, e.name)
+	}
+
+	assert.DeepEqual(t, names, []string{"linux-kbuild-6.1", "linux-compiler-gcc-12-x86"})
+
+	relations := make([]string, 0, len(unresolved))
+	for _, r := range unresolved {
+		relations = append(relations, r.String())
+	}
+
+	assert.DeepEqual(t, relations, []string{"linux-headers-6.1.0-18-common (= 6.1.76-1)", "libssl3 (>= 3.0.0)"})
+}
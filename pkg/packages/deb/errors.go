@@ -0,0 +1,10 @@
+package deb
+
+import "errors"
+
+var (
+	ErrReleaseNotFound       = errors.New("release file not found")
+	ErrPackagesIndexNotFound = errors.New("packages index file not found")
+
+	errNotFound = errors.New("file not found")
+)
@@ -0,0 +1,55 @@
+package deb
+
+import (
+	"archive/tar"
+	"io"
+
+	"github.com/pkg/errors"
+	"pault.ag/go/debian/deb"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// ExtractPackage extracts the files of the package file from its data tarball into the directory,
+// as the root of the file system.
+func ExtractPackage(path, dir string) error {
+	d, closer, err := deb.LoadFile(path)
+	if err != nil {
+		return errors.Wrap(err, path)
+	}
+	defer closer()
+
+	return errors.Wrap(extractTarFiles(d.Data, dir), path)
+}
+
+// extractTarFiles extracts the directories, the regular files and the links of the tarball into the directory.
+// The other files (e.g. devices), and the symbolic links which could resolve out of the directory, are skipped.
+func extractTarFiles(tr *tar.Reader, dir string) error {
+	extractor := packages.NewExtractor(dir)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = extractor.Mkdir(header.Name, header.FileInfo().Mode().Perm())
+		case tar.TypeReg:
+			err = extractor.WriteFile(header.Name, tr, header.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			_, err = extractor.Symlink(header.Name, header.Linkname)
+		case tar.TypeLink:
+			err = extractor.Link(header.Name, header.Linkname)
+		}
+
+		if err != nil {
+			return errors.Wrap(err, header.Name)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package deb
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestExtractPackage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "linux-headers-6.1.0-18-amd64_6.1.76-1_amd64.deb")
+
+	assert.NilError(t, os.WriteFile(path, testDeb(t, [][2]string{
+		{"./usr/src/linux-headers-6.1.0-18-amd64/.config", "CONFIG_GCC_VERSION=120200\n"},
+		{"./usr/src/linux-headers-6.1.0-18-amd64/Makefile", "include ../linux-headers-6.1.0-18-common/Makefile\n"},
+		{"../../escaped", "\n"},
+	}), 0o600))
+
+	root := filepath.Join(dir, "root")
+	assert.NilError(t, ExtractPackage(path, root))
+
+	b, err := os.ReadFile(filepath.Join(root, "usr/src/linux-headers-6.1.0-18-amd64/Makefile"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "include ../linux-headers-6.1.0-18-common/Makefile\n")
+
+	// The files are extracted into the directory only.
+	_, err = os.Stat(filepath.Join(root, "escaped"))
+	assert.NilError(t, err)
+}
+
+// testTarEntry is a file of a test tarball, with its content or link name.
+type testTarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+}
+
+func testTar(t *testing.T, entries []testTarEntry) *tar.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0o644, Typeflag: e.typeflag}
+
+		switch e.typeflag {
+		case tar.TypeReg:
+			header.Size = int64(len(e.content))
+		case tar.TypeDir:
+			header.Mode = 0o755
+		default:
+			header.Linkname = e.content
+		}
+
+		assert.NilError(t, tw.WriteHeader(header))
+
+		if e.typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(e.content))
+			assert.NilError(t, err)
+		}
+	}
+
+	assert.NilError(t, tw.Close())
+
+	return tar.NewReader(&buf)
+}
+
+//nolint:funlen
+func TestExtractTarFilesHostile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		// The entries get the path of a directory out of the extraction directory.
+		entries func(outside string) []testTarEntry
+		// The symbolic link to the outside directory, existing before the extraction.
+		existing string
+		// The file expected in the extraction directory, if any, with its content.
+		file    [2]string
+		wantErr error
+	}{
+		"absolute symlink": {
+			entries: func(outside string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./lib/modules/6.1.0-18-amd64/build", typeflag: tar.TypeSymlink, content: outside},
+					{name: "./lib/modules/6.1.0-18-amd64/build/pwned", typeflag: tar.TypeReg, content: "pwned\n"},
+				}
+			},
+			wantErr: packages.ErrPathNotSafe,
+		},
+		"absolute symlink re-rooted": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./usr/src/linux-headers-6.1.0-18-amd64/Makefile", typeflag: tar.TypeReg, content: "VERSION = 6\n"},
+					{name: "./lib/modules/6.1.0-18-amd64/build", typeflag: tar.TypeSymlink, content: "/usr/src/linux-headers-6.1.0-18-amd64"},
+				}
+			},
+			file: [2]string{"lib/modules/6.1.0-18-amd64/build/Makefile", "VERSION = 6\n"},
+		},
+		"chained symlinks escaping the directory": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./d/up", typeflag: tar.TypeSymlink, content: ".."},
+					{name: "./x", typeflag: tar.TypeSymlink, content: "d/up/.."},
+					{name: "./x/pwned", typeflag: tar.TypeReg, content: "pwned\n"},
+				}
+			},
+			file: [2]string{"x/pwned", "pwned\n"},
+		},
+		"symlink escaping the directory": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./usr/escape", typeflag: tar.TypeSymlink, content: "../../outside"},
+					{name: "./usr/escape/pwned", typeflag: tar.TypeReg, content: "pwned\n"},
+				}
+			},
+			file: [2]string{"usr/escape/pwned", "pwned\n"},
+		},
+		"file through a symlink": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./usr/lib", typeflag: tar.TypeDir},
+					{name: "./lib", typeflag: tar.TypeSymlink, content: "usr/lib"},
+					{name: "./lib/pwned", typeflag: tar.TypeReg, content: "pwned\n"},
+				}
+			},
+			wantErr: packages.ErrPathNotSafe,
+		},
+		"directory through a symlink": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./usr/lib", typeflag: tar.TypeDir},
+					{name: "./lib", typeflag: tar.TypeSymlink, content: "usr/lib"},
+					{name: "./lib/modules", typeflag: tar.TypeDir},
+				}
+			},
+			wantErr: packages.ErrPathNotSafe,
+		},
+		"hard link through a symlink": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./usr/lib/config", typeflag: tar.TypeReg, content: "CONFIG_64BIT=y\n"},
+					{name: "./lib", typeflag: tar.TypeSymlink, content: "usr/lib"},
+					{name: "./config", typeflag: tar.TypeLink, content: "./lib/config"},
+				}
+			},
+			wantErr: packages.ErrPathNotSafe,
+		},
+		"existing symlink": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./config", typeflag: tar.TypeReg, content: "CONFIG_64BIT=y\n"},
+				}
+			},
+			existing: "config",
+			file:     [2]string{"config", "CONFIG_64BIT=y\n"},
+		},
+		"links in the directory": {
+			entries: func(_ string) []testTarEntry {
+				return []testTarEntry{
+					{name: "./usr/src/linux-headers-6.1.0-18-common/Makefile", typeflag: tar.TypeReg, content: "VERSION = 6\n"},
+					{name: "./usr/src/linux-headers-6.1.0-18-amd64/Makefile", typeflag: tar.TypeSymlink, content: "../linux-headers-6.1.0-18-common/Makefile"},
+					{name: "./usr/src/Makefile", typeflag: tar.TypeLink, content: "./usr/src/linux-headers-6.1.0-18-common/Makefile"},
+				}
+			},
+			file: [2]string{"usr/src/linux-headers-6.1.0-18-amd64/Makefile", "VERSION = 6\n"},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			root, outside := filepath.Join(dir, "root"), filepath.Join(dir, "outside")
+
+			assert.NilError(t, os.Mkdir(root, 0o755))
+			assert.NilError(t, os.Mkdir(outside, 0o755))
+			assert.NilError(t, os.WriteFile(filepath.Join(outside, "config"), []byte("unchanged\n"), 0o600))
+
+			if tt.existing != "" {
+				assert.NilError(t, os.Symlink(filepath.Join(outside, "config"), filepath.Join(root, tt.existing)))
+			}
+
+			err := extractTarFiles(testTar(t, tt.entries(outside)), root)
+			if tt.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tt.wantErr))
+			} else {
+				assert.NilError(t, err)
+			}
+
+			if tt.file[0] != "" {
+				b, err := os.ReadFile(filepath.Join(root, tt.file[0]))
+				assert.NilError(t, err)
+				assert.Equal(t, string(b), tt.file[1])
+			}
+
+			// Nothing is written out of the directory.
+			entries, err := os.ReadDir(outside)
+			assert.NilError(t, err)
+			assert.Equal(t, len(entries), 1)
+
+			b, err := os.ReadFile(filepath.Join(outside, "config"))
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), "unchanged\n")
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package deb
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"pault.ag/go/debian/deb"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const (
+	// The kernel compile header of linux-headers packages, with the compiler the kernel is built with.
+	compileHeaderFile = "compile.h"
+
+	// The prefix of the kernel build configuration of linux-image packages (e.g. /boot/config-6.1.0-18-amd64).
+	imageConfigPrefix = "config-"
+	imageConfigDir    = "boot"
+)
+
+// walkPackageFiles downloads the package to a temporary file, verifying it against its checksum,
+// if declared by the Packages index, and visits the package files from the data tarball.
+func walkPackageFiles(ctx context.Context, packageURL, checksum string, fileNames []string, visit packages.FileVisitor) error {
+	f, err := os.CreateTemp("", "krawler-*.deb")
+	if err != nil {
+		return errors.Wrap(err, "error creating temporary package file")
+	}
+
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	if err = downloadPackage(ctx, packageURL, checksum, f); err != nil {
+		return err
+	}
+
+	d, err := deb.Load(f, packageURL)
+	if err != nil {
+		return errors.Wrap(err, packageURL)
+	}
+	defer d.Close()
+
+	return walkTarFiles(d.Data, fileNames, visit)
+}
+
+// downloadPackage downloads the package to w, verifying it against the SHA256 checksum, if any.
+func downloadPackage(ctx context.Context, packageURL, checksum string, w io.Writer) error {
+	body, err := get(ctx, packageURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var content io.Reader = body
+
+	verify := func() error { return nil }
+
+	if checksum != "" {
+		cr, err := packages.NewChecksumReader(body, packageURL, checksumAlgorithm, checksum)
+		if err != nil {
+			return err
+		}
+
+		content, verify = cr, cr.Verify
+	}
+
+	if _, err = io.Copy(w, content); err != nil {
+		return err
+	}
+
+	return verify()
+}
+
+// walkTarFiles visits the regular files of the tarball with the names, and the kernel files
+// with the compiler version, or all of them if no name is specified.
+func walkTarFiles(tr *tar.Reader, names []string, visit packages.FileVisitor) error {
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg || !isLookedFor(header.Name, names) {
+			continue
+		}
+
+		if skip, err := packages.VisitFile(visit, header.Name, tr); skip {
+			return err
+		}
+	}
+}
+
+// isLookedFor returns whether the package file is one of the files with the names, or a kernel
+// file with the compiler version (i.e. the compile header, and the build configuration
+// of linux-image packages).
+func isLookedFor(name string, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+
+	base := path.Base(name)
+
+	switch {
+	case slices.Contains(names, base), base == compileHeaderFile:
+		return true
+	case strings.HasPrefix(base, imageConfigPrefix) && path.Base(path.Dir(name)) == imageConfigDir:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package deb
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+const testControl = `Package: linux-headers-6.1.0-18-amd64
+Version: 6.1.76-1
+Architecture: amd64
+Maintainer: Debian Kernel Team <debian-kernel@lists.debian.org>
+Description: Header files for Linux 6.1.0-18-amd64
+`
+
+// testTarGz returns a gzip-compressed tarball of the files.
+func testTarGz(t *testing.T, files [][2]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for _, f := range files {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: f[0], Mode: 0o644, Size: int64(len(f[1])), Typeflag: tar.TypeReg}))
+
+		_, err := tw.Write([]byte(f[1]))
+		assert.NilError(t, err)
+	}
+
+	assert.NilError(t, tw.Close())
+	assert.NilError(t, gw.Close())
+
+	return buf.Bytes()
+}
+
+// testDeb returns a .deb package with the data files.
+func testDeb(t *testing.T, files [][2]string) []byte {
+	t.Helper()
+
+	members := [][2]string{
+		{"debian-binary", "2.0\n"},
+		{"control.tar.gz", string(testTarGz(t, [][2]string{{"./control", testControl}}))},
+		{"data.tar.gz", string(testTarGz(t, files))},
+	}
+
+	buf := bytes.NewBufferString("!<arch>\n")
+
+	for _, m := range members {
+		fmt.Fprintf(buf, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", m[0], 0, 0, 0, "100644", len(m[1]))
+		buf.WriteString(m[1])
+
+		if len(m[1])%2 != 0 {
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func TestPackageFiles(t *testing.T) {
+	t.Parallel()
+
+	data := testDeb(t, [][2]string{
+		{"./usr/src/linux-headers-6.1.0-18-amd64/.config", "CONFIG_GCC_VERSION=120200\n"},
+		{"./usr/src/linux-headers-6.1.0-18-amd64/Makefile", "include ../linux-headers-6.1.0-18-common/Makefile\n"},
+		{"./usr/src/linux-headers-6.1.0-18-amd64/include/generated/compile.h", "#define LINUX_COMPILER \"gcc-12 (Debian 12.2.0-14) 12.2.0\"\n"},
+		{"./boot/config-6.1.0-18-amd64", "CONFIG_GCC_VERSION=120200\n"},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(data)
+
+	p := &Package{
+		Url:       server.URL + "/linux-headers-6.1.0-18-amd64_6.1.76-1_amd64.deb",
+		checksum:  hex.EncodeToString(sum[:]),
+		fileNames: []string{".config"},
+	}
+
+	visited := map[string]string{}
+
+	err := p.Files(context.Background(), func(name string, content io.Reader) error {
+		b, err := io.ReadAll(content)
+		visited[name] = string(b)
+
+		return err
+	})
+	assert.NilError(t, err)
+
+	// The files looked for, and the kernel files with the compiler version are visited.
+	assert.DeepEqual(t, visited, map[string]string{
+		"./usr/src/linux-headers-6.1.0-18-amd64/.config":                     "CONFIG_GCC_VERSION=120200\n",
+		"./usr/src/linux-headers-6.1.0-18-amd64/include/generated/compile.h": "#define LINUX_COMPILER \"gcc-12 (Debian 12.2.0-14) 12.2.0\"\n",
+		"./boot/config-6.1.0-18-amd64":                                       "CONFIG_GCC_VERSION=120200\n",
+	})
+
+	// Corrupt packages are not visited.
+	p.checksum = hex.EncodeToString(make([]byte, sha256.Size))
+
+	err = p.Files(context.Background(), func(string, io.Reader) error {
+		t.Fatal("corrupt package visited")
+
+		return nil
+	})
+
+	var cerr *packages.ChecksumError
+	assert.Assert(t, errors.As(err, &cerr))
+}
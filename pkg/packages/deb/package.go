@@ -1,7 +1,9 @@
 package deb
 
 import (
-	"io"
+	"context"
+
+	"github.com/maxgio92/krawler/pkg/packages"
 )
 
 type Package struct {
@@ -11,8 +13,18 @@ type Package struct {
 	Release  string
 	Location string
 	//nolint:stylecheck,revive
-	Url         string
-	fileReaders []io.Reader
+	Url     string
+	Flavour string
+
+	// The SHA256 checksum and the size declared by the Packages index, if any.
+	checksum string
+	size     int64
+
+	// The URLs of the packages the package depends on, resolved within the Packages index.
+	dependencies []string
+
+	// The names of the package files looked for.
+	fileNames []string
 }
 
 type PackageLocation struct {
@@ -43,6 +55,32 @@ func (p *Package) URL() string {
 	return p.Url
 }
 
-func (p *Package) FileReaders() []io.Reader {
-	return p.fileReaders
+func (p *Package) GetFlavour() string {
+	return p.Flavour
+}
+
+func (p *Package) GetChecksum() string {
+	if p.checksum == "" {
+		return ""
+	}
+
+	return checksumAlgorithm + ":" + p.checksum
+}
+
+func (p *Package) GetSize() int64 {
+	return p.size
+}
+
+func (p *Package) GetDependencies() []string {
+	return p.dependencies
+}
+
+func (p *Package) GetVersionScheme() string {
+	return packages.VersionSchemeDeb
+}
+
+// Files downloads the package, and visits the files looked for, with the kernel files
+// with the compiler version.
+func (p *Package) Files(ctx context.Context, visit packages.FileVisitor) error {
+	return walkPackageFiles(ctx, p.Url, p.checksum, p.fileNames, visit)
 }
@@ -0,0 +1,259 @@
+package deb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"pault.ag/go/archive"
+	"pault.ag/go/debian/control"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+	"github.com/maxgio92/krawler/pkg/signature"
+)
+
+// packagesIndex is a Packages index file of a dist, published in one or more compression formats.
+type packagesIndex struct {
+	// url is the URL of the index file, without the compression format extension.
+	// E.g. /dists/stable/main/binary-amd64/Packages.
+	url string
+
+	// component is the component of the index file, which is empty for flat repositories.
+	component string
+
+	// formats are the available compression formats, in order of preference.
+	formats []string
+
+	// algorithm is the algorithm of the checksums declared by the Release file (e.g. sha256).
+	algorithm string
+
+	// checksums are the checksums of the index file, indexed by compression format.
+	checksums map[string]string
+}
+
+// getReleaseFromDistURL returns the Release index file from the deb dist URL.
+// The InRelease file is preferred, falling back to the Release file with its
+// detached signature (Release.gpg), where InRelease does not exist.
+// If a keyring is specified, the signature is verified, and a missing signature is an error.
+// It leverages pault.ag/go/archive and pault.ag/go/debian/deb libraries to parse and build the Release object.
+func getReleaseFromDistURL(ctx context.Context, distURL string, keyring signature.Keyring) (*archive.Release, error) {
+	inReleaseURL, err := url.JoinPath(distURL, InRelease)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := download(ctx, inReleaseURL)
+	if err == nil {
+		if keyring != nil {
+			if data, err = signature.VerifyClearsigned(keyring, data); err != nil {
+				return nil, errors.Wrap(err, inReleaseURL)
+			}
+		}
+
+		return loadRelease(data, inReleaseURL)
+	}
+
+	if !errors.Is(err, errNotFound) {
+		return nil, err
+	}
+
+	releaseURL, err := url.JoinPath(distURL, Release)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = download(ctx, releaseURL)
+	if errors.Is(err, errNotFound) {
+		return nil, errors.Wrap(ErrReleaseNotFound, distURL)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring != nil {
+		signatureURL, err := url.JoinPath(distURL, ReleaseSignature)
+		if err != nil {
+			return nil, err
+		}
+
+		// A missing signature fails the verification.
+		sig, err := download(ctx, signatureURL)
+		if err != nil && !errors.Is(err, errNotFound) {
+			return nil, err
+		}
+
+		if err = signature.VerifyDetached(keyring, data, sig); err != nil {
+			return nil, errors.Wrap(err, releaseURL)
+		}
+	}
+
+	return loadRelease(data, releaseURL)
+}
+
+func loadRelease(data []byte, releaseURL string) (*archive.Release, error) {
+	r, err := archive.LoadInRelease(bytes.NewReader(data), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, releaseURL)
+	}
+
+	return r, nil
+}
+
+// getPackagesIndexesFromRelease returns from per dist Release index file, the per component
+// Packages index files, with their available compression formats.
+// Checksum entries are read by strength, as not all of them are mandatory.
+// E.g. from /dists/stable/Release -> /dists/stable/main/binary-amd64/Packages{.xz,.gz}.
+func getPackagesIndexesFromRelease(r *archive.Release, distURL string) ([]packagesIndex, error) {
+	hashes := []control.FileHash{}
+
+	switch {
+	case len(r.SHA256) > 0:
+		for _, v := range r.SHA256 {
+			hashes = append(hashes, v.FileHash)
+		}
+	case len(r.SHA1) > 0:
+		for _, v := range r.SHA1 {
+			hashes = append(hashes, v.FileHash)
+		}
+	default:
+		for _, v := range r.MD5Sum {
+			hashes = append(hashes, v.FileHash)
+		}
+	}
+
+	formats := map[string][]string{}
+	checksums := map[string]map[string]string{}
+	algorithm := ""
+
+	for _, hash := range hashes {
+		ext := path.Ext(hash.Filename)
+		if !slices.Contains(PackagesIndexFormats, ext) {
+			continue
+		}
+
+		base := strings.TrimSuffix(hash.Filename, ext)
+		if path.Base(base) != PackagesIndex {
+			continue
+		}
+
+		if !slices.Contains(formats[base], ext) {
+			formats[base] = append(formats[base], ext)
+		}
+
+		if checksums[base] == nil {
+			checksums[base] = map[string]string{}
+		}
+
+		checksums[base][ext] = hash.Hash
+		algorithm = hash.Algorithm
+	}
+
+	indexes := make([]packagesIndex, 0, len(formats))
+
+	for base, exts := range formats {
+		u, err := url.JoinPath(distURL, base)
+		if err != nil {
+			return nil, err
+		}
+
+		component := path.Base(path.Dir(path.Dir(base)))
+		if component == "." {
+			component = ""
+		}
+
+		indexes = append(indexes, packagesIndex{
+			url:       u,
+			component: component,
+			formats:   sortFormats(exts),
+			algorithm: algorithm,
+			checksums: checksums[base],
+		})
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].url < indexes[j].url
+	})
+
+	return indexes, nil
+}
+
+// getFlatPackagesIndexes returns the Packages index file of a flat repository without Release file,
+// where any compression format could be available.
+func getFlatPackagesIndexes(distURL string) ([]packagesIndex, error) {
+	u, err := url.JoinPath(distURL, PackagesIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return []packagesIndex{{url: u, formats: PackagesIndexFormats}}, nil
+}
+
+// sortFormats returns the compression formats in order of preference.
+func sortFormats(formats []string) []string {
+	sorted := make([]string, 0, len(formats))
+
+	for _, v := range PackagesIndexFormats {
+		if slices.Contains(formats, v) {
+			sorted = append(sorted, v)
+		}
+	}
+
+	return sorted
+}
+
+// download downloads the file at the URL, and returns its content.
+func download(ctx context.Context, u string) ([]byte, error) {
+	body, err := get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var buf bytes.Buffer
+	if _, err = io.Copy(&buf, body); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// get downloads the file at the URL, and returns its body.
+// A missing file is reported as errNotFound.
+func get(ctx context.Context, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := fetch.Default().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		resp.Body.Close()
+
+		if got == http.StatusNotFound {
+			return nil, errors.Wrap(errNotFound, u)
+		}
+
+		if got >= 500 && got < 600 {
+			//nolint:goerr113
+			return nil, fmt.Errorf("internal error from mirror for file %s", u)
+		}
+
+		//nolint:goerr113
+		return nil, fmt.Errorf("download(%s): unexpected HTTP status code: got %d, want %d", u, got, want)
+	}
+
+	return resp.Body, nil
+}
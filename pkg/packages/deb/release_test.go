@@ -0,0 +1,95 @@
+package deb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gotest.tools/assert"
+	"pault.ag/go/archive"
+)
+
+const testRelease = `Origin: Debian
+Suite: stable
+Codename: bookworm
+Components: main contrib
+MD5Sum:
+ 00000000000000000000000000000000 1000 main/binary-amd64/Packages
+SHA256:
+ 0000000000000000000000000000000000000000000000000000000000000000 1000 main/binary-amd64/Packages
+ 0000000000000000000000000000000000000000000000000000000000000000 100 main/binary-amd64/Packages.gz
+ 0000000000000000000000000000000000000000000000000000000000000000 80 main/binary-amd64/Packages.xz
+ 0000000000000000000000000000000000000000000000000000000000000000 10 main/binary-amd64/Release
+ 0000000000000000000000000000000000000000000000000000000000000000 100 main/i18n/Translation-en.bz2
+ 0000000000000000000000000000000000000000000000000000000000000000 90 contrib/binary-arm64/Packages.bz2
+ 0000000000000000000000000000000000000000000000000000000000000000 90 contrib/Contents-arm64.gz
+`
+
+const (
+	testSHA256 = "0000000000000000000000000000000000000000000000000000000000000000"
+	testMD5    = "00000000000000000000000000000000"
+)
+
+const testFlatRelease = `Origin: Example
+MD5Sum:
+ 00000000000000000000000000000000 1000 Packages
+ 00000000000000000000000000000000 100 Packages.gz
+`
+
+func TestGetPackagesIndexesFromRelease(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		release string
+		distURL string
+		want    []packagesIndex
+	}{
+		"dist release": {
+			release: testRelease,
+			distURL: "https://deb.debian.org/debian/dists/bookworm",
+			want: []packagesIndex{
+				{
+					url:       "https://deb.debian.org/debian/dists/bookworm/contrib/binary-arm64/Packages",
+					component: "contrib",
+					formats:   []string{".bz2"},
+					algorithm: "sha256",
+					checksums: map[string]string{".bz2": testSHA256},
+				},
+				{
+					url:       "https://deb.debian.org/debian/dists/bookworm/main/binary-amd64/Packages",
+					component: "main",
+					formats:   []string{".xz", ".gz", ""},
+					algorithm: "sha256",
+					checksums: map[string]string{".xz": testSHA256, ".gz": testSHA256, "": testSHA256},
+				},
+			},
+		},
+		"flat repository release": {
+			release: testFlatRelease,
+			distURL: "https://example.com/debian/",
+			want: []packagesIndex{
+				{
+					url:       "https://example.com/debian/Packages",
+					formats:   []string{".gz", ""},
+					algorithm: "md5",
+					checksums: map[string]string{".gz": testMD5, "": testMD5},
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := archive.LoadInRelease(strings.NewReader(tt.release), nil)
+			assert.NilError(t, err)
+
+			got, err := getPackagesIndexesFromRelease(r, tt.distURL)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tt.want, got, cmp.AllowUnexported(packagesIndex{}))
+		})
+	}
+}
@@ -14,7 +14,7 @@ type SearchOptions struct {
 // NewSearchOptions returns a pointer to a SearchOptions object from a pointer to a packages.SearchOptions, and
 // overriding architectures and seedURLs.
 func NewSearchOptions(options *packages.SearchOptions, architectures []packages.Architecture, seedURLs []string, components []string) *SearchOptions {
-	return &SearchOptions{
+	so := &SearchOptions{
 		components,
 		packages.NewSearchOptions(
 			options.PackageName(),
@@ -25,6 +25,12 @@ func NewSearchOptions(options *packages.SearchOptions, architectures []packages.
 			options.PackageFileNames()...,
 		),
 	}
+	so.SetRelatedPackageNames(options.RelatedPackageNames()...)
+	so.SetKeyring(options.Keyring())
+	so.SetState(options.State())
+	so.SetParallelism(options.Parallelism())
+
+	return so
 }
 
 func (s *SearchOptions) Components() []string {
@@ -0,0 +1,56 @@
+package deb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// distRevision returns the revision marker of the dist, from the checksums of the Packages index files
+// declared by the Release file.
+// The Release date is not part of it, as Release files are published again on a schedule,
+// even if the indexes did not change.
+func distRevision(indexes []packagesIndex) string {
+	h := sha256.New()
+
+	for _, index := range indexes {
+		if len(index.checksums) == 0 {
+			// The index changes cannot be tracked.
+			return ""
+		}
+
+		fmt.Fprintf(h, "%s %s\n", index.url, index.algorithm)
+
+		for _, format := range index.formats {
+			fmt.Fprintf(h, "%s %s\n", format, index.checksums[format])
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// packagesFromRecords returns the packages found by a previous run, from their records in the state.
+// The package files not recorded are looked for in the packages.
+func packagesFromRecords(state *packages.State, records []*packages.PackageRecord, fileNames []string) []packages.Package {
+	ps := make([]packages.Package, 0, len(records))
+
+	for _, r := range records {
+		ps = append(ps, state.Restore(r, &Package{
+			Name:         r.Name,
+			Arch:         r.Arch,
+			Version:      r.Version,
+			Release:      r.Release,
+			Location:     r.Location,
+			Url:          r.URL,
+			checksum:     strings.TrimPrefix(r.Checksum, checksumAlgorithm+":"),
+			size:         r.Size,
+			dependencies: r.Dependencies,
+			fileNames:    fileNames,
+		}))
+	}
+
+	return ps
+}
@@ -0,0 +1,31 @@
+package deb
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDistRevision(t *testing.T) {
+	t.Parallel()
+
+	index := packagesIndex{
+		url:       "https://deb.debian.org/debian/dists/bookworm/main/binary-amd64/Packages",
+		component: "main",
+		formats:   []string{".xz", ".gz"},
+		algorithm: "sha256",
+		checksums: map[string]string{".xz": testSHA256, ".gz": testSHA256},
+	}
+
+	revision := distRevision([]packagesIndex{index})
+	assert.Assert(t, revision != "")
+	assert.Equal(t, distRevision([]packagesIndex{index}), revision)
+
+	changed := index
+	changed.checksums = map[string]string{".xz": testSHA256, ".gz": "1" + testSHA256[1:]}
+	assert.Assert(t, distRevision([]packagesIndex{changed}) != revision)
+
+	// Indexes without checksums cannot be tracked.
+	untracked := packagesIndex{url: index.url, formats: PackagesIndexFormats}
+	assert.Equal(t, distRevision([]packagesIndex{untracked}), "")
+}
@@ -0,0 +1,62 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+// Download downloads the package from the URL to w, verifying it against the checksum, in the form
+// algorithm:value (e.g. sha256:...), if not empty.
+func Download(ctx context.Context, packageURL, checksum string, w io.Writer) error {
+	var (
+		algorithm, expected string
+		ok                  bool
+	)
+
+	if checksum != "" {
+		if algorithm, expected, ok = strings.Cut(checksum, ":"); !ok {
+			return fmt.Errorf("%w: %s", ErrChecksumAlgorithmNotSupported, checksum)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, packageURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := fetch.Default().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		//nolint:goerr113
+		return fmt.Errorf("download(%s): unexpected HTTP status code: got %d, want %d", packageURL, got, want)
+	}
+
+	var content io.Reader = resp.Body
+
+	verify := func() error { return nil }
+
+	if checksum != "" {
+		var cr *ChecksumReader
+
+		if cr, err = NewChecksumReader(resp.Body, packageURL, algorithm, expected); err != nil {
+			return err
+		}
+
+		content, verify = cr, cr.Verify
+	}
+
+	if _, err = io.Copy(w, content); err != nil {
+		return err
+	}
+
+	return verify()
+}
@@ -0,0 +1,69 @@
+package packages_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+
+	content := "kernel-devel-5.14.0-70.13.1.el9_0.x86_64.rpm"
+	sum := sha256.Sum256([]byte(content))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/kernel-devel.rpm" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		//nolint:errcheck
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		path     string
+		checksum string
+		err      error
+	}{
+		"matching checksum": {path: "/kernel-devel.rpm", checksum: "sha256:" + hex.EncodeToString(sum[:])},
+		"without checksum":  {path: "/kernel-devel.rpm"},
+		"corrupt content":   {path: "/kernel-devel.rpm", checksum: "sha256:" + hex.EncodeToString(make([]byte, sha256.Size)), err: packages.ErrChecksumMismatch},
+		"invalid checksum":  {path: "/kernel-devel.rpm", checksum: "0123", err: packages.ErrChecksumAlgorithmNotSupported},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			err := packages.Download(context.Background(), server.URL+tt.path, tt.checksum, &buf)
+			if tt.err != nil {
+				assert.Assert(t, errors.Is(err, tt.err))
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, buf.String(), content)
+		})
+	}
+
+	err := packages.Download(context.Background(), server.URL+"/missing.rpm", "", &bytes.Buffer{})
+	assert.ErrorContains(t, err, "unexpected HTTP status code")
+}
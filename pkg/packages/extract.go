@@ -0,0 +1,196 @@
+package packages
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrPathNotSafe is returned extracting a package file whose path goes through a symbolic link,
+// as it could resolve out of the extraction directory.
+var ErrPathNotSafe = errors.New("package file path through a symbolic link")
+
+// Extractor extracts the package files into a directory, as the root of the file system.
+// The package files are never written through a symbolic link, and the symbolic links never
+// resolve out of the directory: the absolute ones are made relative to the directory, as its root,
+// and the relative ones are created only if they go up (..) first, and not out of the directory.
+// As the directories are never symbolic links, no chain of links resolves out of the directory.
+type Extractor struct {
+	dir string
+}
+
+// NewExtractor returns an extractor of the package files into the directory.
+func NewExtractor(dir string) *Extractor {
+	return &Extractor{dir: filepath.Clean(dir)}
+}
+
+// Mkdir creates the directory package file, with its parents.
+func (e *Extractor) Mkdir(name string, perm os.FileMode) error {
+	target := e.path(name)
+
+	if err := e.checkPath(target); err != nil {
+		return err
+	}
+
+	return os.MkdirAll(target, perm|0o700)
+}
+
+// WriteFile creates the regular package file with the content, replacing the existing one.
+func (e *Extractor) WriteFile(name string, content io.Reader, perm os.FileMode) error {
+	target, err := e.create(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(f, content); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// Symlink creates the symbolic link package file, replacing the existing one, and returns
+// whether it's created, as the links which could resolve out of the directory are not.
+// Absolute links (e.g. lib/modules/6.1.0-18-amd64/build) are made relative to the directory, as its root.
+func (e *Extractor) Symlink(name, linkname string) (bool, error) {
+	dir := filepath.Dir(e.rel(name))
+	linkname = filepath.FromSlash(linkname)
+
+	if filepath.IsAbs(linkname) {
+		var err error
+
+		if linkname, err = filepath.Rel(dir, e.rel(linkname)); err != nil {
+			return false, err
+		}
+	}
+
+	if !isLocal(dir, linkname) {
+		return false, nil
+	}
+
+	target, err := e.create(name)
+	if err != nil {
+		return false, err
+	}
+
+	return true, os.Symlink(linkname, target)
+}
+
+// Link creates the hard link package file to the package file linked, replacing the existing one.
+func (e *Extractor) Link(name, linked string) error {
+	source := e.path(linked)
+
+	if err := e.checkPath(filepath.Dir(source)); err != nil {
+		return err
+	}
+
+	target, err := e.create(name)
+	if err != nil {
+		return err
+	}
+
+	return os.Link(source, target)
+}
+
+// isLocal returns whether the relative link target resolves in the directory, from the directory of the link.
+// The target must go up first, not out of the directory, and then only down: as going up after a link
+// would be relative to the target of the link, the resolution is not told by the target alone.
+func isLocal(dir, linkname string) bool {
+	depth := 0
+	if dir != "." {
+		depth = len(strings.Split(dir, string(filepath.Separator)))
+	}
+
+	down := false
+
+	for _, elem := range strings.Split(linkname, string(filepath.Separator)) {
+		switch elem {
+		case "", ".":
+		case "..":
+			if down || depth == 0 {
+				return false
+			}
+
+			depth--
+		default:
+			down = true
+		}
+	}
+
+	return true
+}
+
+// create returns the path of the package file in the directory, with its parents created
+// and the existing file removed, for it not to be written through.
+func (e *Extractor) create(name string) (string, error) {
+	target := e.path(name)
+
+	if err := e.checkPath(filepath.Dir(target)); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	return target, nil
+}
+
+// checkPath returns ErrPathNotSafe if the path, or any of its parents in the directory, is a symbolic link.
+func (e *Extractor) checkPath(path string) error {
+	rel, err := filepath.Rel(e.dir, path)
+	if err != nil {
+		return err
+	}
+
+	current := e.dir
+
+	for _, elem := range strings.Split(rel, string(filepath.Separator)) {
+		if elem == "." {
+			continue
+		}
+
+		current = filepath.Join(current, elem)
+
+		var info os.FileInfo
+
+		info, err = os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return errors.Wrap(ErrPathNotSafe, rel)
+		}
+	}
+
+	return nil
+}
+
+// path returns the path of the package file in the directory, not escaping it.
+func (e *Extractor) path(name string) string {
+	return filepath.Join(e.dir, e.rel(name))
+}
+
+// rel returns the path of the package file relative to the directory, not escaping it.
+func (e *Extractor) rel(name string) string {
+	return strings.TrimPrefix(filepath.Clean(string(filepath.Separator)+filepath.FromSlash(name)), string(filepath.Separator))
+}
@@ -0,0 +1,79 @@
+package packages
+
+import (
+	"net/url"
+	"sync"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+var defaultHealth = NewMirrorHealth()
+
+// DefaultHealth returns the health of the mirrors crawled, as for the content they served.
+// It lives as long as the process, and is safe for concurrent use: the unhealthy mirrors
+// are kept until Reset, e.g. between runs.
+func DefaultHealth() *MirrorHealth {
+	return defaultHealth
+}
+
+// MirrorHealth tracks the mirrors which served corrupt content, by host.
+type MirrorHealth struct {
+	mu        sync.RWMutex
+	unhealthy map[string]error
+}
+
+func NewMirrorHealth() *MirrorHealth {
+	return &MirrorHealth{unhealthy: map[string]error{}}
+}
+
+// MarkUnhealthy marks the mirror serving the URL as unhealthy, because of the error.
+// The equivalent mirrors of its group are preferred to it from then on. A mirror without
+// group has no equivalent mirror to fail over to, so it's only reported as unhealthy.
+func (h *MirrorHealth) MarkUnhealthy(u string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.unhealthy[mirrorHost(u)] = err
+
+	fetch.DefaultMirrors().MarkFailure(u)
+}
+
+// IsHealthy returns whether the mirror serving the URL is healthy.
+func (h *MirrorHealth) IsHealthy(u string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	_, ok := h.unhealthy[mirrorHost(u)]
+
+	return !ok
+}
+
+// Unhealthy returns the unhealthy mirror hosts, with the last error they caused.
+func (h *MirrorHealth) Unhealthy() map[string]error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	unhealthy := make(map[string]error, len(h.unhealthy))
+	for k, v := range h.unhealthy {
+		unhealthy[k] = v
+	}
+
+	return unhealthy
+}
+
+// Reset marks all the mirrors as healthy.
+func (h *MirrorHealth) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.unhealthy = map[string]error{}
+}
+
+func mirrorHost(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+
+	return parsed.Host
+}
@@ -0,0 +1,131 @@
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+var ErrMirrorlistEmpty = errors.New("no mirrors listed")
+
+// ResolveMirrorlist returns the URLs listed by the mirrorlist at the URL, in order of preference.
+// The mirrorlist is either a plain list of URLs, one per line, or a metalink.
+// Only HTTP(S) URLs are returned.
+func ResolveMirrorlist(ctx context.Context, mirrorlistURL string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mirrorlistURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := fetch.Default().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		//nolint:goerr113
+		return nil, fmt.Errorf("%s: unexpected HTTP status code %d", mirrorlistURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	urls, err := ParseMirrorlist(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%s: %w", mirrorlistURL, ErrMirrorlistEmpty)
+	}
+
+	return urls, nil
+}
+
+// ParseMirrorlist returns the HTTP(S) URLs listed by the mirrorlist, either a metalink
+// or a plain list of URLs, one per line, where lines starting with # are comments.
+func ParseMirrorlist(data []byte) ([]string, error) {
+	var (
+		urls []string
+		err  error
+	)
+
+	if isMetalink(data) {
+		urls, err = parseMetalink(data)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			urls = append(urls, line)
+		}
+	}
+
+	result := make([]string, 0, len(urls))
+
+	for _, v := range urls {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+
+		result = append(result, v)
+	}
+
+	return result, nil
+}
+
+func isMetalink(data []byte) bool {
+	return bytes.Contains(data, []byte("<metalink"))
+}
+
+// parseMetalink returns the content of the url elements of the metalink (e.g. the ones served by MirrorManager),
+// in document order.
+func parseMetalink(data []byte) ([]string, error) {
+	var urls []string
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "url" {
+			continue
+		}
+
+		var u string
+		if err = decoder.DecodeElement(&u, &start); err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, strings.TrimSpace(u))
+	}
+
+	return urls, nil
+}
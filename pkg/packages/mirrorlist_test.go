@@ -0,0 +1,64 @@
+package packages
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const testMetalink = `<?xml version="1.0" encoding="utf-8"?>
+<metalink version="3.0" xmlns="http://www.metalinker.org/" xmlns:mm0="http://fedorahosted.org/mirrormanager">
+ <files>
+  <file name="repomd.xml">
+   <resources maxconnections="1">
+    <url protocol="https" type="https" location="DE" preference="100">https://ftp.example.de/fedora/linux/releases/39/Everything/x86_64/os/repodata/repomd.xml</url>
+    <url protocol="rsync" type="rsync" location="DE" preference="100">rsync://ftp.example.de/fedora/linux/releases/39/Everything/x86_64/os/repodata/repomd.xml</url>
+    <url protocol="http" type="http" location="US" preference="99">http://mirror.example.com/fedora/releases/39/Everything/x86_64/os/repodata/repomd.xml</url>
+   </resources>
+  </file>
+ </files>
+</metalink>
+`
+
+const testMirrorlist = `# mirrors
+https://cdn.example.com/2/core/2.0/x86_64/abc
+
+ftp://ftp.example.com/2/core/2.0/x86_64/abc
+http://mirror.example.com/2/core/2.0/x86_64/abc
+`
+
+func TestParseMirrorlist(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		data string
+		want []string
+	}{
+		"metalink": {
+			data: testMetalink,
+			want: []string{
+				"https://ftp.example.de/fedora/linux/releases/39/Everything/x86_64/os/repodata/repomd.xml",
+				"http://mirror.example.com/fedora/releases/39/Everything/x86_64/os/repodata/repomd.xml",
+			},
+		},
+		"mirrorlist": {
+			data: testMirrorlist,
+			want: []string{
+				"https://cdn.example.com/2/core/2.0/x86_64/abc",
+				"http://mirror.example.com/2/core/2.0/x86_64/abc",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseMirrorlist([]byte(tt.data))
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package packages
 
 import (
+	"context"
 	"io"
+
+	"github.com/pkg/errors"
 )
 
+// ErrSkipFiles is returned by a FileVisitor to skip the remaining files of the package.
+var ErrSkipFiles = errors.New("skip the remaining package files")
+
 type Package interface {
 	GetName() string
 	GetVersion() string
@@ -11,7 +17,83 @@ type Package interface {
 	GetArch() string
 	GetLocation() string
 	URL() string
-	FileReaders() []io.Reader
+
+	// Files opens the package, and calls visit for each of the files looked for
+	// by the search, as they are read from the package.
+	// The package is opened on each call, and no file content is kept once visited.
+	Files(ctx context.Context, visit FileVisitor) error
+}
+
+// FileVisitor is called with the name of each package file visited, and a reader
+// of its content, valid only until the visitor returns.
+// If the visitor returns ErrSkipFiles, the remaining files are skipped and no error is returned.
+// Any other error stops visiting the files, and it's returned.
+type FileVisitor func(name string, content io.Reader) error
+
+// Flavoured is implemented by packages of kernels built in multiple flavours
+// from the same sources (e.g. the Ubuntu cloud kernels), to tell them apart.
+type Flavoured interface {
+	GetFlavour() string
+}
+
+// The version schemes of the package managers, to compare the package versions.
+const (
+	VersionSchemeRPM  = "rpm"
+	VersionSchemeDeb  = "deb"
+	VersionSchemeAlpm = "alpm"
+)
+
+// Versioned is implemented by packages whose versions are compared with the scheme
+// of their package manager (e.g. the Debian tilde), to order them.
+type Versioned interface {
+	GetVersionScheme() string
+}
+
+// Epoched is implemented by packages whose version epoch is declared apart from the version
+// (e.g. the RPM epoch), as it orders the versions before the version itself.
+type Epoched interface {
+	// GetEpoch returns the epoch of the package version, or an empty string if not declared.
+	GetEpoch() string
+}
+
+// Downloadable is implemented by packages whose checksum and size are declared by the repository metadata.
+type Downloadable interface {
+	// GetChecksum returns the checksum of the package, in the form algorithm:value (e.g. sha256:...),
+	// or an empty string if not declared.
+	GetChecksum() string
+
+	// GetSize returns the size of the package in bytes, or zero if not declared.
+	GetSize() int64
+}
+
+// Dependent is implemented by packages whose dependencies are declared by the repository metadata
+// (e.g. the deb Depends field).
+type Dependent interface {
+	// GetDependencies returns the URLs of the packages the package depends on, as found by the search.
+	GetDependencies() []string
+}
+
+// Unwrap returns the package wrapped by p (e.g. for its files to be recorded in the state),
+// or p if it does not wrap another package.
+func Unwrap(p Package) Package {
+	if w, ok := p.(interface{ Unwrap() Package }); ok {
+		return Unwrap(w.Unwrap())
+	}
+
+	return p
+}
+
+// VisitFile calls visit with the file, and returns whether the remaining files are to be skipped.
+func VisitFile(visit FileVisitor, name string, content io.Reader) (bool, error) {
+	if err := visit(name, content); err != nil {
+		if errors.Is(err, ErrSkipFiles) {
+			return true, nil
+		}
+
+		return true, err
+	}
+
+	return false, nil
 }
 
 type Architecture string
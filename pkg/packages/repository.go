@@ -12,4 +12,15 @@ type Mirror struct {
 	// The base URL of the package mirror
 	// (e.g. https://mirrors.kernel.org/<distribution>)
 	URL string
+
+	// The group of equivalent mirrors the mirror belongs to, serving the same content.
+	// Only the first mirror of a group is crawled, the others are failed over to.
+	// Mirrors without group are not equivalent to any other.
+	Group string
+
+	// The URL of a mirrorlist or metalink listing mirrors equivalent to this one,
+	// to fail over to (e.g. https://mirrors.fedoraproject.org/metalink?repo=fedora-39&arch=x86_64).
+	// It can be templated with the versions and the architectures, resolved by the distros supporting them
+	// (e.g. https://mirrors.fedoraproject.org/metalink?repo=fedora-{{ .versions }}&arch={{ .archs }}).
+	Mirrorlist string
 }
@@ -1,12 +1,29 @@
 package rpm
 
-import log "github.com/sirupsen/logrus"
+import (
+	rpmutils "github.com/sassoftware/go-rpmutils"
+	log "github.com/sirupsen/logrus"
+)
 
 const (
-	metadataPath      = "repodata/repomd.xml"
-	metadataDataXPath = "//repomd/data"
-	dataPackageXPath  = "//package"
-	primary           = "primary"
+	metadataPath = "repodata/repomd.xml"
+
+	// The metadata detached signature is published next to the metadata, e.g. repodata/repomd.xml.asc.
+	metadataSignatureSuffix = ".asc"
+	metadataDataXPath       = "//repomd/data"
+	metadataRevisionXPath   = "//repomd/revision"
+	dataPackageXPath        = "//package"
+	primary                 = "primary"
 )
 
 var logger = log.New()
+
+// The file digest algorithms, as declared by the package header.
+var fileDigestAlgorithms = map[int]string{
+	rpmutils.PGPHASHALGO_MD5:    "md5",
+	rpmutils.PGPHASHALGO_SHA1:   "sha1",
+	rpmutils.PGPHASHALGO_SHA224: "sha224",
+	rpmutils.PGPHASHALGO_SHA256: "sha256",
+	rpmutils.PGPHASHALGO_SHA384: "sha384",
+	rpmutils.PGPHASHALGO_SHA512: "sha512",
+}
@@ -2,9 +2,16 @@ package rpm
 
 type Data struct {
 	Type     string   `xml:"type,attr"`
+	Checksum Checksum `xml:"checksum"`
 	Location Location `xml:"location"`
 }
 
+// Checksum is the checksum of a repository file, computed with the algorithm of its type (e.g. sha256).
+type Checksum struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
 type Location struct {
 	Href string `xml:"href,attr"`
 }
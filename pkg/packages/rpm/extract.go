@@ -0,0 +1,86 @@
+package rpm
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	rpmutils "github.com/sassoftware/go-rpmutils"
+	"github.com/sassoftware/go-rpmutils/cpio"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// ExtractPackage extracts the files of the package file into the directory, as the root of the file system.
+func ExtractPackage(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rpm, err := rpmutils.ReadRpm(f)
+	if err != nil {
+		return errors.Wrap(err, path)
+	}
+
+	payload, err := rpm.PayloadReaderExtended()
+	if err != nil {
+		return errors.Wrap(err, path)
+	}
+
+	return errors.Wrap(extractPayloadFiles(payload, dir), path)
+}
+
+// extractPayloadFiles extracts the directories, the regular files and the links of the payload into the directory.
+// The other files (e.g. devices), and the symbolic links which could resolve out of the directory, are skipped.
+//
+//nolint:cyclop
+func extractPayloadFiles(payload rpmutils.PayloadReader, dir string) error {
+	extractor := packages.NewExtractor(dir)
+
+	// The hard links are created once the file linked, the last one of the same inode, is extracted.
+	links := make(map[int][]string)
+
+	for {
+		fileInfo, err := payload.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name, perm := fileInfo.Name(), os.FileMode(fileInfo.Mode()).Perm()
+
+		switch fileInfo.Mode() &^ 0o7777 {
+		case cpio.S_ISDIR:
+			err = extractor.Mkdir(name, perm)
+		case cpio.S_ISREG:
+			if payload.IsLink() {
+				links[fileInfo.Inode()] = append(links[fileInfo.Inode()], name)
+
+				continue
+			}
+
+			err = extractor.WriteFile(name, payload, perm)
+
+			for _, link := range links[fileInfo.Inode()] {
+				if err != nil {
+					break
+				}
+
+				err = extractor.Link(link, name)
+			}
+
+			delete(links, fileInfo.Inode())
+		case cpio.S_ISLNK:
+			_, err = extractor.Symlink(name, fileInfo.Linkname())
+		}
+
+		if err != nil {
+			return errors.Wrap(err, name)
+		}
+	}
+}
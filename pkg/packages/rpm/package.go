@@ -1,8 +1,11 @@
 package rpm
 
 import (
+	"context"
 	"encoding/xml"
-	"io"
+	"strconv"
+
+	"github.com/maxgio92/krawler/pkg/packages"
 )
 
 type Package struct {
@@ -10,6 +13,7 @@ type Package struct {
 	Name        string          `xml:"name"`
 	Arch        string          `xml:"arch"`
 	Version     PackageVersion  `xml:"version"`
+	Checksum    Checksum        `xml:"checksum"`
 	Summary     string          `xml:"summary"`
 	Description string          `xml:"description"`
 	Packager    string          `xml:"packager"`
@@ -18,7 +22,9 @@ type Package struct {
 	Location    PackageLocation `xml:"location"`
 	Format      PackageFormat   `xml:"format"`
 	url         string
-	fileReaders []io.Reader
+
+	// The names of the package files looked for.
+	fileNames []string
 }
 
 func (p *Package) GetName() string {
@@ -29,6 +35,10 @@ func (p *Package) GetVersion() string {
 	return p.Version.Ver
 }
 
+func (p *Package) GetEpoch() string {
+	return p.Version.Epoch
+}
+
 func (p *Package) GetRelease() string {
 	return p.Version.Rel
 }
@@ -45,6 +55,25 @@ func (p *Package) URL() string {
 	return p.url
 }
 
-func (p *Package) FileReaders() []io.Reader {
-	return p.fileReaders
+func (p *Package) GetChecksum() string {
+	if p.Checksum.Value == "" {
+		return ""
+	}
+
+	return p.Checksum.Type + ":" + p.Checksum.Value
+}
+
+func (p *Package) GetSize() int64 {
+	size, _ := strconv.ParseInt(p.Size.Package, 10, 64)
+
+	return size
+}
+
+func (p *Package) GetVersionScheme() string {
+	return packages.VersionSchemeRPM
+}
+
+// Files downloads the package, up to the files looked for, and visits them.
+func (p *Package) Files(ctx context.Context, visit packages.FileVisitor) error {
+	return walkPackageFiles(ctx, p.url, p.Checksum, p.Format.HeaderRange.PayloadOffset(), p.fileNames, visit)
 }
@@ -0,0 +1,157 @@
+package rpm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+const (
+	// The size of the payload requested with the package headers, and of the next range requests,
+	// doubled on each request up to maxRangeSize.
+	minRangeSize = 1 << 20
+	maxRangeSize = 8 << 20
+)
+
+// rangeReader reads the content at the URL sequentially with HTTP range requests,
+// for only the content actually read to be downloaded.
+// If the server does not support range requests, the whole content is streamed instead.
+//
+//nolint:containedctx
+type rangeReader struct {
+	ctx  context.Context
+	url  string
+	next int64
+
+	// The offset of the next byte to read, and the total size of the content, if known.
+	offset int64
+	size   int64
+
+	body   io.ReadCloser
+	ranged bool
+}
+
+// newRangeReader returns a rangeReader of the content at the URL, requesting first the size bytes.
+func newRangeReader(ctx context.Context, u string, size int64) *rangeReader {
+	if size < minRangeSize {
+		size = minRangeSize
+	}
+
+	return &rangeReader{ctx: ctx, url: u, next: size, size: -1, ranged: true}
+}
+
+// Ranged returns whether the content is read with range requests.
+// It's meaningful once the content is being read.
+func (r *rangeReader) Ranged() bool {
+	return r.ranged
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if r.size >= 0 && r.offset >= r.size {
+				return 0, io.EOF
+			}
+
+			if err := r.request(); err != nil {
+				return 0, err
+			}
+
+			if r.body == nil {
+				return 0, io.EOF
+			}
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+
+		if errors.Is(err, io.EOF) && r.ranged {
+			// The range is read: the next one is requested by the next read.
+			r.body.Close()
+			r.body = nil
+
+			if n > 0 {
+				return n, nil
+			}
+
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (r *rangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+
+	return r.body.Close()
+}
+
+// request requests the next range of the content.
+func (r *rangeReader) request() error {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", r.offset, r.offset+r.next-1))
+
+	resp, err := fetch.Default().Do(req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var start, end int64
+
+		// The total size can be unknown (*).
+		if _, err = fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &r.size); err != nil {
+			r.size = -1
+		}
+
+		if start != r.offset {
+			resp.Body.Close()
+
+			return errors.Wrap(errPackageURLInvalidResponse, r.url)
+		}
+
+		r.body = resp.Body
+
+		if r.next < maxRangeSize {
+			r.next *= 2
+		}
+	case http.StatusOK:
+		// Range requests are not supported: the content is read from the requested offset to the end.
+		if _, err = io.CopyN(io.Discard, resp.Body, r.offset); err != nil {
+			resp.Body.Close()
+
+			return err
+		}
+
+		r.body = resp.Body
+		r.ranged = false
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+
+		// The content ends at the requested offset.
+		r.size = r.offset
+	case http.StatusNotFound:
+		resp.Body.Close()
+
+		return errPackageURLNotFound
+	default:
+		resp.Body.Close()
+
+		return errors.Wrap(errPackageURLInvalidResponse, r.url)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package rpm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestRangeReader(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 1<<18)
+
+	tests := map[string]struct {
+		ranges bool
+	}{
+		"ranges supported":     {ranges: true},
+		"ranges not supported": {ranges: false},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var requests int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if !tt.ranges {
+					r.Header.Del("Range")
+				}
+
+				http.ServeContent(w, r, "kernel-devel.rpm", time.Time{}, bytes.NewReader(content))
+			}))
+			defer server.Close()
+
+			rr := newRangeReader(context.Background(), server.URL, minRangeSize)
+			defer rr.Close()
+
+			// Only the beginning of the content is read.
+			head := make([]byte, minRangeSize+10)
+			_, err := io.ReadFull(rr, head)
+			assert.NilError(t, err)
+			assert.Assert(t, bytes.Equal(head, content[:len(head)]))
+			assert.Equal(t, rr.Ranged(), tt.ranges)
+
+			rest, err := io.ReadAll(rr)
+			assert.NilError(t, err)
+			assert.Assert(t, bytes.Equal(rest, content[len(head):]))
+
+			if tt.ranges {
+				// 1 MiB, then 2 MiB, then the end of the content.
+				assert.Equal(t, requests, 3)
+			} else {
+				assert.Equal(t, requests, 1)
+			}
+		})
+	}
+}
@@ -9,22 +9,27 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strings"
 
+	"github.com/maxgio92/krawler/pkg/fetch"
 	"github.com/maxgio92/krawler/pkg/packages"
+	"github.com/maxgio92/krawler/pkg/signature"
 
 	"github.com/antchfx/xmlquery"
 	"github.com/pkg/errors"
 	rpmutils "github.com/sassoftware/go-rpmutils"
+	"golang.org/x/exp/slices"
 )
 
 // SearchPackages crawls packages from the specified repositories,
 // and returns a list of package of type Package with specified name.
-// func SearchPackages(repositoryURLs []*url.URL, packageName string, packageFileNames ...string) ([]Package, error) {
-func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
+// The search stops when ctx is done, returning the context error.
+func SearchPackages(ctx context.Context, so *SearchOptions) ([]packages.Package, error) {
 	var result []packages.Package
 
 	search := func(repoURL string) {
 		searchPackagesFromRepository(
+			ctx,
 			func() {
 				so.Progress(1)
 				so.SigProducerCompletion()
@@ -50,7 +55,7 @@ func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
 	// Run search producers.
 	for _, v := range so.SeedURLs() {
 		repoURL := v
-		go search(repoURL)
+		so.Go(ctx, func() { search(repoURL) })
 	}
 
 	// Run collect consumer.
@@ -59,83 +64,129 @@ func SearchPackages(so *SearchOptions) ([]packages.Package, error) {
 	// Wait for producers and consumers to complete and cleanup.
 	so.WaitAndClose()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
 // searchPackagesFromRepository crawls packages from specified repository as repositoryURL *URL,
 // sends them over a packagesCh Package channel and signals completion through a WaitGroup.
 // The waitGroup counter needs to be greater than zero.
-func searchPackagesFromRepository(doneFunc func(), so *SearchOptions, repoURL string) {
+func searchPackagesFromRepository(ctx context.Context, doneFunc func(), so *SearchOptions, repoURL string) {
 	defer doneFunc()
 
 	metadataURL, err := url.JoinPath(repoURL, metadataPath)
 	if err != nil {
-		so.SendError(err)
+		so.SendError(ctx, err)
 
 		return
 	}
 
 	so.Log().WithField("url", repoURL).Info("Analysing repository")
 
-	dbs, err := getPrimaryDBsFromMetadataURL(metadataURL)
+	dbs, revision, err := getPrimaryDBsFromMetadataURL(ctx, metadataURL, so.Keyring())
 	if err != nil {
-		so.SendError(err)
+		so.SendError(ctx, errors.Wrap(err, repoURL))
 
 		return
 	}
 
-	dbURLs := []string{}
+	// Skip the repository if unchanged since the last run.
+	key := packages.StateKey(repoURL, so.SearchOptions)
+	if records, ok := so.State().Lookup(key, revision); ok {
+		so.Log().WithField("url", repoURL).Info("Repository unchanged since the last run")
+		so.SendMessage(ctx, packagesFromRecords(so.State(), records, so.PackageFileNames())...)
+
+		return
+	}
+
+	var (
+		result   []packages.Package
+		complete = true
+	)
+
 	for _, db := range dbs {
+		if ctx.Err() != nil {
+			return
+		}
+
 		dbURL, _ := url.JoinPath(repoURL, db.GetLocation())
-		dbURLs = append(dbURLs, dbURL)
-	}
 
-	for _, dbURL := range dbURLs {
 		so.Log().WithField("url", dbURL).Info("Analysing DB")
-		searchPackagesFromDB(so, repoURL, dbURL)
+
+		ps, ok := searchPackagesFromDB(ctx, so, repoURL, dbURL, db.Checksum)
+		result = append(result, ps...)
+		complete = complete && ok
+	}
+
+	// Partial results are not recorded, for the repository to be crawled again by the next run.
+	if !complete || ctx.Err() != nil {
+		return
 	}
+
+	so.State().Update(key, revision, result)
 }
 
-//nolint:cyclop
-func getPrimaryDBsFromMetadataURL(metadataURL string) ([]Data, error) {
+// getPrimaryDBsFromMetadataURL returns the primary DBs listed in the repository metadata,
+// with the repository revision marker.
+// If a keyring is specified, the metadata detached signature (repomd.xml.asc) is verified.
+//
+//nolint:cyclop,funlen
+func getPrimaryDBsFromMetadataURL(ctx context.Context, metadataURL string, keyring signature.Keyring) ([]Data, string, error) {
 	var dbs []Data
 
 	u, err := url.Parse(metadataURL)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := fetch.Default().Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Wrap(errMetadataURLNotValid, metadataURL)
+		return nil, "", errors.Wrapf(errMetadataURLNotValid, "%s: HTTP status code %d", metadataURL, resp.StatusCode)
 	}
 
-	if resp.Body == nil {
-		return nil, errMetadataInvalidResponse
+	var metadata bytes.Buffer
+	if _, err = io.Copy(&metadata, resp.Body); err != nil {
+		return nil, "", err
+	}
+
+	if keyring != nil {
+		logger.Debug("Verifying repository metadata signature")
+
+		if err = verifyMetadata(ctx, keyring, metadataURL, metadata.Bytes()); err != nil {
+			return nil, "", err
+		}
 	}
-	defer resp.Body.Close()
 
 	logger.Debug("Parsing repository metadata")
 
-	doc, err := xmlquery.Parse(resp.Body)
+	doc, err := xmlquery.Parse(&metadata)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	var revision string
+	if n := xmlquery.FindOne(doc, metadataRevisionXPath); n != nil {
+		revision = n.InnerText()
 	}
 
 	logger.Debug("Getting repository DBs")
 
 	datasXML, err := xmlquery.QueryAll(doc, metadataDataXPath)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	for _, v := range datasXML {
@@ -143,7 +194,7 @@ func getPrimaryDBsFromMetadataURL(metadataURL string) ([]Data, error) {
 
 		err = xml.Unmarshal([]byte(v.OutputXML(true)), data)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		switch data.Type {
@@ -153,96 +204,136 @@ func getPrimaryDBsFromMetadataURL(metadataURL string) ([]Data, error) {
 		}
 	}
 
-	return dbs, nil
+	return dbs, repositoryRevision(revision, dbs), nil
 }
 
-// func searchPackagesFromDB(doneFunc func(), so *SearchOptions, repoURL, dbURL string) {
-func searchPackagesFromDB(so *SearchOptions, repoURL, dbURL string) {
-	xmlDB, err := getPackagesXMLDBFromURL(so, dbURL)
+// verifyMetadata verifies the repository metadata against its detached signature.
+// A missing signature fails the verification.
+func verifyMetadata(ctx context.Context, keyring signature.Keyring, metadataURL string, metadata []byte) error {
+	signatureURL := metadataURL + metadataSignatureSuffix
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signatureURL, nil)
 	if err != nil {
-		so.SendError(err)
+		return err
 	}
 
-	queue := packages.NewMPSCQueue(len(xmlDB))
+	resp, err := fetch.Default().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var sig bytes.Buffer
+
+	if resp.StatusCode == http.StatusOK {
+		if _, err = io.Copy(&sig, resp.Body); err != nil {
+			return err
+		}
+	}
+
+	if err = signature.VerifyDetached(keyring, metadata, sig.Bytes()); err != nil {
+		return errors.Wrap(err, metadataURL)
+	}
+
+	return nil
+}
+
+// searchPackagesFromDB sends the packages found in the primary DB, and returns them,
+// with whether the DB has been crawled without errors.
+func searchPackagesFromDB(ctx context.Context, so *SearchOptions, repoURL, dbURL string, checksum Checksum) ([]packages.Package, bool) {
+	xmlDB, err := getPackagesXMLDBFromURL(ctx, so, dbURL, checksum)
+	if err != nil {
+		so.SendError(ctx, err)
+
+		return nil, false
+	}
+
+	var (
+		result   []packages.Package
+		complete = true
+	)
+
+	queue := packages.NewMPSCQueue(len(xmlDB), so.Parallelism())
 
 	for _, v := range xmlDB {
 		node := v
 
-		go func() {
+		queue.Go(ctx, func() {
 			defer queue.SigProducerCompletion()
 
 			p := &Package{}
 
 			err := xml.Unmarshal([]byte(node.OutputXML(true)), p)
 			if err != nil {
-				queue.SendError(err)
+				queue.SendError(ctx, err)
 
 				return
 			}
 
 			p.url, err = url.JoinPath(repoURL, p.GetLocation())
 			if err != nil {
-				queue.SendError(err)
+				queue.SendError(ctx, err)
 
 				return
 			}
 
-			so.Log().WithField("fullname", filepath.Base(p.GetLocation())).Debug("Opening package")
-
-			fileReaders, err := getFileReadersFromPackageURL(p.url, so.PackageFileNames()...)
-			if err != nil {
-				queue.SendError(err)
-
-				return
-			}
-
-			p.fileReaders = fileReaders
+			p.fileNames = so.PackageFileNames()
 
 			so.Log().WithField("version", p.Version.Ver).WithField("release", p.Version.Rel).WithField("name", p.Name).Debug("found package")
-			queue.SendMessage(p)
-		}()
+			queue.SendMessage(ctx, p)
+		})
 	}
 
 	go func() {
 		queue.Consume(
 			func(p ...packages.Package) {
-				so.SendMessage(p...)
+				p = so.State().Track(p...)
+				result = append(result, p...)
+				so.SendMessage(ctx, p...)
 			},
 			func(e error) {
+				complete = false
 				so.Log().Error(e)
 			},
 		)
 	}()
 
 	queue.WaitAndClose()
+
+	return result, complete
 }
 
-func getPackagesXMLDBFromURL(so *SearchOptions, dbURL string) ([]*xmlquery.Node, error) {
+// getPackagesXMLDBFromURL returns the packages from the primary DB, verifying the DB against its checksum,
+// if declared by the repository metadata.
+//
+//nolint:cyclop
+func getPackagesXMLDBFromURL(ctx context.Context, so *SearchOptions, dbURL string, checksum Checksum) ([]*xmlquery.Node, error) {
 	u, err := url.Parse(dbURL)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := fetch.Default().Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Wrap(errRepositoryURLNotValid, u.String())
+		return nil, errors.Wrapf(errRepositoryURLNotValid, "%s: HTTP status code %d", u.String(), resp.StatusCode)
 	}
 
-	if resp.Body == nil {
-		return nil, errRepositoryInvalidResponse
+	body, verify, err := withChecksum(resp.Body, dbURL, checksum)
+	if err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	gr, err := gzip.NewReader(resp.Body)
+	gr, err := gzip.NewReader(body)
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +344,7 @@ func getPackagesXMLDBFromURL(so *SearchOptions, dbURL string) ([]*xmlquery.Node,
 	sp, err := xmlquery.CreateStreamParser(
 		gr,
 		dataPackageXPath,
-		dataPackageXPath+"[name='"+so.PackageName()+"']")
+		dataPackageXPath+"["+namesPredicate(so.PackageNames())+"]")
 	if err != nil {
 		return nil, err
 	}
@@ -267,23 +358,103 @@ func getPackagesXMLDBFromURL(so *SearchOptions, dbURL string) ([]*xmlquery.Node,
 		packagesXML = append(packagesXML, n)
 	}
 
+	if err = verify(); err != nil {
+		return nil, err
+	}
+
 	return packagesXML, nil
 }
 
-func getFileReadersFromPackageURL(packageURL string, fileNames ...string) ([]io.Reader, error) {
+// namesPredicate returns the XPath predicate of the packages with any of the names.
+func namesPredicate(names []string) string {
+	predicates := make([]string, 0, len(names))
+
+	for _, name := range names {
+		predicates = append(predicates, "name='"+name+"'")
+	}
+
+	return strings.Join(predicates, " or ")
+}
+
+// walkPackageFiles opens the package, and visits the package files as they are read.
+// If specific files are looked for, the package is read with range requests, up to the files,
+// starting with the headers and the beginning of the payload (headerEnd is the payload offset, if known).
+// As the package is not downloaded entirely, the files are verified against the digests declared
+// by the package header, rather than the package against its checksum.
+// If range requests are not supported, the package is downloaded entirely, and verified against
+// its checksum, if declared by the repository metadata.
+// The content is verified once read: on mismatch, the error is returned after visiting the files.
+//
+//nolint:cyclop
+func walkPackageFiles(ctx context.Context, packageURL string, checksum Checksum, headerEnd int64, fileNames []string, visit packages.FileVisitor) error {
 	u, err := url.Parse(packageURL)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	logger.WithField("url", u.String()).Debug("Downloading package")
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u.String(), nil)
+	var content io.ReadCloser
+
+	if len(fileNames) > 0 {
+		content = newRangeReader(ctx, u.String(), headerEnd+minRangeSize)
+	} else {
+		content, err = getPackage(ctx, u.String())
+		if err != nil {
+			return err
+		}
+	}
+	defer content.Close()
+
+	body, verify, err := withChecksum(content, packageURL, checksum)
+	if err != nil {
+		return err
+	}
+
+	// Only the package downloaded entirely can be verified against its checksum.
+	if rr, ok := content.(*rangeReader); ok {
+		verifyPackage := verify
+
+		verify = func() error {
+			if rr.Ranged() {
+				return nil
+			}
+
+			return verifyPackage()
+		}
+	}
+
+	rpm, err := rpmutils.ReadRpm(body)
+	if err != nil {
+		// Corrupt content is reported as such, rather than as a parse error.
+		if verr := verify(); verr != nil {
+			return verr
+		}
+
+		logger.WithError(err).Debug("Error parsing package")
+
+		return err
+	}
+
+	if err = walkRPMUtilFiles(packageURL, rpm, fileNames, visit); err != nil {
+		if verr := verify(); verr != nil {
+			return verr
+		}
+
+		return err
+	}
+
+	return verify()
+}
+
+// getPackage downloads the package, and returns its body.
+func getPackage(ctx context.Context, packageURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, packageURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := fetch.Default().Do(req)
 	if err != nil {
 		logger.WithError(err).Debug("Error downloading package")
 
@@ -291,81 +462,103 @@ func getFileReadersFromPackageURL(packageURL string, fileNames ...string) ([]io.
 	}
 
 	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+
 		return nil, errPackageURLNotFound
 	}
 
 	if resp.Body == nil {
 		return nil, errPackageURLInvalidResponse
 	}
-	defer resp.Body.Close()
 
-	rpm, err := rpmutils.ReadRpm(resp.Body)
-	if err != nil {
-		logger.WithError(err).Debug("Error parsing package")
+	return resp.Body, nil
+}
 
-		return nil, err
+// withChecksum returns a reader of the content downloaded from the URL, with a function
+// to verify the content against the checksum once read.
+// If no checksum is declared, the content is not verified.
+func withChecksum(r io.Reader, u string, checksum Checksum) (io.Reader, func() error, error) {
+	if checksum.Value == "" {
+		return r, func() error { return nil }, nil
 	}
 
-	fileReaders, err := getFileReadersFromRPMUtil(rpm, fileNames...)
+	cr, err := packages.NewChecksumReader(r, u, checksum.Type, strings.TrimSpace(checksum.Value))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return fileReaders, nil
+	return cr, cr.Verify, nil
 }
 
-//nolint:cyclop
-func getFileReadersFromRPMUtil(util *rpmutils.Rpm, names ...string) ([]io.Reader, error) {
+// walkRPMUtilFiles visits the package files with the names, or all of them if none is specified,
+// verifying each one against its digest once visited.
+func walkRPMUtilFiles(packageURL string, util *rpmutils.Rpm, names []string, visit packages.FileVisitor) error {
 	payload, err := util.PayloadReaderExtended()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var readers []io.Reader
-
-	fileName := ""
-	limited := len(names) > 0
-
 	logger.WithField("files", names).Debug("Looking for files")
 
+	found := 0
+
 	for {
 		fileInfo, err := payload.Next()
 		if errors.Is(err, io.EOF) {
-			break
+			return nil
 		}
 
-		if limited {
-			fileName = names[len(names)-1]
+		if err != nil {
+			return err
 		}
 
-		//nolint:nestif
-		if fileName == "" || filepath.Base(fileInfo.Name()) == fileName {
-			logger.WithField("name", fileName).Debug("Found file")
-
-			var buf bytes.Buffer
+		if len(names) > 0 && !slices.Contains(names, filepath.Base(fileInfo.Name())) {
+			continue
+		}
 
-			_, err = io.Copy(&buf, payload)
-			if err != nil {
-				return nil, err
-			}
+		logger.WithField("name", fileInfo.Name()).Debug("Found file")
 
-			r := bytes.NewReader(buf.Bytes())
+		content, verify := withFileDigest(packageURL, util.Header, fileInfo, payload)
 
-			_, err := r.Seek(0, io.SeekStart)
-			if err != nil {
-				return nil, err
-			}
+		skip, err := packages.VisitFile(visit, fileInfo.Name(), content)
+		if verr := verify(); verr != nil {
+			return verr
+		}
 
-			readers = append(readers, r)
+		found++
 
-			if limited {
-				names = names[:len(names)-1]
-				if len(names) == 0 {
-					return readers, nil
-				}
-			}
+		if skip || (len(names) > 0 && found == len(names)) {
+			return err
 		}
 	}
+}
+
+// withFileDigest returns a reader of the package file content, with a function to verify the content
+// against its digest, declared by the package header, once read.
+// Files without digest, or with a digest algorithm not supported, are not verified.
+func withFileDigest(packageURL string, header *rpmutils.RpmHeader, fileInfo rpmutils.FileInfo, r io.Reader) (io.Reader, func() error) {
+	if fileInfo.Digest() == "" {
+		return r, func() error { return nil }
+	}
+
+	// The digest algorithm is MD5, unless declared otherwise.
+	algorithm := fileDigestAlgorithms[rpmutils.PGPHASHALGO_MD5]
+	if v, err := header.GetInt(rpmutils.FILEDIGESTALGO); err == nil {
+		algorithm = fileDigestAlgorithms[v]
+	}
+
+	cr, err := packages.NewChecksumReader(r, packageURL, algorithm, fileInfo.Digest())
+	if err != nil {
+		logger.WithError(err).WithField("name", fileInfo.Name()).Debug("Not verifying file digest")
+
+		return r, func() error { return nil }
+	}
+
+	return cr, func() error {
+		if err := cr.Verify(); err != nil {
+			return errors.Wrap(err, fileInfo.Name())
+		}
 
-	return readers, nil
+		return nil
+	}
 }
@@ -11,7 +11,7 @@ type SearchOptions struct {
 // NewSearchOptions returns a pointer to a SearchOptions object from a pointer to a packages.SearchOptions, and
 // overriding architectures and seedURLs.
 func NewSearchOptions(options *packages.SearchOptions, architectures []packages.Architecture, seedURLs []string) *SearchOptions {
-	return &SearchOptions{
+	so := &SearchOptions{
 		packages.NewSearchOptions(
 			options.PackageName(),
 			architectures,
@@ -21,4 +21,10 @@ func NewSearchOptions(options *packages.SearchOptions, architectures []packages.
 			options.PackageFileNames()...,
 		),
 	}
+	so.SetRelatedPackageNames(options.RelatedPackageNames()...)
+	so.SetKeyring(options.Keyring())
+	so.SetState(options.State())
+	so.SetParallelism(options.Parallelism())
+
+	return so
 }
@@ -0,0 +1,48 @@
+package rpm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/maxgio92/krawler/pkg/packages"
+)
+
+// repositoryRevision returns the revision marker of the repository, from the revision
+// declared by the repository metadata and the checksums of the primary DBs, as the revision is optional.
+func repositoryRevision(revision string, dbs []Data) string {
+	marker := []string{strings.TrimSpace(revision)}
+
+	for _, db := range dbs {
+		if db.Checksum.Value == "" {
+			// The DB changes cannot be tracked.
+			return ""
+		}
+
+		marker = append(marker, db.Checksum.Type+":"+strings.TrimSpace(db.Checksum.Value))
+	}
+
+	return strings.Join(marker, " ")
+}
+
+// packagesFromRecords returns the packages found by a previous run, from their records in the state.
+// The package files not recorded are looked for in the packages.
+func packagesFromRecords(state *packages.State, records []*packages.PackageRecord, fileNames []string) []packages.Package {
+	ps := make([]packages.Package, 0, len(records))
+
+	for _, r := range records {
+		algorithm, checksum, _ := strings.Cut(r.Checksum, ":")
+
+		ps = append(ps, state.Restore(r, &Package{
+			Name:      r.Name,
+			Arch:      r.Arch,
+			Version:   PackageVersion{Epoch: r.Epoch, Ver: r.Version, Rel: r.Release},
+			Checksum:  Checksum{Type: algorithm, Value: checksum},
+			Size:      PackageSize{Package: strconv.FormatInt(r.Size, 10)},
+			Location:  PackageLocation{Href: r.Location},
+			url:       r.URL,
+			fileNames: fileNames,
+		}))
+	}
+
+	return ps
+}
@@ -2,6 +2,7 @@ package rpm
 
 import (
 	"encoding/xml"
+	"strconv"
 )
 
 type RepositoryMetadata struct {
@@ -54,6 +55,16 @@ type PackageHeaderRange struct {
 	End   string `xml:"end,attr"`
 }
 
+// PayloadOffset returns the offset of the package payload, following the headers, or zero if unknown.
+func (r PackageHeaderRange) PayloadOffset() int64 {
+	end, err := strconv.ParseInt(r.End, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return end
+}
+
 type PackageProvides struct {
 	XMLName xml.Name `xml:"provides"`
 	Entries []Entry  `xml:"entry"`
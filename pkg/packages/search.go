@@ -4,10 +4,15 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/maxgio92/krawler/pkg/output"
+	"github.com/maxgio92/krawler/pkg/signature"
 )
 
 type SearchOptions struct {
-	packageName      string
+	packageName string
+
+	// The names of the packages related to the package searched (e.g. the kernel image), searched along with it.
+	relatedPackageNames []string
+
 	architectures    []Architecture
 	packageFileNames []string
 	seedURLs         []string
@@ -16,6 +21,17 @@ type SearchOptions struct {
 	*MPSCQueue
 	verbosity output.Verbosity
 	logger    *output.Logger
+
+	// The keyring to verify repository metadata against.
+	// Verification is disabled if empty.
+	keyring signature.Keyring
+
+	// The state of the repositories crawled by previous runs, to skip the unchanged ones.
+	// Incremental crawling is disabled if nil.
+	state *State
+
+	// The maximum number of producers of the search running at once.
+	parallelism int
 }
 
 func NewSearchOptions(packageName string, architectures []Architecture, seedURLs []string, verbosity output.Verbosity, progressMessage string, packageFileNames ...string) *SearchOptions {
@@ -28,7 +44,7 @@ func NewSearchOptions(packageName string, architectures []Architecture, seedURLs
 
 	progressOptions := output.NewProgressOptions(len(seedURLs), progressMessage)
 
-	queue := NewMPSCQueue(len(seedURLs))
+	queue := NewMPSCQueue(len(seedURLs), DefaultParallelism)
 
 	return &SearchOptions{
 		packageName:      packageName,
@@ -40,6 +56,7 @@ func NewSearchOptions(packageName string, architectures []Architecture, seedURLs
 		MPSCQueue:        queue,
 		verbosity:        verbosity,
 		logger:           logger,
+		parallelism:      DefaultParallelism,
 	}
 }
 
@@ -47,6 +64,22 @@ func (o *SearchOptions) PackageName() string {
 	return o.packageName
 }
 
+// RelatedPackageNames returns the names of the packages related to the package searched.
+func (o *SearchOptions) RelatedPackageNames() []string {
+	return o.relatedPackageNames
+}
+
+// SetRelatedPackageNames sets the names of the packages related to the package searched,
+// to search them along with it, matched as the package name.
+func (o *SearchOptions) SetRelatedPackageNames(names ...string) {
+	o.relatedPackageNames = names
+}
+
+// PackageNames returns the names of the package searched, and of the packages related to it.
+func (o *SearchOptions) PackageNames() []string {
+	return append([]string{o.packageName}, o.relatedPackageNames...)
+}
+
 func (o *SearchOptions) PackageFileNames() []string {
 	return o.packageFileNames
 }
@@ -70,3 +103,36 @@ func (o *SearchOptions) Architectures() []Architecture {
 func (o *SearchOptions) ProgressMessage() string {
 	return o.progressMessage
 }
+
+func (o *SearchOptions) Keyring() signature.Keyring {
+	return o.keyring
+}
+
+// SetKeyring sets the keyring to verify repository metadata against.
+func (o *SearchOptions) SetKeyring(keyring signature.Keyring) {
+	o.keyring = keyring
+}
+
+func (o *SearchOptions) State() *State {
+	return o.state
+}
+
+// SetState sets the state of the repositories crawled by previous runs.
+func (o *SearchOptions) SetState(state *State) {
+	o.state = state
+}
+
+func (o *SearchOptions) Parallelism() int {
+	return o.parallelism
+}
+
+// SetParallelism sets the maximum number of producers of the search running at once.
+// It must be set before any producer is run. A non-positive value restores the default.
+func (o *SearchOptions) SetParallelism(n int) {
+	if n <= 0 {
+		n = DefaultParallelism
+	}
+
+	o.parallelism = n
+	o.MPSCQueue.workers = make(chan struct{}, n)
+}
@@ -0,0 +1,484 @@
+package packages
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The suffix of the directory of the package files recorded by the state, next to the state file.
+const stateFilesSuffix = ".files"
+
+// State is the crawling state of the repositories, persisted across runs in a state file,
+// for the repositories unchanged since the last run not to be crawled again.
+// A repository is unchanged if its revision marker, as published in its metadata
+// (e.g. the repomd.xml revision), did not change.
+// The contents of the package files visited are spilled to the files directory of the state,
+// as they're visited, for them not to be held in memory.
+// A nil State is valid, and it has no repositories.
+type State struct {
+	path string
+
+	mu           sync.Mutex
+	Repositories map[string]*RepositoryState `json:"repositories"`
+}
+
+// RepositoryState is the revision marker of a repository, with the packages found at that revision.
+type RepositoryState struct {
+	Revision string           `json:"revision"`
+	Updated  time.Time        `json:"updated"`
+	Packages []*PackageRecord `json:"packages"`
+}
+
+// PackageRecord is the persisted form of a package, including its files, once visited.
+type PackageRecord struct {
+	Name     string `json:"name"`
+	Epoch    string `json:"epoch,omitempty"`
+	Version  string `json:"version"`
+	Release  string `json:"release,omitempty"`
+	Arch     string `json:"arch"`
+	Location string `json:"location,omitempty"`
+	URL      string `json:"url"`
+	Checksum string `json:"checksum,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+
+	// The URLs of the packages the package depends on, if declared.
+	Dependencies []string `json:"dependencies,omitempty"`
+
+	// The files are null until visited, and empty if the package has none of the files looked for.
+	Files []*FileRecord `json:"package_files"`
+}
+
+// FileRecord is the persisted form of a package file, whose content is stored in the files
+// directory of the state, named after its SHA-256 digest.
+type FileRecord struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
+// NewState returns an empty state, to be persisted in the state file.
+func NewState(path string) *State {
+	return &State{path: path, Repositories: map[string]*RepositoryState{}}
+}
+
+// LoadState returns the state persisted in the state file, gzip-compressed JSON.
+// If the state file does not exist, the state is empty.
+func LoadState(path string) (*State, error) {
+	s := NewState(path)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening state file")
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	defer gr.Close()
+
+	if err = json.NewDecoder(gr).Decode(s); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	if s.Repositories == nil {
+		s.Repositories = map[string]*RepositoryState{}
+	}
+
+	return s, nil
+}
+
+// Save persists the state in the state file.
+func (s *State) Save() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-")
+	if err != nil {
+		return errors.Wrap(err, "error creating state file")
+	}
+	defer os.Remove(f.Name())
+
+	gw := gzip.NewWriter(f)
+
+	err = json.NewEncoder(gw).Encode(s)
+	if err == nil {
+		err = gw.Close()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "error writing state file")
+	}
+
+	if err = os.Rename(f.Name(), s.path); err != nil {
+		return err
+	}
+
+	return s.removeUnrecordedFiles()
+}
+
+// RemoveState removes the state file, and the package files it records.
+func RemoveState(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return os.RemoveAll(path + stateFilesSuffix)
+}
+
+// removeUnrecordedFiles removes the package files no longer recorded by the state,
+// for the files directory not to grow across runs.
+func (s *State) removeUnrecordedFiles() error {
+	entries, err := os.ReadDir(s.filesDir())
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	recorded := map[string]bool{}
+
+	for _, r := range s.Repositories {
+		for _, p := range r.Packages {
+			for _, f := range p.Files {
+				recorded[f.Digest] = true
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if recorded[e.Name()] {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(s.filesDir(), e.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *State) filesDir() string {
+	return s.path + stateFilesSuffix
+}
+
+// openFiles returns the recorded files, opened, if all of them are stored.
+func (s *State) openFiles(files []*FileRecord) ([]*os.File, bool) {
+	opened := make([]*os.File, 0, len(files))
+
+	for _, f := range files {
+		if f.Digest == "" {
+			closeFiles(opened)
+
+			return nil, false
+		}
+
+		file, err := os.Open(filepath.Join(s.filesDir(), f.Digest))
+		if err != nil {
+			closeFiles(opened)
+
+			return nil, false
+		}
+
+		opened = append(opened, file)
+	}
+
+	return opened, true
+}
+
+// storeFile stores the content read through the returned reader in the files directory,
+// once committed, named after its digest.
+func (s *State) storeFile(content io.Reader) (io.Reader, *fileSpill) {
+	spill := &fileSpill{hash: sha256.New()}
+
+	if err := os.MkdirAll(s.filesDir(), 0o755); err != nil {
+		spill.err = err
+	} else if spill.file, err = os.CreateTemp(s.filesDir(), ".tmp-"); err != nil {
+		spill.err = err
+	}
+
+	return io.TeeReader(content, spill), spill
+}
+
+// fileSpill writes the content of a package file to a temporary file of the files directory,
+// computing its digest. Write errors are deferred to commit, for the visit not to fail because of them.
+type fileSpill struct {
+	file *os.File
+	hash hash.Hash
+	err  error
+}
+
+func (f *fileSpill) Write(p []byte) (int, error) {
+	f.hash.Write(p)
+
+	if f.err == nil {
+		_, f.err = f.file.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// commit renames the temporary file after the content digest, and returns it.
+func (f *fileSpill) commit(dir string) (string, error) {
+	if f.file == nil {
+		return "", f.err
+	}
+
+	defer os.Remove(f.file.Name())
+
+	if err := f.file.Close(); f.err == nil {
+		f.err = err
+	}
+
+	if f.err != nil {
+		return "", f.err
+	}
+
+	digest := hex.EncodeToString(f.hash.Sum(nil))
+
+	return digest, os.Rename(f.file.Name(), filepath.Join(dir, digest))
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// Lookup returns the packages of the repository identified by key, if its revision did not change.
+// An empty revision never matches, as the repository changes cannot be tracked.
+func (s *State) Lookup(key, revision string) ([]*PackageRecord, bool) {
+	if s == nil || revision == "" {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.Repositories[key]
+	if !ok || r.Revision != revision {
+		return nil, false
+	}
+
+	return r.Packages, true
+}
+
+// Update sets the revision of the repository identified by key, with the packages found.
+// The files of the packages tracked by the state are recorded once visited.
+func (s *State) Update(key, revision string, ps []Package) {
+	if s == nil || revision == "" {
+		return
+	}
+
+	records := make([]*PackageRecord, 0, len(ps))
+
+	for _, p := range ps {
+		if rp, ok := p.(*recordedPackage); ok && rp.state == s {
+			records = append(records, rp.record)
+
+			continue
+		}
+
+		records = append(records, NewPackageRecord(p))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Repositories[key] = &RepositoryState{
+		Revision: revision,
+		Updated:  time.Now(),
+		Packages: records,
+	}
+}
+
+// Track returns the packages, for their files to be recorded in the state when visited.
+func (s *State) Track(ps ...Package) []Package {
+	if s == nil {
+		return ps
+	}
+
+	tracked := make([]Package, 0, len(ps))
+
+	for _, p := range ps {
+		tracked = append(tracked, &recordedPackage{Package: p, state: s, record: NewPackageRecord(p)})
+	}
+
+	return tracked
+}
+
+// Restore returns the package found by a previous run, for its files to be visited
+// from the record, if recorded, and otherwise from the package, and recorded.
+func (s *State) Restore(record *PackageRecord, p Package) Package {
+	if s == nil {
+		return p
+	}
+
+	return &recordedPackage{Package: p, state: s, record: record}
+}
+
+// StateKey returns the key of the repository in the state, for the search options,
+// as the packages found depend on them.
+func StateKey(repoURL string, so *SearchOptions) string {
+	return fmt.Sprintf("%s %v %v %v", repoURL, so.PackageNames(), so.Architectures(), so.PackageFileNames())
+}
+
+// NewPackageRecord returns the record of the package, without its files.
+func NewPackageRecord(p Package) *PackageRecord {
+	r := &PackageRecord{
+		Name:     p.GetName(),
+		Version:  p.GetVersion(),
+		Release:  p.GetRelease(),
+		Arch:     p.GetArch(),
+		Location: p.GetLocation(),
+		URL:      p.URL(),
+	}
+
+	if e, ok := p.(Epoched); ok {
+		r.Epoch = e.GetEpoch()
+	}
+
+	if d, ok := p.(Downloadable); ok {
+		r.Checksum, r.Size = d.GetChecksum(), d.GetSize()
+	}
+
+	if d, ok := p.(Dependent); ok {
+		r.Dependencies = d.GetDependencies()
+	}
+
+	return r
+}
+
+// recordedPackage is a package whose files are recorded in the state when visited,
+// and then visited from the record.
+type recordedPackage struct {
+	Package
+	state  *State
+	record *PackageRecord
+}
+
+func (p *recordedPackage) Unwrap() Package {
+	return p.Package
+}
+
+func (p *recordedPackage) GetFlavour() string {
+	if f, ok := p.Package.(Flavoured); ok {
+		return f.GetFlavour()
+	}
+
+	return ""
+}
+
+func (p *recordedPackage) GetVersionScheme() string {
+	if v, ok := p.Package.(Versioned); ok {
+		return v.GetVersionScheme()
+	}
+
+	return ""
+}
+
+func (p *recordedPackage) GetEpoch() string {
+	return p.record.Epoch
+}
+
+func (p *recordedPackage) GetChecksum() string {
+	return p.record.Checksum
+}
+
+func (p *recordedPackage) GetSize() int64 {
+	return p.record.Size
+}
+
+func (p *recordedPackage) GetDependencies() []string {
+	return p.record.Dependencies
+}
+
+// Files visits the files from the record, if all of them are stored, and otherwise from the package,
+// storing them as they're visited.
+func (p *recordedPackage) Files(ctx context.Context, visit FileVisitor) error {
+	p.state.mu.Lock()
+	files := p.record.Files
+	p.state.mu.Unlock()
+
+	if files != nil {
+		if opened, ok := p.state.openFiles(files); ok {
+			defer closeFiles(opened)
+
+			for i, f := range files {
+				if skip, err := VisitFile(visit, f.Name, opened[i]); skip {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	// All the files are recorded, even if the visitor skips some, for them to be visited
+	// from the record by the next runs. Files which cannot be stored are not recorded.
+	files = []*FileRecord{}
+	skip := false
+	stored := true
+
+	err := p.Package.Files(ctx, func(name string, content io.Reader) error {
+		tee, spill := p.state.storeFile(content)
+
+		if !skip {
+			var err error
+			if skip, err = VisitFile(visit, name, tee); err != nil {
+				return err
+			}
+		}
+
+		if _, err := io.Copy(io.Discard, tee); err != nil {
+			return err
+		}
+
+		digest, err := spill.commit(p.state.filesDir())
+		if err != nil {
+			stored = false
+
+			return nil
+		}
+
+		files = append(files, &FileRecord{Name: name, Digest: digest})
+
+		return nil
+	})
+	if err != nil || !stored {
+		return err
+	}
+
+	p.state.mu.Lock()
+	p.record.Files = files
+	p.state.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package packages
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type testFile struct {
+	name    string
+	content string
+}
+
+type testFilePackage struct {
+	testPackage
+	files []testFile
+	opens int
+}
+
+func (p *testFilePackage) GetName() string     { return p.name }
+func (p *testFilePackage) GetVersion() string  { return "5.10.0" }
+func (p *testFilePackage) GetEpoch() string    { return "2" }
+func (p *testFilePackage) GetRelease() string  { return "1" }
+func (p *testFilePackage) GetArch() string     { return "x86_64" }
+func (p *testFilePackage) GetLocation() string { return "kernel-devel.rpm" }
+func (p *testFilePackage) URL() string         { return "https://example.com/kernel-devel.rpm" }
+func (p *testFilePackage) GetChecksum() string { return "sha256:0123" }
+func (p *testFilePackage) GetSize() int64      { return 1024 }
+
+func (p *testFilePackage) GetDependencies() []string {
+	return []string{"https://example.com/kernel-headers.rpm"}
+}
+
+func (p *testFilePackage) Files(_ context.Context, visit FileVisitor) error {
+	p.opens++
+
+	for _, f := range p.files {
+		if skip, err := VisitFile(visit, f.name, strings.NewReader(f.content)); skip {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFirstFile visits the package files, reading only the first line of the first one.
+func readFirstFile(t *testing.T, p Package) string {
+	t.Helper()
+
+	var line string
+
+	err := p.Files(context.Background(), func(_ string, content io.Reader) error {
+		buf := make([]byte, len("CONFIG_64BIT=y\n"))
+		_, err := io.ReadFull(content, buf)
+		line = string(buf)
+
+		if err != nil {
+			return err
+		}
+
+		return ErrSkipFiles
+	})
+	assert.NilError(t, err)
+
+	return line
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json.gz")
+
+	state, err := LoadState(path)
+	assert.NilError(t, err)
+
+	_, ok := state.Lookup("repo", "1")
+	assert.Assert(t, !ok)
+
+	p := &testFilePackage{
+		testPackage: testPackage{name: "kernel-devel"},
+		files: []testFile{
+			{name: "config", content: "CONFIG_64BIT=y\nCONFIG_GCC_VERSION=120200\n"},
+			{name: "Makefile", content: "VERSION = 5\n"},
+		},
+	}
+
+	tracked := state.Track(p)
+	state.Update("repo", "1", tracked)
+
+	// The files are recorded once visited, even if partially read, or skipped.
+	assert.Equal(t, readFirstFile(t, tracked[0]), "CONFIG_64BIT=y\n")
+	assert.NilError(t, state.Save())
+
+	state, err = LoadState(path)
+	assert.NilError(t, err)
+
+	_, ok = state.Lookup("repo", "2")
+	assert.Assert(t, !ok)
+
+	records, ok := state.Lookup("repo", "1")
+	assert.Assert(t, ok)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Name, "kernel-devel")
+	assert.Equal(t, records[0].Epoch, "2")
+	assert.Equal(t, records[0].URL, "https://example.com/kernel-devel.rpm")
+	assert.Equal(t, records[0].Checksum, "sha256:0123")
+	assert.Equal(t, records[0].Size, int64(1024))
+	assert.DeepEqual(t, records[0].Dependencies, []string{"https://example.com/kernel-headers.rpm"})
+	assert.Equal(t, len(records[0].Files), 2)
+
+	// The file contents are stored next to the state file, rather than in it.
+	for i, f := range records[0].Files {
+		assert.Equal(t, f.Name, p.files[i].name)
+
+		content, err := os.ReadFile(filepath.Join(path+stateFilesSuffix, f.Digest))
+		assert.NilError(t, err)
+		assert.Equal(t, string(content), p.files[i].content)
+	}
+
+	// The files are visited from the record, without opening the package.
+	restored := state.Restore(records[0], p)
+	assert.Equal(t, readFirstFile(t, restored), "CONFIG_64BIT=y\n")
+	assert.Equal(t, p.opens, 1)
+	assert.Equal(t, restored.(Epoched).GetEpoch(), "2")
+	assert.Equal(t, restored.(Downloadable).GetChecksum(), "sha256:0123")
+	assert.Equal(t, restored.(Downloadable).GetSize(), int64(1024))
+	assert.DeepEqual(t, restored.(Dependent).GetDependencies(), []string{"https://example.com/kernel-headers.rpm"})
+	assert.Equal(t, Unwrap(restored), Package(p))
+
+	// The files no longer stored are visited from the package again.
+	assert.NilError(t, os.Remove(filepath.Join(path+stateFilesSuffix, records[0].Files[1].Digest)))
+	assert.Equal(t, readFirstFile(t, state.Restore(records[0], p)), "CONFIG_64BIT=y\n")
+	assert.Equal(t, p.opens, 2)
+
+	// The files no longer recorded are removed.
+	state.Update("repo", "2", nil)
+	assert.NilError(t, state.Save())
+
+	entries, err := os.ReadDir(path + stateFilesSuffix)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+
+	assert.NilError(t, RemoveState(path))
+
+	_, err = os.Stat(path + stateFilesSuffix)
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestStateUntracked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json.gz")
+
+	state := NewState(path)
+	p := &testFilePackage{}
+
+	// Repositories without revision are not recorded.
+	state.Update("repo", "", state.Track(p))
+
+	_, ok := state.Lookup("repo", "")
+	assert.Assert(t, !ok)
+
+	// A nil state is disabled.
+	var disabled *State
+
+	assert.Equal(t, disabled.Track(p)[0], Package(p))
+	disabled.Update("repo", "1", nil)
+	assert.NilError(t, disabled.Save())
+
+	_, err := os.Stat(path)
+	assert.Assert(t, os.IsNotExist(err))
+}
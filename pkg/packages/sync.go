@@ -1,9 +1,13 @@
 package packages
 
 import (
+	"context"
 	"sync"
 )
 
+// DefaultParallelism is the default maximum number of producers of a queue running at once.
+const DefaultParallelism = 16
+
 // MPSCQueue provides an option set to manage a sync group of multiple producer workers and
 // single consumer, leveraging Go sync.WaitGroup and channels to notify errors, results and completion
 // of consuming the results from the single consumer worker.
@@ -12,11 +16,18 @@ type MPSCQueue struct {
 	consumerDoneCh chan bool
 	msgCh          chan []Package
 	errCh          chan error
+	workers        chan struct{}
 }
 
-func NewMPSCQueue(parallelism int) *MPSCQueue {
+// NewMPSCQueue returns a queue for the number of producers, running up to parallelism of them at once.
+// A non-positive parallelism is the default.
+func NewMPSCQueue(producers int, parallelism int) *MPSCQueue {
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
 	wg := &sync.WaitGroup{}
-	wg.Add(parallelism)
+	wg.Add(producers)
 
 	msgCh := make(chan []Package)
 
@@ -29,24 +40,48 @@ func NewMPSCQueue(parallelism int) *MPSCQueue {
 		consumerDoneCh: doneCh,
 		msgCh:          msgCh,
 		errCh:          errCh,
+		workers:        make(chan struct{}, parallelism),
 	}
 }
 
+// Go runs the producer in a new goroutine, as soon as one of the queue workers is available.
+// Producers still signal their completion. If ctx is done while waiting for a worker,
+// the producer is run anyway, for it to complete without doing work.
+func (q *MPSCQueue) Go(ctx context.Context, producer func()) {
+	go func() {
+		select {
+		case q.workers <- struct{}{}:
+			defer func() { <-q.workers }()
+		case <-ctx.Done():
+		}
+
+		producer()
+	}()
+}
+
 // SendMessage sends a message as variadic parameter msg of type packages.Package to the messages queue.
-func (q *MPSCQueue) SendMessage(msg ...Package) {
-	q.msgCh <- msg
+// If ctx is done before the consumer receives it, the message is dropped, for the producer not to block.
+func (q *MPSCQueue) SendMessage(ctx context.Context, msg ...Package) {
+	select {
+	case q.msgCh <- msg:
+	case <-ctx.Done():
+	}
 }
 
 // SendMessageAndComplete sends a message as variadic parameter msg of type archive.Package to the messages queue,
 // and eventually signals the completion of the current producer.
-func (q *MPSCQueue) SendMessageAndComplete(msg ...Package) {
+func (q *MPSCQueue) SendMessageAndComplete(ctx context.Context, msg ...Package) {
 	defer q.SigProducerCompletion()
-	q.msgCh <- msg
+	q.SendMessage(ctx, msg...)
 }
 
 // SendError sends an error message of type error to the errors queue.
-func (q *MPSCQueue) SendError(err error) {
-	q.errCh <- err
+// If ctx is done before the consumer receives it, the error is dropped, for the producer not to block.
+func (q *MPSCQueue) SendError(ctx context.Context, err error) {
+	select {
+	case q.errCh <- err:
+	case <-ctx.Done():
+	}
 }
 
 // Consume listens for both messages and errors on queues and do something with them,
@@ -0,0 +1,110 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+type testPackage struct {
+	Package
+	name string
+}
+
+func TestMPSCQueue(t *testing.T) {
+	ctx := context.Background()
+	queue := NewMPSCQueue(2, 0)
+
+	go queue.SendMessageAndComplete(ctx, &testPackage{name: "a"})
+
+	go func() {
+		defer queue.SigProducerCompletion()
+		queue.SendError(ctx, errors.New("b"))
+	}()
+
+	var (
+		msgs int
+		errs int
+	)
+
+	go queue.Consume(
+		func(p ...Package) { msgs += len(p) },
+		func(e error) { errs++ },
+	)
+
+	queue.WaitAndClose()
+
+	assert.Equal(t, msgs, 1)
+	assert.Equal(t, errs, 1)
+}
+
+func TestMPSCQueueCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := NewMPSCQueue(1, 0)
+
+	done := make(chan struct{})
+
+	// No consumer is receiving: the producer must not block once the context is canceled.
+	go func() {
+		defer close(done)
+		defer queue.SigProducerCompletion()
+
+		queue.SendMessage(ctx, &testPackage{name: "a"})
+		queue.SendError(ctx, errors.New("a"))
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("producer blocked after cancellation")
+	}
+
+	go queue.Consume(func(p ...Package) {}, func(e error) {})
+
+	queue.WaitAndClose()
+}
+
+func TestMPSCQueueParallelism(t *testing.T) {
+	t.Parallel()
+
+	const producers = 8
+
+	ctx := context.Background()
+	queue := NewMPSCQueue(producers, 2)
+
+	var current, max int32
+
+	for i := 0; i < producers; i++ {
+		queue.Go(ctx, func() {
+			defer queue.SigProducerCompletion()
+
+			n := atomic.AddInt32(&current, 1)
+			defer atomic.AddInt32(&current, -1)
+
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			queue.SendMessage(ctx, &testPackage{})
+		})
+	}
+
+	var msgs int
+
+	go queue.Consume(func(p ...Package) { msgs += len(p) }, func(e error) {})
+
+	queue.WaitAndClose()
+
+	assert.Equal(t, msgs, producers)
+	assert.Assert(t, atomic.LoadInt32(&max) <= 2)
+}
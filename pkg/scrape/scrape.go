@@ -31,6 +31,7 @@ func CrawlFiles(seedURLs []*url.URL, exactFileRegex string, debug bool) ([]strin
 	coOptions := []func(*colly.Collector){
 		colly.AllowedDomains(allowedDomains...),
 		colly.Async(false),
+		withFetchTransport(),
 	}
 
 	if debug {
@@ -106,6 +107,7 @@ func CrawlFolders(seedURLs []*url.URL, exactFolderRegex string, recursive bool,
 	coOptions := []func(*colly.Collector){
 		colly.AllowedDomains(allowedDomains...),
 		colly.Async(false),
+		withFetchTransport(),
 	}
 
 	if debug {
@@ -184,6 +186,7 @@ func CrawlFoldersPath(seedURLs []*url.URL, exactFolderRegex string, recursive bo
 	coOptions := []func(*colly.Collector){
 		colly.AllowedDomains(allowedDomains...),
 		colly.Async(false),
+		withFetchTransport(),
 	}
 
 	if debug {
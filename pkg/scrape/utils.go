@@ -1,6 +1,20 @@
 package scrape
 
-import "net/url"
+import (
+	"net/url"
+
+	"github.com/gocolly/colly"
+
+	"github.com/maxgio92/krawler/pkg/fetch"
+)
+
+// withFetchTransport makes the collector send requests through the shared HTTP client transport,
+// for it to retry and honour the configured proxy and CA bundle.
+func withFetchTransport() func(*colly.Collector) {
+	return func(c *colly.Collector) {
+		c.WithTransport(fetch.Default().Transport)
+	}
+}
 
 func getHostnamesFromURLs(urls []*url.URL) []string {
 	hostnames := []string{}
@@ -0,0 +1,15 @@
+package signature
+
+import "errors"
+
+var (
+	// ErrVerificationFailed is returned when the signature of repository metadata
+	// does not verify against the configured keyring.
+	ErrVerificationFailed = errors.New("signature verification failed")
+
+	// ErrSignatureNotFound is returned when repository metadata is expected to be signed,
+	// but the signature does not exist.
+	ErrSignatureNotFound = errors.New("signature not found")
+
+	ErrKeyringEmpty = errors.New("keyring contains no keys")
+)
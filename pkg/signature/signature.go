@@ -0,0 +1,91 @@
+// Package signature verifies the OpenPGP signatures of repository metadata.
+package signature
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/pkg/errors"
+	//nolint:staticcheck
+	"golang.org/x/crypto/openpgp"
+	//nolint:staticcheck
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+// Keyring is a set of OpenPGP public keys, used to verify repository metadata.
+type Keyring = openpgp.EntityList
+
+// LoadKeyrings returns a keyring with the keys from all the keyring files,
+// either ASCII-armored or binary.
+// It returns a nil keyring if no file is specified, meaning that verification is disabled.
+func LoadKeyrings(paths []string) (Keyring, error) {
+	var keyring Keyring
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading keyring")
+		}
+
+		keys, err := readKeyring(data)
+		if err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+
+		if len(keys) < 1 {
+			return nil, errors.Wrap(ErrKeyringEmpty, path)
+		}
+
+		keyring = append(keyring, keys...)
+	}
+
+	return keyring, nil
+}
+
+// VerifyDetached verifies the detached signature, either ASCII-armored or binary,
+// of the signed data against the keyring.
+func VerifyDetached(keyring Keyring, signed, signature []byte) error {
+	if len(signature) < 1 {
+		return ErrSignatureNotFound
+	}
+
+	var err error
+
+	if isArmored(signature) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(signed), bytes.NewReader(signature))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(signed), bytes.NewReader(signature))
+	}
+
+	if err != nil {
+		return errors.Wrap(ErrVerificationFailed, err.Error())
+	}
+
+	return nil
+}
+
+// VerifyClearsigned verifies the clear-signed message against the keyring, and returns the signed plaintext.
+func VerifyClearsigned(keyring Keyring, message []byte) ([]byte, error) {
+	block, _ := clearsign.Decode(message)
+	if block == nil {
+		return nil, ErrSignatureNotFound
+	}
+
+	if _, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body); err != nil {
+		return nil, errors.Wrap(ErrVerificationFailed, err.Error())
+	}
+
+	return block.Plaintext, nil
+}
+
+func readKeyring(data []byte) (Keyring, error) {
+	if isArmored(data) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+func isArmored(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN PGP"))
+}
@@ -0,0 +1,110 @@
+package signature_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	//nolint:staticcheck
+	"golang.org/x/crypto/openpgp"
+	//nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor"
+	//nolint:staticcheck
+	"golang.org/x/crypto/openpgp/clearsign"
+	"gotest.tools/assert"
+
+	"github.com/maxgio92/krawler/pkg/signature"
+)
+
+func newEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+
+	e, err := openpgp.NewEntity("krawler", "test", "krawler@example.com", nil)
+	assert.NilError(t, err)
+
+	return e
+}
+
+func writeKeyring(t *testing.T, e *openpgp.Entity) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	assert.NilError(t, err)
+	assert.NilError(t, e.Serialize(w))
+	assert.NilError(t, w.Close())
+
+	path := filepath.Join(t.TempDir(), "keyring.asc")
+	assert.NilError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	return path
+}
+
+func TestVerifyDetached(t *testing.T) {
+	t.Parallel()
+
+	signer := newEntity(t)
+	data := []byte("<repomd></repomd>")
+
+	keyring, err := signature.LoadKeyrings([]string{writeKeyring(t, signer)})
+	assert.NilError(t, err)
+
+	var armored, binary bytes.Buffer
+	assert.NilError(t, openpgp.ArmoredDetachSign(&armored, signer, bytes.NewReader(data), nil))
+	assert.NilError(t, openpgp.DetachSign(&binary, signer, bytes.NewReader(data), nil))
+
+	assert.NilError(t, signature.VerifyDetached(keyring, data, armored.Bytes()))
+	assert.NilError(t, signature.VerifyDetached(keyring, data, binary.Bytes()))
+
+	err = signature.VerifyDetached(keyring, []byte("<repomd>tampered</repomd>"), binary.Bytes())
+	assert.Assert(t, errors.Is(err, signature.ErrVerificationFailed))
+
+	err = signature.VerifyDetached(keyring, data, nil)
+	assert.Assert(t, errors.Is(err, signature.ErrSignatureNotFound))
+
+	other, err := signature.LoadKeyrings([]string{writeKeyring(t, newEntity(t))})
+	assert.NilError(t, err)
+
+	err = signature.VerifyDetached(other, data, binary.Bytes())
+	assert.Assert(t, errors.Is(err, signature.ErrVerificationFailed))
+}
+
+func TestVerifyClearsigned(t *testing.T) {
+	t.Parallel()
+
+	signer := newEntity(t)
+	data := []byte("Origin: Debian\nSuite: stable\n")
+
+	keyring, err := signature.LoadKeyrings([]string{writeKeyring(t, signer)})
+	assert.NilError(t, err)
+
+	var buf bytes.Buffer
+
+	w, err := clearsign.Encode(&buf, signer.PrivateKey, nil)
+	assert.NilError(t, err)
+	_, err = w.Write(data)
+	assert.NilError(t, err)
+	assert.NilError(t, w.Close())
+
+	plaintext, err := signature.VerifyClearsigned(keyring, buf.Bytes())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, data, plaintext)
+
+	_, err = signature.VerifyClearsigned(keyring, data)
+	assert.Assert(t, errors.Is(err, signature.ErrSignatureNotFound))
+
+	tampered := bytes.Replace(buf.Bytes(), []byte("stable"), []byte("sid"), 1)
+	_, err = signature.VerifyClearsigned(keyring, tampered)
+	assert.Assert(t, errors.Is(err, signature.ErrVerificationFailed))
+}
+
+func TestLoadKeyringsNone(t *testing.T) {
+	t.Parallel()
+
+	keyring, err := signature.LoadKeyrings(nil)
+	assert.NilError(t, err)
+	assert.Assert(t, keyring == nil)
+}